@@ -2,13 +2,24 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/gustavolopess/hoteleiro/internal/auth"
+	"github.com/gustavolopess/hoteleiro/internal/calendar"
 	"github.com/gustavolopess/hoteleiro/internal/chat_flow"
 	"github.com/gustavolopess/hoteleiro/internal/config"
 	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/scheduler"
 	"github.com/gustavolopess/hoteleiro/internal/storage"
+	"github.com/gustavolopess/hoteleiro/internal/storage/blob"
 	"github.com/gustavolopess/hoteleiro/internal/storage/s3_client"
 )
 
@@ -16,6 +27,28 @@ type MenuOption string
 
 const (
 	startCommand            string     = "start"
+	calendarCommand         string     = "calendario"
+	calendarImportCommand   string     = "sincronizar"
+	airbnbImportCommand     string     = "importar"
+	bankStatementCommand    string     = "extrato"
+	attachmentCommand       string     = "anexo"
+	inviteCommand           string     = "convidar"
+	revokeCommand           string     = "revogar"
+	allowChatCommand        string     = "liberar"
+	joinCommand             string     = "entrar"
+	apartmentCommand        string     = "imovel"
+	recurringCommand        string     = "recorrente"
+	paymentDueCommand       string     = "vencimento"
+	subscribeCommand        string     = "assinar"
+	indicatorsCommand       string     = "indicadores"
+	financingCommand        string     = "financiamento"
+	indexImportCommand      string     = "indices"
+	taxReportCommand        string     = "irpf"
+	statementCommand        string     = "demonstrativo"
+	chartCommand            string     = "grafico"
+	profitabilityCommand    string     = "rentabilidade"
+	budgetCommand           string     = "orcamento"
+	forecastCommand         string     = "previsao"
 	addRent                 MenuOption = "Adicionar aluguel"
 	addCleaning             MenuOption = "Adicionar faxina"
 	addBill                 MenuOption = "Adicionar conta de luz"
@@ -24,6 +57,7 @@ const (
 	addMiscellaneousExpense MenuOption = "Adicionar despesa diversa"
 	addAmortization         MenuOption = "Adicionar amortizaçao"
 	addFinancingInstallment MenuOption = "Adicionar pagamento de parcela do financiamento"
+	scheduledCleanings      MenuOption = "Faxinas agendadas"
 )
 
 func isMessageAMenuOption(msg string) bool {
@@ -34,31 +68,181 @@ func isMessageAMenuOption(msg string) bool {
 		msg == string(addApartment) ||
 		msg == string(addAmortization) ||
 		msg == string(addMiscellaneousExpense) ||
-		msg == string(addFinancingInstallment))
-}
-
-var numericKeyboard = tgbotapi.NewReplyKeyboard(
-	tgbotapi.NewKeyboardButtonRow(
-		tgbotapi.NewKeyboardButton(string(addRent)),
-		tgbotapi.NewKeyboardButton(string(addCleaning)),
-	),
-	tgbotapi.NewKeyboardButtonRow(
-		tgbotapi.NewKeyboardButton(string(addBill)),
-		tgbotapi.NewKeyboardButton(string(addCondo)),
-	),
-	tgbotapi.NewKeyboardButtonRow(
-		tgbotapi.NewKeyboardButton(string(addAmortization)),
-		tgbotapi.NewKeyboardButton(string(addMiscellaneousExpense)),
-	),
-	tgbotapi.NewKeyboardButtonRow(
-		tgbotapi.NewKeyboardButton(string(addFinancingInstallment)),
-	),
-	tgbotapi.NewKeyboardButtonRow(
-		tgbotapi.NewKeyboardButton(string(addApartment)),
-	),
+		msg == string(addFinancingInstallment) ||
+		msg == string(scheduledCleanings))
+}
+
+var (
+	writers       = []models.Role{models.RoleAdmin, models.RolePartner}
+	readers       = []models.Role{models.RoleAdmin, models.RolePartner, models.RoleReadOnly}
+	cleaningRoles = []models.Role{models.RoleAdmin, models.RolePartner, models.RoleCleaner}
+	adminOnly     = []models.Role{models.RoleAdmin}
 )
 
-var chatSessions = make(map[int64]chat_flow.ChatSession)
+var menuRoles = map[MenuOption][]models.Role{
+	addRent:                 writers,
+	addCleaning:             writers,
+	addBill:                 writers,
+	addCondo:                writers,
+	addApartment:            adminOnly,
+	addMiscellaneousExpense: writers,
+	addAmortization:         writers,
+	addFinancingInstallment: writers,
+	scheduledCleanings:      cleaningRoles,
+}
+
+var menuLayout = [][]MenuOption{
+	{addRent, addCleaning},
+	{scheduledCleanings},
+	{addBill, addCondo},
+	{addAmortization, addMiscellaneousExpense},
+	{addFinancingInstallment},
+	{addApartment},
+}
+
+// menuKeyboard assembles the main menu with the options the role is allowed to use
+func menuKeyboard(role models.Role) tgbotapi.ReplyKeyboardMarkup {
+	var rows [][]tgbotapi.KeyboardButton
+	for _, options := range menuLayout {
+		var row []tgbotapi.KeyboardButton
+		for _, o := range options {
+			if auth.HasRole(role, menuRoles[o]) {
+				row = append(row, tgbotapi.NewKeyboardButton(string(o)))
+			}
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	keyboard := tgbotapi.NewReplyKeyboard(rows...)
+	// in groups, show the menu only to the user who asked for it
+	keyboard.Selective = true
+	return keyboard
+}
+
+// command is a slash command which starts a chat session, allowed only to some roles
+type command struct {
+	roles      []models.Role
+	newSession func(chatId int64) chat_flow.ChatSession
+}
+
+// sessionKey identifies the session of a user in a chat, as in a group each member has its own flow going on
+type sessionKey struct {
+	chatId int64
+	userId int64
+}
+
+// messageKey identifies a message sent to a chat
+type messageKey struct {
+	chatId    int64
+	messageId int
+}
+
+var chatSessions = make(map[sessionKey]chat_flow.ChatSession)
+
+// sessionOrigins are the messages which started each session, quoted by the replies to keyboard answers in groups
+var sessionOrigins = make(map[sessionKey]int)
+
+// keyboardOwners are the users allowed to answer the inline keyboard of each message, and sessionKeyboards the
+// message with the latest keyboard sent to each session
+var keyboardOwners = make(map[messageKey]int64)
+var sessionKeyboards = make(map[sessionKey]messageKey)
+
+// newReply assembles the message sent back to the chat, which is a file when the markup is a chat_flow.Document and
+// an image when it is a chat_flow.Photo. When replyTo is set the reply quotes that message
+func newReply(chatId int64, replyTo int, text string, markup interface{}) tgbotapi.Chattable {
+	if doc, ok := markup.(chat_flow.Document); ok {
+		reply := tgbotapi.NewDocument(chatId, tgbotapi.FileBytes{Name: doc.Name, Bytes: doc.Data})
+		reply.Caption = text
+		reply.ReplyToMessageID = replyTo
+		reply.AllowSendingWithoutReply = true
+		return reply
+	}
+	if photo, ok := markup.(chat_flow.Photo); ok {
+		reply := tgbotapi.NewPhoto(chatId, tgbotapi.FileBytes{Name: photo.Name, Bytes: photo.Data})
+		reply.Caption = text
+		reply.ReplyToMessageID = replyTo
+		reply.AllowSendingWithoutReply = true
+		return reply
+	}
+
+	msg := tgbotapi.NewMessage(chatId, text)
+	msg.ReplyMarkup = markup
+	msg.ReplyToMessageID = replyTo
+	msg.AllowSendingWithoutReply = true
+	return msg
+}
+
+// botNotifier sends the messages of the scheduled jobs
+type botNotifier struct {
+	bot *tgbotapi.BotAPI
+}
+
+func (n botNotifier) Notify(chatId int64, text string, markup interface{}) error {
+	_, err := n.bot.Send(newReply(chatId, 0, text, markup))
+	return err
+}
+
+// alertBudget sends the alert to the chat where the budget was set
+func (n botNotifier) alertBudget(alert *models.BudgetAlert) {
+	if err := n.Notify(alert.Budget.ChatId, alert.ToString(), nil); err != nil {
+		log.Printf("failed to send budget alert to chat %d: %v", alert.Budget.ChatId, err)
+	}
+}
+
+// startSession makes the session the current one of the user in the chat, replacing the previous one
+func startSession(key sessionKey, session chat_flow.ChatSession, origin *tgbotapi.Message) {
+	endSession(key)
+	if userSession, ok := session.(chat_flow.UserSession); ok {
+		userSession.SetUser(key.userId)
+	}
+	chatSessions[key] = session
+	sessionOrigins[key] = origin.MessageID
+}
+
+// endSession forgets the session of the user in the chat along with its keyboard
+func endSession(key sessionKey) {
+	delete(chatSessions, key)
+	delete(sessionOrigins, key)
+	if previous, ok := sessionKeyboards[key]; ok {
+		delete(keyboardOwners, previous)
+		delete(sessionKeyboards, key)
+	}
+}
+
+// endSessionIfOver forgets the session once its conversation is over
+func endSessionIfOver(key sessionKey) {
+	if ended, ok := chatSessions[key].(chat_flow.EndedSession); ok && ended.Ended() {
+		endSession(key)
+	}
+}
+
+// trackKeyboard remembers who may answer the inline keyboard just sent, forgetting the previous one of the session
+func trackKeyboard(key sessionKey, sent tgbotapi.Message) {
+	if previous, ok := sessionKeyboards[key]; ok {
+		delete(keyboardOwners, previous)
+	}
+	msgKey := messageKey{chatId: sent.Chat.ID, messageId: sent.MessageID}
+	keyboardOwners[msgKey] = key.userId
+	sessionKeyboards[key] = msgKey
+}
+
+func startHttpServer(feed *calendar.Feed) {
+	mux := http.NewServeMux()
+	mux.Handle(calendar.FeedPath, feed)
+
+	var err error
+	if len(config.HttpTLSCertFile) > 0 {
+		log.Printf("Serving HTTPS on %s", config.HttpServerAddr)
+		err = http.ListenAndServeTLS(config.HttpServerAddr, config.HttpTLSCertFile, config.HttpTLSKeyFile, mux)
+	} else {
+		log.Printf("Serving HTTP on %s, behind a proxy terminating TLS", config.HttpServerAddr)
+		err = http.ListenAndServe(config.HttpServerAddr, mux)
+	}
+	if err != nil {
+		log.Printf("http server stopped: %v", err)
+	}
+}
 
 func triggerBot(ctx context.Context) {
 	bot, err := tgbotapi.NewBotAPI(config.TelegramBotToken)
@@ -69,7 +253,64 @@ func triggerBot(ctx context.Context) {
 	s3Client := s3_client.GetS3Client()
 
 	googleSheetsCreds := s3Client.GetGoogleSheetsCreds()
-	store := storage.NewGoogleSheetsStore(config.GoogleSheetId, googleSheetsCreds)
+	store := storage.NewGoogleSheetsStore(config.GoogleSheetId, googleSheetsCreds, botNotifier{bot}.alertBudget)
+
+	var blobs blob.Store
+	if len(config.AttachmentsLocalDir) > 0 {
+		blobs = blob.NewLocalStore(config.AttachmentsLocalDir)
+	} else {
+		blobs = blob.NewS3Store(s3Client, config.AttachmentsS3Prefix)
+	}
+
+	authorizer, err := auth.NewAuthorizer(store, parseIds(os.Getenv("ADMIN_USER_IDS")))
+	if err != nil {
+		log.Printf("Unable to load the access list, only the administrators are allowed until it loads: %v", err)
+	}
+
+	// the feed is optional, without it calendars are still exported as files
+	var feed *calendar.Feed
+	feedSecret := os.Getenv("CALENDAR_FEED_SECRET")
+	if len(feedSecret) == 0 {
+		log.Println("calendar feed secret is empty, the calendar feed is disabled")
+	} else if !strings.HasPrefix(config.CalendarFeedBaseURL, "https://") {
+		log.Println("calendar feed base url is not https, the calendar feed is disabled so its tokens don't travel in cleartext")
+	} else {
+		feed = calendar.NewFeed(store, config.CalendarFeedBaseURL, feedSecret)
+		go startHttpServer(feed)
+	}
+
+	recurringExpenses := scheduler.NewRecurringExpenses(store, botNotifier{bot})
+	dueReminders := scheduler.NewDueReminders(store, botNotifier{bot}, config.ReminderDaysBefore, config.ReminderSnooze)
+	digest := scheduler.NewDigest(store, botNotifier{bot}, config.DigestHour)
+	reports := scheduler.NewReports(store, botNotifier{bot})
+	// the sessions which set up the jobs read the same clock the jobs run by
+	clock := time.Now
+	go scheduler.NewScheduler(config.SchedulerInterval, recurringExpenses, dueReminders, digest, reports).WithClock(clock).Start(ctx)
+
+	commands := map[string]command{
+		calendarCommand:       {readers, func(int64) chat_flow.ChatSession { return chat_flow.NewCalendarExportSession(store, feed) }},
+		calendarImportCommand: {writers, func(int64) chat_flow.ChatSession { return chat_flow.NewCalendarImportSession(store) }},
+		airbnbImportCommand:   {writers, func(int64) chat_flow.ChatSession { return chat_flow.NewAirbnbImportSession(store) }},
+		bankStatementCommand:  {writers, func(int64) chat_flow.ChatSession { return chat_flow.NewBankStatementSession(store) }},
+		attachmentCommand:     {readers, func(int64) chat_flow.ChatSession { return chat_flow.NewAttachmentSession(store, blobs) }},
+		inviteCommand:         {adminOnly, func(int64) chat_flow.ChatSession { return chat_flow.NewInviteSession(authorizer) }},
+		revokeCommand:         {adminOnly, func(int64) chat_flow.ChatSession { return chat_flow.NewRevokeSession(authorizer) }},
+		apartmentCommand:      {adminOnly, func(int64) chat_flow.ChatSession { return chat_flow.NewApartmentManagementSession(store) }},
+		recurringCommand:      {writers, func(chatId int64) chat_flow.ChatSession { return chat_flow.NewRecurringExpenseSession(chatId, store) }},
+		paymentDueCommand:     {writers, func(chatId int64) chat_flow.ChatSession { return chat_flow.NewPaymentDueSession(chatId, store) }},
+		subscribeCommand: {readers, func(chatId int64) chat_flow.ChatSession {
+			return chat_flow.NewReportSubscriptionSession(chatId, store, clock)
+		}},
+		indicatorsCommand:    {readers, func(int64) chat_flow.ChatSession { return chat_flow.NewIndicatorsSession(store) }},
+		financingCommand:     {writers, func(int64) chat_flow.ChatSession { return chat_flow.NewFinancingSession(store) }},
+		indexImportCommand:   {writers, func(int64) chat_flow.ChatSession { return chat_flow.NewIndexImportSession(store) }},
+		taxReportCommand:     {writers, func(int64) chat_flow.ChatSession { return chat_flow.NewTaxReportSession(store) }},
+		statementCommand:     {readers, func(int64) chat_flow.ChatSession { return chat_flow.NewStatementSession(store) }},
+		chartCommand:         {readers, func(int64) chat_flow.ChatSession { return chat_flow.NewChartSession(store) }},
+		profitabilityCommand: {readers, func(int64) chat_flow.ChatSession { return chat_flow.NewProfitabilitySession(store) }},
+		budgetCommand:        {writers, func(chatId int64) chat_flow.ChatSession { return chat_flow.NewBudgetSession(chatId, store) }},
+		forecastCommand:      {readers, func(int64) chat_flow.ChatSession { return chat_flow.NewForecastSession(store) }},
+	}
 
 	bot.Debug = true
 
@@ -81,59 +322,248 @@ func triggerBot(ctx context.Context) {
 	updates := bot.GetUpdatesChan(u)
 
 	for update := range updates {
-		var msg tgbotapi.MessageConfig
 		isMessage := update.Message != nil
 		isCallback := update.CallbackQuery != nil
 		var msgText string
 		var chatId int64
+		var replyTo int
 		if isMessage { // If we got a message
 			chatId, msgText = update.Message.Chat.ID, update.Message.Text
 		} else if isCallback {
 			chatId, msgText = update.CallbackQuery.Message.Chat.ID, update.CallbackQuery.Data
 		}
 
-		msg = tgbotapi.NewMessage(chatId, msgText)
+		replyText, markup := "", interface{}(nil)
+		user := update.SentFrom()
+		if user == nil || isCommandToAnotherBot(update, bot.Self.UserName) {
+			continue
+		}
+		key := sessionKey{chatId: chatId, userId: user.ID}
+
+		isGroup := !update.FromChat().IsPrivate()
+		if isGroup && isMessage {
+			replyTo = update.Message.MessageID
+		} else if isGroup && isCallback {
+			replyTo = sessionOrigins[key]
+		}
+
+		if isCallback {
+			if !answerCallback(bot, update.CallbackQuery) {
+				continue
+			}
+		}
 
-		if isMessage && update.Message.IsCommand() && update.Message.Command() == "start" {
-			msg.ReplyMarkup = numericKeyboard
-		} else if isMessageAMenuOption(msgText) {
-			msg.Text, msg.ReplyMarkup = initChatSession(chatId, msgText, store)
+		role, isAllowed := authorizer.Role(user.ID)
+		isAllowed = isAllowed && authorizer.IsChatAllowed(chatId, user.ID)
+
+		if commandOf(update) == joinCommand {
+			replyText = joinBot(authorizer, update.Message)
+		} else if !isAllowed {
+			log.Printf("refusing update of user %d at chat %d", user.ID, chatId)
+			replyText = "Acesso nao autorizado. Peça um código de convite ao administrador e envie /entrar <código>"
+		} else if isMessage && update.Message.IsCommand() && update.Message.Command() == startCommand {
+			replyText, markup = "Selecione uma opçao", menuKeyboard(role)
+		} else if commandOf(update) == allowChatCommand && role == models.RoleAdmin {
+			replyText = allowChat(authorizer, update.Message.Chat)
+		} else if isCallback && recurringExpenses.Handles(msgText) {
+			if !auth.HasRole(role, writers) {
+				replyText = "Você nao tem permissao para isso"
+			} else {
+				replyText = recurringExpenses.Answer(msgText)
+			}
+		} else if isCallback && dueReminders.Handles(msgText) {
+			if !auth.HasRole(role, writers) {
+				replyText = "Você nao tem permissao para isso"
+			} else if reply, paid := dueReminders.Answer(msgText); paid != nil {
+				startSession(key, chat_flow.NewRecordSession(chatId, paid.Type, paid.Apartment.Name, store, blobs), update.CallbackQuery.Message)
+				replyText, markup = chatSessions[key].Next("")
+			} else {
+				replyText = reply
+			}
+		} else if isCallback && digest.Handles(msgText) {
+			if !auth.HasRole(role, writers) {
+				replyText = "Você nao tem permissao para isso"
+			} else if apartment, date, reply := digest.Answer(msgText); len(reply) == 0 {
+				startSession(key, chat_flow.NewCleaningScheduleSession(apartment, date, store), update.CallbackQuery.Message)
+				replyText, markup = chatSessions[key].Next("")
+			} else {
+				replyText = reply
+			}
+		} else if cmd, ok := commands[commandOf(update)]; ok {
+			if !auth.HasRole(role, cmd.roles) {
+				replyText = "Você nao tem permissao para isso"
+			} else {
+				startSession(key, cmd.newSession(chatId), update.Message)
+				replyText, markup = chatSessions[key].Next(update.Message.CommandArguments())
+			}
+		} else if isMessage && isMessageAMenuOption(msgText) {
+			if !auth.HasRole(role, menuRoles[MenuOption(msgText)]) {
+				replyText = "Você nao tem permissao para isso"
+			} else {
+				replyText, markup = initChatSession(key, role, update.Message, store, blobs)
+			}
+		} else if isMessage && update.Message.Document != nil {
+			replyText, markup = receiveDocument(bot, key, update.Message.Document.FileID, update.Message.Document.FileName)
+		} else if isMessage && len(update.Message.Photo) > 0 {
+			// photos come in several sizes, the last one being the largest
+			photo := update.Message.Photo[len(update.Message.Photo)-1]
+			replyText, markup = receiveDocument(bot, key, photo.FileID, photo.FileUniqueID+".jpg")
 		} else {
-			if _, ok := chatSessions[chatId]; ok {
-				msg.Text, msg.ReplyMarkup = chatSessions[chatId].Next(msgText)
+			if _, ok := chatSessions[key]; ok {
+				replyText, markup = chatSessions[key].Next(msgText)
 			}
 		}
 
-		if _, err := bot.Send(msg); err != nil {
-			log.Panic(err)
+		// updates without an answer, such as stickers or messages outside a session, are left unanswered
+		if len(replyText) > 0 || markup != nil {
+			sent, err := bot.Send(newReply(chatId, replyTo, replyText, markup))
+			if err != nil {
+				log.Printf("failed to reply to chat %d: %v", chatId, err)
+			} else if _, ok := markup.(tgbotapi.InlineKeyboardMarkup); ok {
+				trackKeyboard(key, sent)
+			}
+		}
+		endSessionIfOver(key)
+	}
+}
+
+// answerCallback acknowledges the keyboard answer, refusing it if the keyboard belongs to another user's session
+func answerCallback(bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery) bool {
+	owner, ok := keyboardOwners[messageKey{chatId: callback.Message.Chat.ID, messageId: callback.Message.MessageID}]
+	if ok && owner != callback.From.ID {
+		answer := tgbotapi.NewCallbackWithAlert(callback.ID, "Somente quem iniciou essa conversa pode responder")
+		if _, err := bot.Request(answer); err != nil {
+			log.Printf("failed to answer callback: %v", err)
+		}
+		return false
+	}
+
+	if _, err := bot.Request(tgbotapi.NewCallback(callback.ID, "")); err != nil {
+		log.Printf("failed to answer callback: %v", err)
+	}
+	return true
+}
+
+// isCommandToAnotherBot tells if the command was addressed as /cmd@botname to a bot other than this one
+func isCommandToAnotherBot(update tgbotapi.Update, botName string) bool {
+	if update.Message == nil || !update.Message.IsCommand() {
+		return false
+	}
+	_, addressee, found := strings.Cut(update.Message.CommandWithAt(), "@")
+	return found && !strings.EqualFold(addressee, botName)
+}
+
+// joinBot redeems the invite code sent with the join command
+func joinBot(authorizer *auth.Authorizer, message *tgbotapi.Message) string {
+	u, err := authorizer.Redeem(strings.TrimSpace(message.CommandArguments()), message.From.ID, message.From.String())
+	if err != nil {
+		return fmt.Sprintf("Falha ao entrar - %v", err.Error())
+	}
+	return fmt.Sprintf("Bem-vindo(a), %v! Envie /start para ver as opçoes", u.Name)
+}
+
+// allowChat adds the group chat to the allow-list, so its allowed members can use the bot in it
+func allowChat(authorizer *auth.Authorizer, chat *tgbotapi.Chat) string {
+	if chat.IsPrivate() {
+		return "Conversas privadas já sao liberadas, use esse comando em um grupo"
+	}
+	if err := authorizer.AllowChat(&models.AllowedChat{Id: chat.ID, Name: chat.Title}); err != nil {
+		return fmt.Sprintf("Falha ao liberar o grupo - %v", err.Error())
+	}
+	return "Grupo liberado!"
+}
+
+// parseIds reads a comma separated list of Telegram ids
+func parseIds(ids string) []int64 {
+	var parsed []int64
+	for _, id := range strings.Split(ids, ",") {
+		if len(strings.TrimSpace(id)) == 0 {
+			continue
+		}
+		i, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+		if err != nil {
+			log.Fatalf("invalid telegram id %q", id)
 		}
+		parsed = append(parsed, i)
 	}
+	return parsed
+}
+
+// receiveDocument downloads a file sent to the chat and hands it to the current session
+func receiveDocument(bot *tgbotapi.BotAPI, key sessionKey, fileId string, fileName string) (string, interface{}) {
+	receiver, ok := chatSessions[key].(chat_flow.DocumentReceiver)
+	if !ok {
+		return "Nao estou esperando um arquivo agora", nil
+	}
+
+	data, err := downloadFile(bot, fileId)
+	if err != nil {
+		log.Printf("failed to download file %s: %v", fileName, err)
+		return fmt.Sprintf("Falha ao baixar o arquivo %s", fileName), nil
+	}
+
+	return receiver.ReceiveDocument(fileName, data)
 }
 
-func initChatSession(chatId int64, msgText string, store storage.Store) (string, interface{}) {
+func downloadFile(bot *tgbotapi.BotAPI, fileId string) ([]byte, error) {
+	url, err := bot.GetFileDirectURL(fileId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status downloading file: %s", resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// commandOf returns the command of the update, or an empty string if it isn't a command
+func commandOf(update tgbotapi.Update) string {
+	if update.Message == nil || !update.Message.IsCommand() {
+		return ""
+	}
+	return update.Message.Command()
+}
+
+func initChatSession(key sessionKey, role models.Role, message *tgbotapi.Message, store storage.Store, blobs blob.Store) (string, interface{}) {
 	var chatSession chat_flow.ChatSession
+	chatId, msgText := key.chatId, message.Text
 
 	switch MenuOption(msgText) {
 	case addBill:
-		chatSession = chat_flow.NewChatSession[models.EnergyBill](chatId, store)
+		chatSession = chat_flow.NewChatSession[models.EnergyBill](chatId, store, blobs)
 	case addRent:
-		chatSession = chat_flow.NewChatSession[models.Rent](chatId, store)
+		chatSession = chat_flow.NewChatSession[models.Rent](chatId, store, blobs)
 	case addCleaning:
-		chatSession = chat_flow.NewChatSession[models.Cleaning](chatId, store)
+		chatSession = chat_flow.NewChatSession[models.Cleaning](chatId, store, blobs)
 	case addCondo:
-		chatSession = chat_flow.NewChatSession[models.Condo](chatId, store)
+		chatSession = chat_flow.NewChatSession[models.Condo](chatId, store, blobs)
 	case addApartment:
-		chatSession = chat_flow.NewChatSession[models.Apartment](chatId, store)
+		chatSession = chat_flow.NewChatSession[models.Apartment](chatId, store, blobs)
 	case addMiscellaneousExpense:
-		chatSession = chat_flow.NewChatSession[models.MiscellaneousExpense](chatId, store)
+		chatSession = chat_flow.NewChatSession[models.MiscellaneousExpense](chatId, store, blobs)
 	case addAmortization:
-		chatSession = chat_flow.NewChatSession[models.Amortization](chatId, store)
+		chatSession = chat_flow.NewChatSession[models.Amortization](chatId, store, blobs)
 	case addFinancingInstallment:
-		chatSession = chat_flow.NewChatSession[models.FinancingInstallment](chatId, store)
+		chatSession = chat_flow.NewChatSession[models.FinancingInstallment](chatId, store, blobs)
+	case scheduledCleanings:
+		// cleaners only see the cleanings assigned to them
+		var cleanerId int64
+		if role == models.RoleCleaner {
+			cleanerId = key.userId
+		}
+		chatSession = chat_flow.NewScheduledCleaningsSession(cleanerId, store)
 	}
 
 	if chatSession != nil {
-		chatSessions[chatId] = chatSession
+		startSession(key, chatSession, message)
 		return chatSession.Next(msgText)
 	}
 
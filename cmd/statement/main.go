@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/config"
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/report"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+	"github.com/gustavolopess/hoteleiro/internal/storage/s3_client"
+)
+
+// statement writes the PDF statement of an apartment to disk, the same sent by the bot with /demonstrativo
+func main() {
+	apartment := flag.String("imovel", "", "name of the apartment")
+	periodFlag := flag.String("periodo", "", "month as 03/2024 or year as 2024, the previous month when empty")
+	output := flag.String("saida", "", "file written, demonstrativo-<imovel>-<aaaa-mm>.pdf when empty")
+	flag.Parse()
+
+	if len(*apartment) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	period, err := report.ParsePeriod(*periodFlag, time.Now())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(*output) == 0 {
+		*output = fmt.Sprintf("demonstrativo-%v-%v.pdf", *apartment, period.Begin.Format("2006-01"))
+	}
+
+	store := storage.NewGoogleSheetsStore(config.GoogleSheetId, s3_client.GetS3Client().GetGoogleSheetsCreds(), nil)
+	statement, err := report.BuildStatement(store, models.Apartment{Name: *apartment}, period)
+	if err != nil {
+		log.Fatalf("Unable to build the statement: %v", err)
+	}
+	if err := os.WriteFile(*output, statement.PDF(), 0o644); err != nil {
+		log.Fatalf("Unable to write the statement: %v", err)
+	}
+	log.Printf("statement written to %s", *output)
+}
@@ -0,0 +1,124 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+// MonthlyMetrics are the hosting indicators of an apartment in a month
+type MonthlyMetrics struct {
+	Month           time.Time
+	NightsBooked    int
+	NightsAvailable int
+	// Revenue is what the nights booked earned, without the cleaning fees
+	Revenue float64
+}
+
+// Occupancy is the share of the nights of the month which were booked
+func (m *MonthlyMetrics) Occupancy() float64 {
+	if m.NightsAvailable == 0 {
+		return 0
+	}
+	return float64(m.NightsBooked) / float64(m.NightsAvailable)
+}
+
+// ADR is the average daily rate, the revenue of each night booked
+func (m *MonthlyMetrics) ADR() float64 {
+	if m.NightsBooked == 0 {
+		return 0
+	}
+	return m.Revenue / float64(m.NightsBooked)
+}
+
+// RevPAR is the revenue per available night, booked or not
+func (m *MonthlyMetrics) RevPAR() float64 {
+	if m.NightsAvailable == 0 {
+		return 0
+	}
+	return m.Revenue / float64(m.NightsAvailable)
+}
+
+// StayMetrics describe the stays which checked in during a period
+type StayMetrics struct {
+	Stays int
+	// AverageLengthOfStay is in nights
+	AverageLengthOfStay float64
+	// AverageLeadTime is the days between booking and check-in, of the stays whose booking date is known
+	AverageLeadTime float64
+	LeadTimeStays   int
+}
+
+// Monthly splits the rents into the months from the month of begin until the month of end, each night of a stay
+// counting in the month it falls in, with its share of the rent value less the cleaning fee. Only the nights until the
+// one of today count, so the current month isn't measured against the nights still to come
+func Monthly(rents []*models.Rent, begin, end, now time.Time) []*MonthlyMetrics {
+	// records are dated at midnight UTC
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+
+	var months []*MonthlyMetrics
+	byMonth := make(map[time.Time]*MonthlyMetrics)
+	for month := monthStart(begin); !month.After(monthStart(end)); month = month.AddDate(0, 1, 0) {
+		m := &MonthlyMetrics{Month: month}
+		if nextMonth := month.AddDate(0, 1, 0); nextMonth.Before(tomorrow) {
+			m.NightsAvailable = nightsBetween(month, nextMonth)
+		} else if month.Before(tomorrow) {
+			m.NightsAvailable = nightsBetween(month, tomorrow)
+		}
+		months = append(months, m)
+		byMonth[month] = m
+	}
+
+	for _, r := range rents {
+		nights := nightsBetween(r.DateBegin, r.DateEnd)
+		if nights <= 0 {
+			continue
+		}
+		nightValue := (r.Value - r.CleaningFee) / float64(nights)
+		for night := r.DateBegin; night.Before(r.DateEnd) && night.Before(tomorrow); night = night.AddDate(0, 0, 1) {
+			if m, ok := byMonth[monthStart(night)]; ok {
+				m.NightsBooked++
+				m.Revenue += nightValue
+			}
+		}
+	}
+
+	return months
+}
+
+// Stays describes the rents which checked in from begin until the day before end
+func Stays(rents []*models.Rent, begin, end time.Time) StayMetrics {
+	var m StayMetrics
+	var nights, leadDays int
+	for _, r := range rents {
+		if r.DateBegin.Before(begin) || !r.DateBegin.Before(end) {
+			continue
+		}
+		m.Stays++
+		nights += nightsBetween(r.DateBegin, r.DateEnd)
+
+		if !r.BookedAt.IsZero() && !r.BookedAt.After(r.DateBegin) {
+			m.LeadTimeStays++
+			leadDays += nightsBetween(r.BookedAt, r.DateBegin)
+		}
+	}
+
+	if m.Stays > 0 {
+		m.AverageLengthOfStay = float64(nights) / float64(m.Stays)
+	}
+	if m.LeadTimeStays > 0 {
+		m.AverageLeadTime = float64(leadDays) / float64(m.LeadTimeStays)
+	}
+	return m
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// nightsBetween counts the calendar days from begin until end, regardless of daylight saving changes
+func nightsBetween(begin, end time.Time) int {
+	b := time.Date(begin.Year(), begin.Month(), begin.Day(), 0, 0, 0, 0, time.UTC)
+	e := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	return int(e.Sub(b).Hours() / 24)
+}
@@ -0,0 +1,84 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
+
+func TestMonthly(t *testing.T) {
+	rents := []*models.Rent{
+		// 4 nights, 2 in January and 2 in February, 100 of them for the cleaning
+		{DateBegin: date(2024, time.January, 30), DateEnd: date(2024, time.February, 3), Value: 900, CleaningFee: 100},
+		{DateBegin: date(2024, time.February, 10), DateEnd: date(2024, time.February, 12), Value: 400},
+		// out of the period
+		{DateBegin: date(2023, time.December, 1), DateEnd: date(2023, time.December, 5), Value: 1000},
+	}
+	months := Monthly(rents, date(2024, time.January, 1), date(2024, time.February, 29), date(2024, time.June, 1))
+	if len(months) != 2 {
+		t.Fatalf("%d months, want 2", len(months))
+	}
+
+	jan, feb := months[0], months[1]
+	if jan.NightsBooked != 2 || jan.NightsAvailable != 31 || !near(jan.Revenue, 400) {
+		t.Errorf("January %+v, want 2 of 31 nights booked earning 400", jan)
+	}
+	if feb.NightsBooked != 4 || feb.NightsAvailable != 29 || !near(feb.Revenue, 800) {
+		t.Errorf("February %+v, want 4 of 29 nights booked earning 800", feb)
+	}
+	if !near(jan.ADR(), 200) {
+		t.Errorf("ADR of January %.2f, want 200 as the cleaning fee is left out", jan.ADR())
+	}
+	if !near(feb.RevPAR(), 800.0/29) {
+		t.Errorf("RevPAR of February %.2f, want %.2f", feb.RevPAR(), 800.0/29)
+	}
+}
+
+func TestMonthlyUntilToday(t *testing.T) {
+	rents := []*models.Rent{
+		{DateBegin: date(2024, time.March, 9), DateEnd: date(2024, time.March, 13), Value: 400},
+	}
+	months := Monthly(rents, date(2024, time.March, 1), date(2024, time.April, 30), time.Date(2024, time.March, 10, 15, 0, 0, 0, time.UTC))
+
+	mar, apr := months[0], months[1]
+	// the nights of the 9th and of today
+	if mar.NightsBooked != 2 || mar.NightsAvailable != 10 || !near(mar.Revenue, 200) {
+		t.Errorf("March %+v, want 2 of 10 nights booked earning 200", mar)
+	}
+	if apr.NightsAvailable != 0 || apr.Occupancy() != 0 || apr.ADR() != 0 || apr.RevPAR() != 0 {
+		t.Errorf("April %+v has indicators before it begins", apr)
+	}
+}
+
+func TestStays(t *testing.T) {
+	rents := []*models.Rent{
+		{DateBegin: date(2024, time.January, 10), DateEnd: date(2024, time.January, 13), BookedAt: date(2023, time.December, 31)},
+		{DateBegin: date(2024, time.January, 20), DateEnd: date(2024, time.January, 25)},
+		// booked after the check-in, a typo left out of the lead time
+		{DateBegin: date(2024, time.January, 28), DateEnd: date(2024, time.January, 29), BookedAt: date(2024, time.February, 2)},
+		// checked in before the period, though it crosses into it
+		{DateBegin: date(2023, time.December, 30), DateEnd: date(2024, time.January, 2), BookedAt: date(2023, time.December, 1)},
+		{DateBegin: date(2024, time.February, 1), DateEnd: date(2024, time.February, 3)},
+	}
+	m := Stays(rents, date(2024, time.January, 1), date(2024, time.February, 1))
+
+	if m.Stays != 3 {
+		t.Errorf("%d stays, want 3", m.Stays)
+	}
+	if !near(m.AverageLengthOfStay, 3) {
+		t.Errorf("average length of stay %.2f, want 3", m.AverageLengthOfStay)
+	}
+	if m.LeadTimeStays != 1 || !near(m.AverageLeadTime, 10) {
+		t.Errorf("lead time of %d stays %.2f, want 10 days of 1 stay", m.LeadTimeStays, m.AverageLeadTime)
+	}
+}
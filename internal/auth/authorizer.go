@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// inviteTTL is how long an invite code can be redeemed
+const inviteTTL = 24 * time.Hour
+
+var ErrInvalidInviteCode = errors.New("código de convite inválido ou expirado")
+var ErrCannotRevokeAdmin = errors.New("administradores configurados no servidor nao podem ser removidos")
+
+type invite struct {
+	role      models.Role
+	expiresAt time.Time
+}
+
+// Authorizer keeps the allow-list of users and group chats, whose source of truth is the store
+type Authorizer struct {
+	store   storage.Store
+	admins  map[int64]bool
+	mu      sync.Mutex
+	users   map[int64]*models.User
+	chats   map[int64]*models.AllowedChat
+	invites map[string]*invite
+	now     func() time.Time
+}
+
+// NewAuthorizer loads the allow-list from the store, the given admins are always allowed regardless of it. When the
+// allow-list can't be loaded the authorizer is still returned along the error, allowing only the admins until a later
+// load succeeds
+func NewAuthorizer(store storage.Store, admins []int64) (*Authorizer, error) {
+	a := &Authorizer{
+		store:   store,
+		admins:  make(map[int64]bool),
+		invites: make(map[string]*invite),
+		now:     time.Now,
+	}
+	for _, id := range admins {
+		a.admins[id] = true
+	}
+
+	return a, a.Reload()
+}
+
+// Reload reads the allow-list from the store again
+func (a *Authorizer) Reload() error {
+	users, err := a.store.GetUsers()
+	if err != nil {
+		return err
+	}
+	chats, err := a.store.GetAllowedChats()
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.users = make(map[int64]*models.User)
+	for _, u := range users {
+		a.users[u.Id] = u
+	}
+	a.chats = make(map[int64]*models.AllowedChat)
+	for _, c := range chats {
+		a.chats[c.Id] = c
+	}
+	return nil
+}
+
+// ensureLoaded loads the allow-list if it failed to load before
+func (a *Authorizer) ensureLoaded() {
+	a.mu.Lock()
+	loaded := a.users != nil
+	a.mu.Unlock()
+	if loaded {
+		return
+	}
+	if err := a.Reload(); err != nil {
+		log.Printf("error while loading the access list: %v", err.Error())
+	}
+}
+
+// Role returns the role of the user, and false if the user is not allowed at all
+func (a *Authorizer) Role(userId int64) (models.Role, bool) {
+	if a.admins[userId] {
+		return models.RoleAdmin, true
+	}
+
+	a.ensureLoaded()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if u, ok := a.users[userId]; ok {
+		return u.Role, true
+	}
+	return "", false
+}
+
+// IsChatAllowed tells if the bot may answer in the chat, private chats are allowed as long as the user is
+func (a *Authorizer) IsChatAllowed(chatId int64, userId int64) bool {
+	if chatId == userId {
+		return true
+	}
+
+	a.ensureLoaded()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, ok := a.chats[chatId]
+	return ok
+}
+
+// Invite creates a one-time code which gives the role to whoever redeems it
+func (a *Authorizer) Invite(role models.Role) (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := base32.StdEncoding.EncodeToString(b)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.invites[code] = &invite{role: role, expiresAt: a.now().Add(inviteTTL)}
+	return code, nil
+}
+
+// Redeem allows the user with the role of the invite, which can't be used again
+func (a *Authorizer) Redeem(code string, userId int64, name string) (*models.User, error) {
+	a.mu.Lock()
+	inv, ok := a.invites[code]
+	delete(a.invites, code)
+	a.mu.Unlock()
+
+	if !ok || a.now().After(inv.expiresAt) {
+		return nil, ErrInvalidInviteCode
+	}
+
+	u := &models.User{Id: userId, Name: name, Role: inv.role}
+	if err := a.store.AddUser(u); err != nil {
+		return nil, err
+	}
+
+	// an allow-list not loaded yet will have the user once it loads
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.users != nil {
+		a.users[u.Id] = u
+	}
+	return u, nil
+}
+
+func (a *Authorizer) Revoke(userId int64) error {
+	if a.admins[userId] {
+		return ErrCannotRevokeAdmin
+	}
+	if err := a.store.RemoveUser(userId); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.users, userId)
+	return nil
+}
+
+func (a *Authorizer) AllowChat(c *models.AllowedChat) error {
+	if err := a.store.AddAllowedChat(c); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.chats != nil {
+		a.chats[c.Id] = c
+	}
+	return nil
+}
+
+func (a *Authorizer) RevokeChat(chatId int64) error {
+	if err := a.store.RemoveAllowedChat(chatId); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.chats, chatId)
+	return nil
+}
+
+func (a *Authorizer) Users() []*models.User {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	users := make([]*models.User, 0, len(a.users))
+	for _, u := range a.users {
+		users = append(users, u)
+	}
+	return users
+}
+
+func (a *Authorizer) Chats() []*models.AllowedChat {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	chats := make([]*models.AllowedChat, 0, len(a.chats))
+	for _, c := range a.chats {
+		chats = append(chats, c)
+	}
+	return chats
+}
+
+// HasRole tells if the role is one of the allowed ones
+func HasRole(role models.Role, allowed []models.Role) bool {
+	for _, r := range allowed {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
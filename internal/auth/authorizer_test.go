@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// accessStore keeps the allow-list in memory, the other methods of the store are not expected to be called
+type accessStore struct {
+	storage.Store
+	users []*models.User
+	chats []*models.AllowedChat
+	err   error
+}
+
+func (s *accessStore) GetUsers() ([]*models.User, error) {
+	return s.users, s.err
+}
+
+func (s *accessStore) GetAllowedChats() ([]*models.AllowedChat, error) {
+	return s.chats, s.err
+}
+
+func (s *accessStore) AddUser(u *models.User) error {
+	s.users = append(s.users, u)
+	return nil
+}
+
+func (s *accessStore) RemoveUser(id int64) error {
+	var kept []*models.User
+	for _, u := range s.users {
+		if u.Id != id {
+			kept = append(kept, u)
+		}
+	}
+	s.users = kept
+	return nil
+}
+
+const (
+	adminId    = 1
+	partnerId  = 2
+	readerId   = 3
+	strangerId = 4
+	groupId    = -100
+)
+
+func newTestAuthorizer(t *testing.T, store *accessStore) *Authorizer {
+	t.Helper()
+	a, err := NewAuthorizer(store, []int64{adminId})
+	if err != nil && store.err == nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func newAccessStore() *accessStore {
+	return &accessStore{
+		users: []*models.User{
+			{Id: partnerId, Name: "Gustavo", Role: models.RolePartner},
+			{Id: readerId, Name: "Ana", Role: models.RoleReadOnly},
+		},
+		chats: []*models.AllowedChat{{Id: groupId, Name: "Sócios"}},
+	}
+}
+
+func TestAllowList(t *testing.T) {
+	a := newTestAuthorizer(t, newAccessStore())
+
+	tests := []struct {
+		userId  int64
+		role    models.Role
+		allowed bool
+	}{
+		{adminId, models.RoleAdmin, true},
+		{partnerId, models.RolePartner, true},
+		{readerId, models.RoleReadOnly, true},
+		{strangerId, "", false},
+	}
+	for _, tt := range tests {
+		if role, ok := a.Role(tt.userId); role != tt.role || ok != tt.allowed {
+			t.Errorf("Role(%d) = %q, %v, want %q, %v", tt.userId, role, ok, tt.role, tt.allowed)
+		}
+	}
+
+	if !a.IsChatAllowed(partnerId, partnerId) {
+		t.Errorf("the private chat of a user is not allowed")
+	}
+	if !a.IsChatAllowed(groupId, partnerId) {
+		t.Errorf("the allowed group is not allowed")
+	}
+	if a.IsChatAllowed(-200, partnerId) {
+		t.Errorf("a group out of the allow-list is allowed")
+	}
+
+	if err := a.Revoke(adminId); !errors.Is(err, ErrCannotRevokeAdmin) {
+		t.Errorf("revoking a configured admin returned %v, want %v", err, ErrCannotRevokeAdmin)
+	}
+	if err := a.Revoke(readerId); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := a.Role(readerId); ok {
+		t.Errorf("revoked user is still allowed")
+	}
+}
+
+func TestAllowListFailingToLoad(t *testing.T) {
+	store := newAccessStore()
+	store.err = errors.New("planilha indisponível")
+	a := newTestAuthorizer(t, store)
+
+	if _, ok := a.Role(adminId); !ok {
+		t.Errorf("the admin is not allowed while the allow-list fails to load")
+	}
+	if _, ok := a.Role(partnerId); ok {
+		t.Errorf("a user is allowed while the allow-list fails to load")
+	}
+
+	store.err = nil
+	if role, ok := a.Role(partnerId); !ok || role != models.RolePartner {
+		t.Errorf("Role(%d) = %q, %v once the allow-list loads, want %q", partnerId, role, ok, models.RolePartner)
+	}
+}
+
+func TestHasRole(t *testing.T) {
+	writers := []models.Role{models.RoleAdmin, models.RolePartner}
+	tests := []struct {
+		role models.Role
+		want bool
+	}{
+		{models.RoleAdmin, true},
+		{models.RolePartner, true},
+		{models.RoleReadOnly, false},
+		{models.RoleCleaner, false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := HasRole(tt.role, writers); got != tt.want {
+			t.Errorf("HasRole(%q) = %v, want %v", tt.role, got, tt.want)
+		}
+	}
+}
+
+func TestInvite(t *testing.T) {
+	store := newAccessStore()
+	a := newTestAuthorizer(t, store)
+	now := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+
+	code, err := a.Invite(models.RoleCleaner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Redeem("NAOEXISTE", strangerId, "Bia"); !errors.Is(err, ErrInvalidInviteCode) {
+		t.Errorf("redeeming an unknown code returned %v, want %v", err, ErrInvalidInviteCode)
+	}
+
+	u, err := a.Redeem(code, strangerId, "Bia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Role != models.RoleCleaner || u.Name != "Bia" {
+		t.Errorf("redeemed user %+v, want Bia as %q", u, models.RoleCleaner)
+	}
+	if role, ok := a.Role(strangerId); !ok || role != models.RoleCleaner {
+		t.Errorf("Role(%d) = %q, %v after redeeming, want %q", strangerId, role, ok, models.RoleCleaner)
+	}
+	if len(store.users) != 3 {
+		t.Errorf("the redeemed user was not stored: %v", store.users)
+	}
+
+	if _, err := a.Redeem(code, 5, "Caio"); !errors.Is(err, ErrInvalidInviteCode) {
+		t.Errorf("redeeming a code twice returned %v, want %v", err, ErrInvalidInviteCode)
+	}
+
+	expiring, err := a.Invite(models.RolePartner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(inviteTTL + time.Minute)
+	if _, err := a.Redeem(expiring, 5, "Caio"); !errors.Is(err, ErrInvalidInviteCode) {
+		t.Errorf("redeeming an expired code returned %v, want %v", err, ErrInvalidInviteCode)
+	}
+	if _, ok := a.Role(5); ok {
+		t.Errorf("an expired invite allowed its user")
+	}
+}
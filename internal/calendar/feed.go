@@ -0,0 +1,106 @@
+package calendar
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+const FeedPath = "/calendario/"
+
+// Feed serves the rents of each apartment as an iCalendar feed, protected by a per apartment token derived from a secret.
+// Feeds are addressed by the id of the apartment, so renaming it keeps the address registered on Airbnb and Booking
+type Feed struct {
+	store   storage.Store
+	baseURL string
+	secret  []byte
+}
+
+func NewFeed(store storage.Store, baseURL string, secret string) *Feed {
+	return &Feed{
+		store:   store,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		secret:  []byte(secret),
+	}
+}
+
+// Token returns the secret token which gives access to the apartment feed
+func (f *Feed) Token(apartment models.Apartment) string {
+	mac := hmac.New(sha256.New, f.secret)
+	mac.Write([]byte(strconv.FormatInt(apartment.Id, 10)))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// URL returns the address to be registered on Airbnb, Booking, etc. to import the apartment calendar
+func (f *Feed) URL(apartment models.Apartment) string {
+	return fmt.Sprintf("%s%s%d.ics?token=%s", f.baseURL, FeedPath, apartment.Id, f.Token(apartment))
+}
+
+// Calendar renders the current iCalendar of an apartment
+func Calendar(store storage.Store, apartment models.Apartment) ([]byte, error) {
+	rents, err := store.GetExistingRents(apartment)
+	if err != nil {
+		return nil, err
+	}
+	return Export(apartment, rents, time.Now()), nil
+}
+
+func (f *Feed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, FeedPath)
+	if !strings.HasSuffix(name, ".ics") {
+		http.NotFound(w, r)
+		return
+	}
+	id, err := strconv.ParseInt(strings.TrimSuffix(name, ".ics"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	if !hmac.Equal([]byte(token), []byte(f.Token(models.Apartment{Id: id}))) {
+		http.NotFound(w, r)
+		return
+	}
+
+	apartments, err := f.store.GetApartments()
+	if err != nil {
+		log.Printf("failed to get apartments for calendar feed: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	var apartment *models.Apartment
+	for _, a := range apartments {
+		if a.Id == id {
+			apartment = a
+		}
+	}
+	if apartment == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	ics, err := Calendar(f.store, *apartment)
+	if err != nil {
+		log.Printf("failed to export calendar of %s: %v", apartment.Name, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Write(ics)
+}
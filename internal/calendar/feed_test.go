@@ -0,0 +1,82 @@
+package calendar
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// feedStore holds the apartments and rents served by the feed, the other methods of the store are not expected to be
+// called
+type feedStore struct {
+	storage.Store
+	apartments []*models.Apartment
+	rents      map[int64][]*models.Rent
+}
+
+func (s *feedStore) GetApartments() ([]*models.Apartment, error) {
+	return s.apartments, nil
+}
+
+func (s *feedStore) GetExistingRents(a models.Apartment) ([]*models.Rent, error) {
+	return s.rents[a.Id], nil
+}
+
+func TestFeed(t *testing.T) {
+	centro := &models.Apartment{Id: 1, Name: "Centro"}
+	praia := &models.Apartment{Id: 2, Name: "Praia"}
+	store := &feedStore{
+		apartments: []*models.Apartment{centro, praia},
+		rents: map[int64][]*models.Rent{
+			1: {{DateBegin: day(2024, time.March, 1), DateEnd: day(2024, time.March, 4), Renter: "Ana"}},
+		},
+	}
+	feed := NewFeed(store, "https://hoteleiro.example.com/", "segredo")
+
+	if got, want := feed.URL(*centro), "https://hoteleiro.example.com/calendario/1.ics?token="+feed.Token(*centro); got != want {
+		t.Errorf("URL = %q, want %q", got, want)
+	}
+	if feed.Token(*centro) == feed.Token(*praia) {
+		t.Errorf("apartments share the token %q", feed.Token(*centro))
+	}
+	if other := NewFeed(store, "https://hoteleiro.example.com", "outro segredo"); other.Token(*centro) == feed.Token(*centro) {
+		t.Errorf("the token does not depend on the secret")
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		status int
+	}{
+		{"valid token", http.MethodGet, "/calendario/1.ics?token=" + feed.Token(*centro), http.StatusOK},
+		{"head", http.MethodHead, "/calendario/1.ics?token=" + feed.Token(*centro), http.StatusOK},
+		{"no token", http.MethodGet, "/calendario/1.ics", http.StatusNotFound},
+		{"wrong token", http.MethodGet, "/calendario/1.ics?token=0123456789abcdef0123456789abcdef", http.StatusNotFound},
+		{"token of another apartment", http.MethodGet, "/calendario/1.ics?token=" + feed.Token(*praia), http.StatusNotFound},
+		{"unknown apartment", http.MethodGet, "/calendario/3.ics?token=" + feed.Token(models.Apartment{Id: 3}), http.StatusNotFound},
+		{"not a calendar", http.MethodGet, "/calendario/1?token=" + feed.Token(*centro), http.StatusNotFound},
+		{"post", http.MethodPost, "/calendario/1.ics?token=" + feed.Token(*centro), http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		feed.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.status)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	feed.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/calendario/1.ics?token="+feed.Token(*centro), nil))
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Errorf("content type %q, want text/calendar", ct)
+	}
+	if body := w.Body.String(); !strings.Contains(body, "DTSTART;VALUE=DATE:20240301") {
+		t.Errorf("feed is missing the rent:\n%s", body)
+	}
+}
@@ -0,0 +1,84 @@
+package calendar
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+const (
+	icalDateLayout     = "20060102"
+	icalDateTimeLayout = "20060102T150405Z"
+	icalLineLimit      = 75
+	productId          = "-//hoteleiro//hoteleiro bot//PT"
+)
+
+// RentUID returns an identifier for the rent which does not change between exports of the same calendar, nor when
+// the apartment is renamed
+func RentUID(apartment models.Apartment, r *models.Rent) string {
+	h := sha1.Sum([]byte(fmt.Sprintf("%d|%s", apartment.Id, r.DateBegin.Format(icalDateLayout))))
+	return fmt.Sprintf("%x@hoteleiro", h)
+}
+
+// Export renders the rents of an apartment as an iCalendar, each rent being a VEVENT that blocks the days of the stay
+func Export(apartment models.Apartment, rents []*models.Rent, now time.Time) []byte {
+	var b strings.Builder
+
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+productId)
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	writeLine(&b, "X-WR-CALNAME:"+escapeText(apartment.Name))
+
+	for _, r := range rents {
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, "UID:"+RentUID(apartment, r))
+		writeLine(&b, "DTSTAMP:"+now.UTC().Format(icalDateTimeLayout))
+		writeLine(&b, "DTSTART;VALUE=DATE:"+r.DateBegin.Format(icalDateLayout))
+		writeLine(&b, "DTEND;VALUE=DATE:"+r.DateEnd.Format(icalDateLayout))
+		// the feed is shared with the platforms, so the guests are not named
+		writeLine(&b, "SUMMARY:Reservado")
+		writeLine(&b, "TRANSP:OPAQUE")
+		writeLine(&b, "END:VEVENT")
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+
+	return []byte(b.String())
+}
+
+// writeLine writes a content line folding it at 75 octets, as required by RFC 5545. The leading space of the
+// continuation lines counts towards their limit
+func writeLine(b *strings.Builder, line string) {
+	limit := icalLineLimit
+	for len(line) > limit {
+		cut := limit
+		// never split a multi-byte character
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icalLineLimit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
+
+func escapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\n", `\n`,
+	).Replace(s)
+}
@@ -0,0 +1,121 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+func TestRentUID(t *testing.T) {
+	apartment := models.Apartment{Id: 42, Name: "Centro"}
+	rent := &models.Rent{DateBegin: day(2024, time.March, 1), DateEnd: day(2024, time.March, 4), Value: 900, Renter: "Ana"}
+	uid := RentUID(apartment, rent)
+
+	renamed := models.Apartment{Id: 42, Name: "Centro 2"}
+	changed := &models.Rent{DateBegin: rent.DateBegin, DateEnd: day(2024, time.March, 5), Value: 1200, Renter: "Bia"}
+	if got := RentUID(renamed, changed); got != uid {
+		t.Errorf("UID changed to %q after renaming the apartment and editing the rent, want %q", got, uid)
+	}
+
+	other := &models.Rent{DateBegin: day(2024, time.March, 4), DateEnd: day(2024, time.March, 6)}
+	if RentUID(apartment, other) == uid {
+		t.Errorf("rents checking in on different days share the UID %q", uid)
+	}
+	if RentUID(models.Apartment{Id: 43, Name: "Centro"}, rent) == uid {
+		t.Errorf("rents of different apartments share the UID %q", uid)
+	}
+}
+
+func TestExport(t *testing.T) {
+	apartment := models.Apartment{Id: 7, Name: "Praia, bloco A; 2"}
+	rents := []*models.Rent{
+		{DateBegin: day(2024, time.March, 1), DateEnd: day(2024, time.March, 4), Renter: "Ana Souza"},
+		{DateBegin: day(2024, time.March, 10), DateEnd: day(2024, time.March, 12), Renter: "Bia"},
+	}
+	ics := Export(apartment, rents, time.Date(2024, time.February, 20, 12, 0, 0, 0, time.UTC))
+
+	if !bytes.Contains(ics, []byte("X-WR-CALNAME:Praia\\, bloco A\\; 2\r\n")) {
+		t.Errorf("calendar name not escaped:\n%s", ics)
+	}
+	for _, r := range rents {
+		if bytes.Contains(ics, []byte(r.Renter)) {
+			t.Errorf("the export names the guest %q", r.Renter)
+		}
+	}
+
+	cal, err := Parse(bytes.NewReader(ics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cal.Events) != len(rents) {
+		t.Fatalf("%d events, want %d", len(cal.Events), len(rents))
+	}
+	for i, e := range cal.Events {
+		if e.Summary != "Reservado" {
+			t.Errorf("event %d summary %q, want %q", i, e.Summary, "Reservado")
+		}
+		if !e.DateBegin.Equal(rents[i].DateBegin) || !e.DateEnd.Equal(rents[i].DateEnd) {
+			t.Errorf("event %d from %v to %v, want %v to %v", i, e.DateBegin, e.DateEnd, rents[i].DateBegin, rents[i].DateEnd)
+		}
+		if e.UID != RentUID(apartment, rents[i]) {
+			t.Errorf("event %d UID %q, want %q", i, e.UID, RentUID(apartment, rents[i]))
+		}
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Centro", "Centro"},
+		{"a,b;c", `a\,b\;c`},
+		{`C:\apto`, `C:\\apto`},
+		{"linha 1\nlinha 2", `linha 1\nlinha 2`},
+	}
+	for _, tt := range tests {
+		if got := escapeText(tt.in); got != tt.want {
+			t.Errorf("escapeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteLine(t *testing.T) {
+	tests := []string{
+		"SUMMARY:Reservado",
+		"X-WR-CALNAME:" + strings.Repeat("a", 200),
+		// multi-byte characters are never split
+		"X-WR-CALNAME:" + strings.Repeat("ção ", 60),
+	}
+	for _, line := range tests {
+		var b strings.Builder
+		writeLine(&b, line)
+		folded := b.String()
+		if !strings.HasSuffix(folded, "\r\n") {
+			t.Fatalf("line %q not terminated by CRLF", folded)
+		}
+
+		var unfolded strings.Builder
+		for i, l := range strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n") {
+			if len(l) > icalLineLimit {
+				t.Errorf("line %d has %d octets, more than %d: %q", i, len(l), icalLineLimit, l)
+			}
+			if !utf8.ValidString(l) {
+				t.Errorf("line %d splits a character: %q", i, l)
+			}
+			if i > 0 {
+				if !strings.HasPrefix(l, " ") {
+					t.Fatalf("continuation line %d does not begin with a space: %q", i, l)
+				}
+				l = l[1:]
+			}
+			unfolded.WriteString(l)
+		}
+		if unfolded.String() != line {
+			t.Errorf("unfolded %q, want %q", unfolded.String(), line)
+		}
+	}
+}
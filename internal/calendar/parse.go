@@ -0,0 +1,173 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const fetchTimeout = 30 * time.Second
+
+// Event is a VEVENT read from an iCalendar, its dates are days as stays are booked by night
+type Event struct {
+	UID       string
+	Summary   string
+	DateBegin time.Time
+	DateEnd   time.Time
+	Cancelled bool
+}
+
+// ParsedCalendar is an iCalendar read from an OTA, Source is who published it, like "Airbnb Inc" or "admin.booking.com"
+type ParsedCalendar struct {
+	Source string
+	Events []*Event
+}
+
+// Parse reads the events of an iCalendar
+func Parse(r io.Reader) (*ParsedCalendar, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &ParsedCalendar{}
+	var current *Event
+	for i, line := range lines {
+		name, params, value := splitContentLine(line)
+		switch {
+		case name == "PRODID" && current == nil:
+			cal.Source = productOwner(value)
+		case name == "BEGIN" && value == "VEVENT":
+			current = &Event{}
+		case name == "END" && value == "VEVENT":
+			if current == nil {
+				return nil, fmt.Errorf("linha %d: fim de evento sem início", i+1)
+			}
+			if current.DateBegin.IsZero() {
+				return nil, fmt.Errorf("linha %d: evento %q sem data de início", i+1, current.UID)
+			}
+			if current.DateEnd.IsZero() {
+				current.DateEnd = current.DateBegin.AddDate(0, 0, 1)
+			}
+			cal.Events = append(cal.Events, current)
+			current = nil
+		case current == nil:
+			continue
+		case name == "UID":
+			current.UID = value
+		case name == "SUMMARY":
+			current.Summary = unescapeText(value)
+		case name == "STATUS":
+			current.Cancelled = strings.EqualFold(value, "CANCELLED")
+		case name == "DTSTART", name == "DTEND":
+			t, err := parseDate(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("linha %d: %v", i+1, err)
+			}
+			if name == "DTSTART" {
+				current.DateBegin = t
+			} else {
+				current.DateEnd = t
+			}
+		}
+	}
+
+	return cal, nil
+}
+
+// Fetch downloads and parses the iCalendar published at url
+func Fetch(url string) (*ParsedCalendar, error) {
+	client := http.Client{Timeout: fetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("falha ao baixar o calendário: %s", resp.Status)
+	}
+
+	return Parse(resp.Body)
+}
+
+// productOwner returns the owner of a product identifier like "-//Airbnb Inc//Hosting Calendar 0.8.8//EN", which
+// unlike the version does not change between exports
+func productOwner(prodId string) string {
+	parts := strings.Split(prodId, "//")
+	if len(parts) < 2 {
+		return strings.TrimSpace(prodId)
+	}
+	return strings.TrimSpace(parts[1])
+}
+
+func unfoldLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) == 0 {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// splitContentLine splits a line like "DTSTART;VALUE=DATE:20221020" into its name, parameters and value
+func splitContentLine(line string) (string, map[string]string, string) {
+	head, value, _ := strings.Cut(line, ":")
+	parts := strings.Split(head, ";")
+
+	params := make(map[string]string)
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+
+	return strings.ToUpper(parts[0]), params, value
+}
+
+func parseDate(value string, params map[string]string) (time.Time, error) {
+	loc := time.UTC
+	if tzid, ok := params["TZID"]; ok {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+
+	var t time.Time
+	var err error
+	switch {
+	case params["VALUE"] == "DATE" || len(value) == len(icalDateLayout):
+		t, err = time.Parse(icalDateLayout, value)
+	case strings.HasSuffix(value, "Z"):
+		t, err = time.Parse(icalDateTimeLayout, value)
+	default:
+		t, err = time.ParseInLocation("20060102T150405", value, loc)
+	}
+	if err != nil {
+		return t, fmt.Errorf("%v nao é uma data válida", value)
+	}
+
+	// stays are counted in days, so the time of day is dropped
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+func unescapeText(s string) string {
+	return strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	).Replace(s)
+}
@@ -0,0 +1,101 @@
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func parseFixture(t *testing.T, name string) *ParsedCalendar {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	cal, err := Parse(f)
+	if err != nil {
+		t.Fatalf("parsing %v: %v", name, err)
+	}
+	return cal
+}
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseAirbnb(t *testing.T) {
+	cal := parseFixture(t, "airbnb.ics")
+
+	if cal.Source != "Airbnb Inc" {
+		t.Errorf("source = %q, want %q", cal.Source, "Airbnb Inc")
+	}
+	if len(cal.Events) != 3 {
+		t.Fatalf("got %d events, want 3", len(cal.Events))
+	}
+
+	e := cal.Events[0]
+	if e.UID != "1418fb94e984-3f2a7c6d9e1b0a5f8c4d2e6b7a9f0c1d@airbnb.com" {
+		t.Errorf("uid = %q", e.UID)
+	}
+	if !e.DateBegin.Equal(day(2022, 10, 20)) || !e.DateEnd.Equal(day(2022, 10, 23)) {
+		t.Errorf("stay = %v to %v, want 20/10/2022 to 23/10/2022", e.DateBegin, e.DateEnd)
+	}
+	if e.GuestName() != "" {
+		t.Errorf("guest = %q, want none", e.GuestName())
+	}
+	if !isReservation(e) {
+		t.Error("reserved event is not a reservation")
+	}
+	if isReservation(cal.Events[2]) {
+		t.Error("not available days are a reservation")
+	}
+}
+
+func TestParseBooking(t *testing.T) {
+	cal := parseFixture(t, "booking.ics")
+
+	if cal.Source != "admin.booking.com" {
+		t.Errorf("source = %q, want %q", cal.Source, "admin.booking.com")
+	}
+	if len(cal.Events) != 4 {
+		t.Fatalf("got %d events, want 4", len(cal.Events))
+	}
+
+	tests := []struct {
+		guest       string
+		begin, end  time.Time
+		cancelled   bool
+		reservation bool
+	}{
+		{"Maria Souza", day(2022, 11, 10), day(2022, 11, 13), false, true},
+		{"Jo, Ana", day(2022, 11, 15), day(2022, 11, 18), true, false},
+		{"Not available", day(2022, 12, 20), day(2022, 12, 24), false, false},
+		{"Pedro", day(2022, 12, 26), day(2022, 12, 30), false, false},
+	}
+	for i, tt := range tests {
+		e := cal.Events[i]
+		if e.GuestName() != tt.guest {
+			t.Errorf("event %d: guest = %q, want %q", i, e.GuestName(), tt.guest)
+		}
+		if !e.DateBegin.Equal(tt.begin) || !e.DateEnd.Equal(tt.end) {
+			t.Errorf("event %d: stay = %v to %v, want %v to %v", i, e.DateBegin, e.DateEnd, tt.begin, tt.end)
+		}
+		if e.Cancelled != tt.cancelled {
+			t.Errorf("event %d: cancelled = %v, want %v", i, e.Cancelled, tt.cancelled)
+		}
+		if isReservation(e) != tt.reservation {
+			t.Errorf("event %d: reservation = %v, want %v", i, isReservation(e), tt.reservation)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := Parse(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:x\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"))
+	if err == nil {
+		t.Error("event without start parsed")
+	}
+}
@@ -0,0 +1,127 @@
+package calendar
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+// summaries used by Airbnb and Booking for days blocked by the host, which are not reservations
+var blockedSummaries = []string{"not available", "closed", "blocked", "indisponível"}
+
+type Diff struct {
+	// Missing are reservations of the calendar without a rent registered
+	Missing []*Event
+	// Changed are reservations which overlap a rent but with different dates
+	Changed []*Change
+	// Cancelled are cancelled reservations that still have a rent registered, either marked as cancelled or gone
+	// from the calendar since the previous sync
+	Cancelled []*Change
+}
+
+type Change struct {
+	Event *Event
+	Rent  *models.Rent
+}
+
+// DiffRents compares the reservations of a calendar against the rents already registered for the apartment. The
+// previous are the reservations of the last sync of the same source, the ones missing now being cancelled unless
+// their stay has already ended, as past stays leave the calendar too
+func DiffRents(cal *ParsedCalendar, previous []*models.CalendarBooking, rents []*models.Rent, now time.Time) *Diff {
+	d := &Diff{}
+	listed := make(map[string]bool)
+	for _, e := range cal.Events {
+		listed[e.UID] = true
+		if isOwnEvent(e) || isBlockedDays(e) {
+			continue
+		}
+
+		rent := overlappingRent(e, rents)
+		switch {
+		case e.Cancelled && rent != nil:
+			d.Cancelled = append(d.Cancelled, &Change{Event: e, Rent: rent})
+		case e.Cancelled:
+			continue
+		case rent == nil:
+			d.Missing = append(d.Missing, e)
+		case !rent.DateBegin.Equal(e.DateBegin) || !rent.DateEnd.Equal(e.DateEnd):
+			d.Changed = append(d.Changed, &Change{Event: e, Rent: rent})
+		}
+	}
+
+	for _, b := range previous {
+		if listed[b.UID] || !b.DateEnd.After(now) {
+			continue
+		}
+		e := &Event{UID: b.UID, DateBegin: b.DateBegin, DateEnd: b.DateEnd, Cancelled: true}
+		if rent := overlappingRent(e, rents); rent != nil {
+			d.Cancelled = append(d.Cancelled, &Change{Event: e, Rent: rent})
+		}
+	}
+	return d
+}
+
+// Bookings returns the reservations of the calendar to be kept as the last sync of its source
+func (c *ParsedCalendar) Bookings(apartment models.Apartment) []*models.CalendarBooking {
+	var bookings []*models.CalendarBooking
+	for _, e := range c.Events {
+		if !isReservation(e) {
+			continue
+		}
+		bookings = append(bookings, &models.CalendarBooking{
+			Source:    c.Source,
+			UID:       e.UID,
+			DateBegin: e.DateBegin,
+			DateEnd:   e.DateEnd,
+			Apartment: apartment,
+		})
+	}
+	return bookings
+}
+
+// GuestName returns the name of the guest informed in the event, if any
+func (e *Event) GuestName() string {
+	summary := strings.TrimSpace(e.Summary)
+	for _, prefix := range []string{"Reserved -", "Reservado -", "CLOSED -"} {
+		summary = strings.TrimSpace(strings.TrimPrefix(summary, prefix))
+	}
+	if strings.EqualFold(summary, "Reserved") || strings.EqualFold(summary, "Reservado") {
+		return ""
+	}
+	return summary
+}
+
+// isOwnEvent tells if the event was exported by hoteleiro itself
+func isOwnEvent(e *Event) bool {
+	return strings.HasSuffix(e.UID, "@hoteleiro")
+}
+
+// isReservation tells if the event is a stay booked by a guest
+func isReservation(e *Event) bool {
+	return !e.Cancelled && !isOwnEvent(e) && !isBlockedDays(e)
+}
+
+// isBlockedDays tells if the event blocks days without a guest, Booking names both as "CLOSED - " followed by the
+// guest or by "Not available", so the prefix is not enough
+func isBlockedDays(e *Event) bool {
+	summary := strings.ToLower(e.Summary)
+	if guest := e.GuestName(); len(guest) > 0 {
+		summary = strings.ToLower(guest)
+	}
+	for _, s := range blockedSummaries {
+		if strings.Contains(summary, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlappingRent(e *Event, rents []*models.Rent) *models.Rent {
+	for _, r := range rents {
+		if e.DateBegin.Before(r.DateEnd) && r.DateBegin.Before(e.DateEnd) {
+			return r
+		}
+	}
+	return nil
+}
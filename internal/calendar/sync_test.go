@@ -0,0 +1,55 @@
+package calendar
+
+import (
+	"testing"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+func TestDiffRentsStatusCancelled(t *testing.T) {
+	cal := parseFixture(t, "booking.ics")
+	rents := []*models.Rent{
+		{DateBegin: day(2022, 11, 10), DateEnd: day(2022, 11, 12)},
+		{DateBegin: day(2022, 11, 15), DateEnd: day(2022, 11, 18)},
+	}
+
+	d := DiffRents(cal, nil, rents, day(2022, 10, 15))
+
+	if len(d.Missing) != 0 {
+		t.Errorf("got %d missing, want 0", len(d.Missing))
+	}
+	if len(d.Changed) != 1 || d.Changed[0].Rent != rents[0] {
+		t.Errorf("changed = %v, want the rent of 10/11/2022", d.Changed)
+	}
+	if len(d.Cancelled) != 1 || d.Cancelled[0].Rent != rents[1] {
+		t.Errorf("cancelled = %v, want the rent of 15/11/2022", d.Cancelled)
+	}
+}
+
+func TestDiffRentsRemovedSincePreviousSync(t *testing.T) {
+	apartment := models.Apartment{Name: "Apto 101"}
+	previous := parseFixture(t, "airbnb.ics").Bookings(apartment)
+	if len(previous) != 2 {
+		t.Fatalf("got %d bookings, want the 2 reservations", len(previous))
+	}
+
+	cal := parseFixture(t, "airbnb_cancelled.ics")
+	rents := []*models.Rent{
+		{DateBegin: day(2022, 10, 20), DateEnd: day(2022, 10, 23)},
+		{DateBegin: day(2022, 11, 1), DateEnd: day(2022, 11, 5)},
+	}
+
+	d := DiffRents(cal, previous, rents, day(2022, 10, 15))
+	if len(d.Missing) != 0 || len(d.Changed) != 0 {
+		t.Errorf("got %d missing and %d changed, want none", len(d.Missing), len(d.Changed))
+	}
+	if len(d.Cancelled) != 1 || d.Cancelled[0].Rent != rents[1] {
+		t.Fatalf("cancelled = %v, want the rent of 01/11/2022", d.Cancelled)
+	}
+
+	// a stay that has ended leaves the calendar without being cancelled
+	d = DiffRents(cal, previous, rents, day(2022, 11, 6))
+	if len(d.Cancelled) != 0 {
+		t.Errorf("got %d cancelled after the stay, want 0", len(d.Cancelled))
+	}
+}
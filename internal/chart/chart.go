@@ -0,0 +1,295 @@
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/analytics"
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/report"
+)
+
+const (
+	width       = 900
+	panelHeight = 300
+	padding     = 20
+	// the left of the plots holds the labels of the axis
+	axisWidth = 160
+	scale     = 2
+	gridLines = 4
+)
+
+var (
+	background   = color.White
+	ink          = color.Black
+	gridColor    = color.RGBA{R: 0xdd, G: 0xdd, B: 0xdd, A: 0xff}
+	incomeColor  = color.RGBA{R: 0x2e, G: 0x7d, B: 0x32, A: 0xff}
+	expenseColor = color.RGBA{R: 0xc6, G: 0x28, B: 0x28, A: 0xff}
+	lineColor    = color.RGBA{R: 0x15, G: 0x65, B: 0xc0, A: 0xff}
+)
+
+// Month is the income, expenses and occupancy of an apartment in a month
+type Month struct {
+	Month     time.Time
+	Income    float64
+	Expenses  float64
+	Occupancy float64
+}
+
+// Category is the total of the expenses of a kind
+type Category struct {
+	Label string
+	Value float64
+}
+
+// Data is what the charts of an apartment show
+type Data struct {
+	Title      string
+	Months     []Month
+	Categories []Category
+}
+
+// NewData sums the statement by month and by category, the occupancy of each month coming from the rents of the
+// apartment, including the ones which began before the period, until now
+func NewData(statement *report.Statement, rents []*models.Rent, now time.Time) *Data {
+	d := &Data{Title: fmt.Sprintf("%v - %v", statement.Apartment, statement.Period.Label)}
+	index := make(map[time.Time]int)
+	for _, m := range analytics.Monthly(rents, statement.Period.Begin, statement.Period.End.AddDate(0, 0, -1), now) {
+		index[m.Month] = len(d.Months)
+		d.Months = append(d.Months, Month{Month: m.Month, Occupancy: m.Occupancy()})
+	}
+	month := func(date time.Time) *Month {
+		i, ok := index[time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())]
+		if !ok {
+			return &Month{}
+		}
+		return &d.Months[i]
+	}
+
+	for _, r := range statement.Rents {
+		month(r.DateBegin).Income += r.Value
+	}
+	for _, t := range models.RecordTypes {
+		entries := statement.Expenses[t]
+		if len(entries) == 0 {
+			continue
+		}
+		for _, e := range entries {
+			month(e.Date).Expenses += e.Value
+		}
+		d.Categories = append(d.Categories, Category{Label: string(t), Value: statement.CategoryTotal(t)})
+	}
+	return d
+}
+
+// Render draws the income against the expenses of each month, the expenses by category and the occupancy of each
+// month, one panel below the other, as a PNG image
+func Render(d *Data) ([]byte, error) {
+	titleHeight := glyphHeight*scale*2 + padding
+	img := image.NewRGBA(image.Rect(0, 0, width, titleHeight+3*panelHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	drawText(img, padding, padding/2, d.Title, scale*2, ink)
+
+	top := titleHeight
+	incomeExpenses(img, d.Months, top)
+	top += panelHeight
+	categories(img, d.Categories, top)
+	top += panelHeight
+	occupancy(img, d.Months, top)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// plot is the area of a panel inside its title and axis
+type plot struct {
+	left, top, width, height int
+}
+
+func newPlot(img *image.RGBA, title string, top int) plot {
+	drawText(img, padding, top+padding/2, title, scale, ink)
+	labelsHeight := glyphHeight*scale + padding
+	p := plot{left: axisWidth, top: top + padding + glyphHeight*scale}
+	p.width = width - axisWidth - padding
+	p.height = panelHeight - (p.top - top) - labelsHeight
+	return p
+}
+
+// grid draws the horizontal lines of the plot labeled from zero to max
+func (p plot) grid(img *image.RGBA, max float64, label func(float64) string) {
+	for i := 0; i <= gridLines; i++ {
+		y := p.top + p.height - p.height*i/gridLines
+		fillRect(img, p.left, y, p.width, 1, gridColor)
+		text := label(max * float64(i) / gridLines)
+		drawText(img, p.left-textWidth(text, scale)-8, y-glyphHeight*scale/2, text, scale, ink)
+	}
+}
+
+func incomeExpenses(img *image.RGBA, months []Month, top int) {
+	p := newPlot(img, "Receitas e despesas por mês", top)
+	max := 0.0
+	for _, m := range months {
+		max = maxFloat(max, maxFloat(m.Income, m.Expenses))
+	}
+	if max == 0 {
+		max = 1
+	}
+	p.grid(img, max, money)
+	legend(img, top, []string{"Receitas", "Despesas"}, []color.Color{incomeColor, expenseColor})
+	if len(months) == 0 {
+		return
+	}
+
+	group := p.width / len(months)
+	bar := group / 3
+	for i, m := range months {
+		x := p.left + i*group + group/6
+		for j, v := range []float64{m.Income, m.Expenses} {
+			h := int(float64(p.height) * v / max)
+			c := incomeColor
+			if j == 1 {
+				c = expenseColor
+			}
+			fillRect(img, x+j*bar, p.top+p.height-h, bar, h, c)
+		}
+		monthLabel(img, p, i, group, m.Month, len(months))
+	}
+}
+
+func categories(img *image.RGBA, categories []Category, top int) {
+	p := newPlot(img, "Despesas por categoria", top)
+	if len(categories) == 0 {
+		drawText(img, p.left, p.top+p.height/2, "Nenhuma despesa", scale, ink)
+		return
+	}
+	max := 0.0
+	for _, c := range categories {
+		max = maxFloat(max, c.Value)
+	}
+
+	row := p.height / len(categories)
+	// leaves room right of the longest bar for its value
+	length := p.width - textWidth(money(max), scale) - 8
+	for i, c := range categories {
+		y := p.top + i*row
+		h := minInt(row*2/3, glyphHeight*scale*3)
+		drawText(img, p.left-textWidth(c.Label, scale)-8, y+(h-glyphHeight*scale)/2, c.Label, scale, ink)
+		w := 0
+		if max > 0 && c.Value > 0 {
+			w = int(float64(length) * c.Value / max)
+		}
+		fillRect(img, p.left, y, w, h, expenseColor)
+		drawText(img, p.left+w+8, y+(h-glyphHeight*scale)/2, money(c.Value), scale, ink)
+	}
+}
+
+func occupancy(img *image.RGBA, months []Month, top int) {
+	p := newPlot(img, "Ocupaçao por mês", top)
+	p.grid(img, 1, func(v float64) string { return fmt.Sprintf("%.0f%%", v*100) })
+	if len(months) == 0 {
+		return
+	}
+
+	group := p.width / len(months)
+	var previous image.Point
+	for i, m := range months {
+		point := image.Point{X: p.left + i*group + group/2, Y: p.top + p.height - int(float64(p.height)*m.Occupancy)}
+		if i > 0 {
+			drawLine(img, previous, point, lineColor)
+		}
+		fillRect(img, point.X-3, point.Y-3, 7, 7, lineColor)
+		previous = point
+		monthLabel(img, p, i, group, m.Month, len(months))
+	}
+}
+
+// legend writes the labels of the colors at the right of the panel title
+func legend(img *image.RGBA, top int, labels []string, colors []color.Color) {
+	x := width - padding
+	for i := len(labels) - 1; i >= 0; i-- {
+		x -= textWidth(labels[i], scale)
+		drawText(img, x, top+padding/2, labels[i], scale, ink)
+		x -= glyphHeight*scale + 6
+		fillRect(img, x, top+padding/2, glyphHeight*scale, glyphHeight*scale, colors[i])
+		x -= padding
+	}
+}
+
+// monthLabel writes the month below its group, skipping some when they would overlap
+func monthLabel(img *image.RGBA, p plot, i, group int, month time.Time, months int) {
+	label := month.Format("01/06")
+	every := 1
+	for textWidth(label, scale)+8 > group*every && every < months {
+		every++
+	}
+	if i%every != 0 {
+		return
+	}
+	x := p.left + i*group + (group-textWidth(label, scale))/2
+	drawText(img, x, p.top+p.height+padding/2, label, scale, ink)
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("R$%.0f", v)
+}
+
+func fillRect(img *image.RGBA, x, y, w, h int, c color.Color) {
+	draw.Draw(img, image.Rect(x, y, x+w, y+h), image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// drawLine draws a line three pixels thick between the points
+func drawLine(img *image.RGBA, from, to image.Point, c color.Color) {
+	dx, dy := absInt(to.X-from.X), -absInt(to.Y-from.Y)
+	sx, sy := 1, 1
+	if from.X > to.X {
+		sx = -1
+	}
+	if from.Y > to.Y {
+		sy = -1
+	}
+	err := dx + dy
+	for x, y := from.X, from.Y; ; {
+		fillRect(img, x-1, y-1, 3, 3, c)
+		if x == to.X && y == to.Y {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y += sy
+		}
+	}
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func absInt(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,130 @@
+package chat_flow
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/auth"
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+const revokedChatPrefix = "chat:"
+
+type inviteSession struct {
+	authorizer *auth.Authorizer
+	step       Step
+}
+
+// NewInviteSession creates a one-time code which allows someone to use the bot with the chosen role
+func NewInviteSession(authorizer *auth.Authorizer) ChatSession {
+	return &inviteSession{
+		authorizer: authorizer,
+		step:       stepBeginInvite,
+	}
+}
+
+func (s *inviteSession) Next(answer string) (string, interface{}) {
+	switch s.step {
+	case stepBeginInvite:
+		s.step = stepGetInviteRole
+		keyboard := tgbotapi.NewInlineKeyboardMarkup()
+		var row []tgbotapi.InlineKeyboardButton
+		for _, r := range models.Roles {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(string(r), string(r)))
+		}
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+		return "Qual o papel da pessoa convidada?", keyboard
+	case stepGetInviteRole:
+		role, ok := models.ParseRole(answer)
+		if !ok {
+			return "Papel inválido, selecione um dos papéis", nil
+		}
+		s.step = stepEnd
+		code, err := s.authorizer.Invite(role)
+		if err != nil {
+			return fmt.Sprintf("Falha ao criar o convite - %v", err.Error()), nil
+		}
+		return fmt.Sprintf("Convite criado! Peça para a pessoa enviar ao bot, em até 24 horas:\n/entrar %s", code), nil
+	}
+	return "", nil
+}
+
+// Ended tells the conversation is over
+func (s *inviteSession) Ended() bool {
+	return s.step == stepEnd
+}
+
+type revokeSession struct {
+	authorizer *auth.Authorizer
+	step       Step
+	keyboard   *paginatedKeyboard
+}
+
+// NewRevokeSession removes a user or a group chat from the allow-list
+func NewRevokeSession(authorizer *auth.Authorizer) ChatSession {
+	return &revokeSession{
+		authorizer: authorizer,
+		step:       stepBeginRevoke,
+	}
+}
+
+func (s *revokeSession) Next(answer string) (string, interface{}) {
+	switch s.step {
+	case stepBeginRevoke:
+		users, chats := s.authorizer.Users(), s.authorizer.Chats()
+		if len(users) == 0 && len(chats) == 0 {
+			s.step = stepEnd
+			return "Nenhum usuário ou grupo liberado", nil
+		}
+		sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+		sort.Slice(chats, func(i, j int) bool { return chats[i].Name < chats[j].Name })
+
+		var options []keyboardOption
+		for _, u := range users {
+			options = append(options, keyboardOption{label: u.ToString(), data: strconv.FormatInt(u.Id, 10)})
+		}
+		for _, c := range chats {
+			options = append(options, keyboardOption{label: c.ToString(), data: revokedChatPrefix + strconv.FormatInt(c.Id, 10)})
+		}
+		s.keyboard = newPaginatedKeyboard(options, 1)
+		s.step = stepGetRevoked
+		return "Quem deve perder o acesso?", s.keyboard.markup()
+	case stepGetRevoked:
+		if consumed, matched := s.keyboard.navigate(answer); consumed {
+			option, ok := s.keyboard.only()
+			if !ok {
+				if !matched {
+					return fmt.Sprintf("Ninguém encontrado com \"%s\"", answer), s.keyboard.markup()
+				}
+				return "Quem deve perder o acesso?", s.keyboard.markup()
+			}
+			answer = option.data
+		}
+		isChat := strings.HasPrefix(answer, revokedChatPrefix)
+		id, err := strconv.ParseInt(strings.TrimPrefix(answer, revokedChatPrefix), 10, 64)
+		if err != nil {
+			return "Selecione um dos usuários ou grupos da lista", nil
+		}
+
+		if isChat {
+			err = s.authorizer.RevokeChat(id)
+		} else {
+			err = s.authorizer.Revoke(id)
+		}
+		if err != nil {
+			return fmt.Sprintf("Falha ao remover o acesso - %v", err.Error()), nil
+		}
+		s.step = stepEnd
+		return "Acesso removido", nil
+	}
+	return "", nil
+}
+
+// Ended tells the conversation is over
+func (s *revokeSession) Ended() bool {
+	return s.step == stepEnd
+}
@@ -0,0 +1,164 @@
+package chat_flow
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/format"
+	"github.com/gustavolopess/hoteleiro/internal/importer"
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+const (
+	confirmAnswer         = "Confirmar"
+	cancelAnswer          = "Cancelar"
+	listingCallbackPrefix = "anuncio:"
+)
+
+type airbnbImportSession struct {
+	apartmentSelector
+	store        storage.Store
+	step         Step
+	reservations []*importer.AirbnbReservation
+	listing      string
+	preview      *importer.AirbnbPreview
+	batch        *models.Batch
+}
+
+// NewAirbnbImportSession reads the transaction history CSV of Airbnb and registers its reservations after a dry-run preview
+func NewAirbnbImportSession(store storage.Store) ChatSession {
+	return &airbnbImportSession{
+		apartmentSelector: newApartmentSelector(store),
+		store:             store,
+		step:              stepBeginAirbnbImport,
+	}
+}
+
+func (s *airbnbImportSession) Next(answer string) (string, interface{}) {
+	replyText, markup := s.next(answer)
+	return s.withApartmentName(replyText), markup
+}
+
+// Ended tells the conversation is over
+func (s *airbnbImportSession) Ended() bool {
+	return s.step == stepEnd
+}
+
+func (s *airbnbImportSession) ReceiveDocument(name string, data []byte) (string, interface{}) {
+	if s.step != stepGetAirbnbCsv {
+		return "Nao estou esperando um arquivo agora", nil
+	}
+
+	reservations, err := importer.ParseAirbnbCSV(bytes.NewReader(data))
+	if err != nil {
+		return s.withApartmentName(fmt.Sprintf("Falha ao ler o arquivo %s - %v", name, err.Error())), nil
+	}
+	if len(reservations) == 0 {
+		return s.withApartmentName("Nenhuma reserva encontrada no arquivo"), nil
+	}
+	s.reservations = reservations
+
+	listings := importer.Listings(reservations)
+	if len(listings) > 1 {
+		s.step = stepGetAirbnbListing
+		keyboard := tgbotapi.NewInlineKeyboardMarkup()
+		for i, l := range listings {
+			data := format.CallbackData(listingCallbackPrefix, strconv.Itoa(i))
+			keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(l, data)))
+		}
+		return s.withApartmentName("Qual anúncio do Airbnb corresponde a este imóvel?"), keyboard
+	}
+
+	s.listing = listings[0]
+	s.step = stepGetAirbnbReceiver
+	return s.withApartmentName("Quem recebe os repasses do Airbnb?"), assembleKeyboardMenuWithPayers(s.store)
+}
+
+func (s *airbnbImportSession) next(answer string) (string, interface{}) {
+	if !s.apartmentSelected() {
+		replyText, markup, failed := s.selectApartment(answer)
+		if failed {
+			s.step = stepEnd
+		}
+		if !s.apartmentSelected() {
+			return replyText, markup
+		}
+	}
+
+	switch s.step {
+	case stepBeginAirbnbImport:
+		s.step = stepGetAirbnbCsv
+		return "Envie o CSV do histórico de transações do Airbnb", nil
+	case stepGetAirbnbCsv:
+		return "Envie o CSV do histórico de transações do Airbnb como arquivo", nil
+	case stepGetAirbnbListing:
+		listings := importer.Listings(s.reservations)
+		parts, ok := format.ParseCallbackData(answer, listingCallbackPrefix, 1)
+		if !ok {
+			return "Anúncio nao encontrado no arquivo, selecione um dos anúncios", nil
+		}
+		i, err := strconv.Atoi(parts[0])
+		if err != nil || i < 0 || i >= len(listings) {
+			return "Anúncio nao encontrado no arquivo, selecione um dos anúncios", nil
+		}
+		s.listing = listings[i]
+		s.step = stepGetAirbnbReceiver
+		return "Quem recebe os repasses do Airbnb?", assembleKeyboardMenuWithPayers(s.store)
+	case stepGetAirbnbReceiver:
+		return s.previewImport(answer)
+	case stepConfirmAirbnbImport:
+		s.step = stepEnd
+		if answer != confirmAnswer {
+			return "Importaçao cancelada", nil
+		}
+		if err := s.store.AddBatch(s.batch); err != nil {
+			return fmt.Sprintf("Falha ao importar as reservas - %v", err.Error()), nil
+		}
+		return fmt.Sprintf("%d aluguel(is) e %d taxa(s) de serviço importados!", len(s.batch.Rents), len(s.batch.MiscellaneousExpenses)), nil
+	}
+	return "", nil
+}
+
+func (s *airbnbImportSession) previewImport(receiver string) (string, interface{}) {
+	apartment := models.Apartment{Name: s.apartmentName}
+	rents, err := s.store.GetExistingRents(apartment)
+	if err != nil {
+		s.step = stepEnd
+		return fmt.Sprintf("Falha ao consultar os aluguéis - %v", err.Error()), nil
+	}
+
+	s.preview = importer.PreviewAirbnbImport(s.reservations, s.listing, rents)
+	s.batch = s.preview.Batch(apartment, receiver)
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf("Prévia da importaçao de %q:", s.listing))
+	for _, r := range s.preview.New {
+		lines = append(lines, "✅ "+r.ToString())
+	}
+	for _, r := range s.preview.Duplicated {
+		lines = append(lines, "⏭️ já registrado: "+r.ToString())
+	}
+	for _, r := range s.preview.Conflicts {
+		lines = append(lines, "⚠️ conflita com outro aluguel: "+r.ToString())
+	}
+
+	if len(s.preview.New) == 0 {
+		s.step = stepEnd
+		lines = append(lines, "Nenhuma reserva nova para importar")
+		return strings.Join(lines, "\n"), nil
+	}
+
+	s.step = stepConfirmAirbnbImport
+	lines = append(lines, fmt.Sprintf("Importar %d reserva(s)?", len(s.preview.New)))
+	return strings.Join(lines, "\n"), tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(confirmAnswer, confirmAnswer),
+			tgbotapi.NewInlineKeyboardButtonData(cancelAnswer, cancelAnswer),
+		),
+	)
+}
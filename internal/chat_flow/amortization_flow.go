@@ -2,7 +2,11 @@ package chat_flow
 
 import (
 	"fmt"
+	"strings"
 
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/financing"
 	"github.com/gustavolopess/hoteleiro/internal/models"
 )
 
@@ -11,9 +15,9 @@ func (f *flow[T]) amortizationFlow(answer string) (string, interface{}) {
 	// Amortization flow
 	case stepBeginAmortization:
 		f.step = stepGetValueAmortization
-		return "Qual foi o valor amortizado?", nil
+		return f.askValue("Qual foi o valor amortizado?", models.RecordAmortization)
 	case stepGetValueAmortization:
-		v, err := parsePriceFromStr(answer)
+		v, err := f.parseValue(answer)
 		if err != nil {
 			return err.Error(), nil
 		}
@@ -28,17 +32,71 @@ func (f *flow[T]) amortizationFlow(answer string) (string, interface{}) {
 		if err != nil {
 			return err.Error(), nil
 		}
-		f.value.(*models.Amortization).Date = t
+		a := f.value.(*models.Amortization)
+		a.Date = t
+		simulation, markup, err := f.simulateAmortization(a)
+		if err != nil {
+			return fmt.Sprintf("Falha ao simular a amortizaçao - %v", err.Error()), nil
+		}
+		if markup != nil {
+			f.step = stepGetOptionAmortization
+			return simulation, markup
+		}
 		f.step = stepGetPayerAmortization
-		return "Quem fez essa amortizaçao?", f.assembleKeyboardMenuWithPayers()
+		return f.askPayer("Quem fez essa amortizaçao?")
+	case stepGetOptionAmortization:
+		option, ok := models.ParseAmortizationOption(answer)
+		if !ok {
+			return "Selecione uma das opçoes", nil
+		}
+		f.value.(*models.Amortization).Option = option
+		f.step = stepGetPayerAmortization
+		return f.askPayer("Quem fez essa amortizaçao?")
 	case stepGetPayerAmortization:
-		f.value.(*models.Amortization).Payer = answer
-		err := f.store.AddAmortization(f.value.(*models.Amortization))
+		a := f.value.(*models.Amortization)
+		a.Payer = answer
+		err := f.store.AddAmortization(a)
 		if err != nil {
-			return fmt.Sprintf("Falha ao adicionar amortizaçao %v - %v", f.value.(*models.Amortization).ToString(), err.Error()), nil
+			return fmt.Sprintf("Falha ao adicionar amortizaçao %v - %v", a.ToString(), err.Error()), nil
 		}
-		f.step = stepEnd
-		return fmt.Sprintf("Amortizaçao registrada: %v", f.value.(*models.Amortization).ToString()), nil
+		f.rememberDefault(models.RecordAmortization, a.Value, a.Payer)
+		return f.askAttachment(fmt.Sprintf("Amortizaçao registrada: %v", a.ToString()), models.RecordAmortization, a.Date, a.Value)
 	}
 	return "", nil
 }
+
+// simulateAmortization compares reducing the term to reducing the installments of the financing of the apartment,
+// without a markup when the apartment has no financing contract
+func (f *flow[T]) simulateAmortization(a *models.Amortization) (string, interface{}, error) {
+	contract, err := financing.FindContract(f.store, a.Apartment.Name)
+	if err != nil || contract == nil {
+		return "", nil, err
+	}
+	extras, err := financing.StoredExtras(f.store, contract)
+	if err != nil {
+		return "", nil, err
+	}
+
+	corrections, err := financing.StoredCorrections(f.store, contract)
+	if err != nil {
+		return "", nil, err
+	}
+
+	current, simulations := financing.Simulate(contract, corrections, extras, a.Value, a.Date)
+	lines := []string{fmt.Sprintf("Hoje o financiamento termina em %v, com a próxima parcela de R$%.2f",
+		current.End.Format("01/2006"), current.Payment)}
+	var row []tgbotapi.InlineKeyboardButton
+	for _, s := range simulations {
+		if s.Option == models.ReduceTerm {
+			lines = append(lines, fmt.Sprintf("Reduzindo o prazo: termina em %v (%d parcelas a menos) e economiza R$%.2f de juros",
+				s.End.Format("01/2006"), current.RemainingTerm-s.RemainingTerm, s.InterestSaved))
+		} else {
+			lines = append(lines, fmt.Sprintf("Reduzindo a parcela: a próxima passa a R$%.2f e economiza R$%.2f de juros",
+				s.Payment, s.InterestSaved))
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(string(s.Option), string(s.Option)))
+	}
+	lines = append(lines, "O que essa amortizaçao deve reduzir?")
+
+	return strings.Join(lines, "\n"), tgbotapi.NewInlineKeyboardMarkup(row), nil
+}
@@ -1,6 +1,10 @@
 package chat_flow
 
-import "github.com/gustavolopess/hoteleiro/internal/models"
+import (
+	"fmt"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
 
 func (f *flow[T]) apartmentFlow(answer string) (string, interface{}) {
 	switch f.step {
@@ -15,9 +19,14 @@ func (f *flow[T]) apartmentFlow(answer string) (string, interface{}) {
 		}
 		return "Qual o endereço do imóvel?", nil
 	case stepGetAddressApartment:
-		f.value.(*models.Apartment).Address = answer
-		_ = f.store.AddApartment(f.value.(*models.Apartment))
+		a := f.value.(*models.Apartment)
+		a.Address = answer
+		if err := f.store.AddApartment(a); err != nil {
+			f.step = stepGetNameApartment
+			return fmt.Sprintf("Falha ao adicionar o imóvel %v - %v. Qual o nome do imóvel?", a.ToString(), err.Error()), nil
+		}
 		f.step = stepEnd
+		return fmt.Sprintf("Imóvel adicionado: %v", a.ToString()), nil
 	}
 	return "", nil
 }
@@ -0,0 +1,162 @@
+package chat_flow
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+const (
+	editApartmentAnswer      = "Editar dados"
+	renameApartmentAnswer    = "Renomear"
+	archiveApartmentAnswer   = "Arquivar"
+	unarchiveApartmentAnswer = "Reativar"
+)
+
+type apartmentManagementSession struct {
+	apartmentSelector
+	store     storage.Store
+	step      Step
+	apartment *models.Apartment
+}
+
+// NewApartmentManagementSession edits the registry data of an apartment, renames or archives it
+func NewApartmentManagementSession(store storage.Store) ChatSession {
+	selector := newApartmentSelector(store)
+	selector.includeArchived = true
+	return &apartmentManagementSession{
+		apartmentSelector: selector,
+		store:             store,
+		step:              stepBeginApartmentManagement,
+	}
+}
+
+func (s *apartmentManagementSession) Next(answer string) (string, interface{}) {
+	replyText, markup := s.next(answer)
+	return s.withApartmentName(replyText), markup
+}
+
+// Ended tells the conversation is over
+func (s *apartmentManagementSession) Ended() bool {
+	return s.step == stepEnd
+}
+
+func (s *apartmentManagementSession) next(answer string) (string, interface{}) {
+	if !s.apartmentSelected() {
+		replyText, markup, failed := s.selectApartment(answer)
+		if failed {
+			s.step = stepEnd
+		}
+		if !s.apartmentSelected() {
+			return replyText, markup
+		}
+	}
+
+	skip := answer == skipAnswer
+	switch s.step {
+	case stepBeginApartmentManagement:
+		apartment, err := s.findApartment()
+		if err != nil {
+			s.step = stepEnd
+			return fmt.Sprintf("Falha ao consultar o imóvel - %v", err.Error()), nil
+		}
+		s.apartment = apartment
+
+		archiveAnswer := archiveApartmentAnswer
+		if apartment.Archived {
+			archiveAnswer = unarchiveApartmentAnswer
+		}
+		s.step = stepGetApartmentAction
+		return apartment.Details() + "\nO que deseja fazer?", tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(editApartmentAnswer, editApartmentAnswer),
+				tgbotapi.NewInlineKeyboardButtonData(renameApartmentAnswer, renameApartmentAnswer),
+				tgbotapi.NewInlineKeyboardButtonData(archiveAnswer, archiveAnswer),
+			),
+		)
+	case stepGetApartmentAction:
+		switch answer {
+		case editApartmentAnswer:
+			s.step = stepGetApartmentAddress
+			return "Qual o endereço do imóvel?", skipKeyboard()
+		case renameApartmentAnswer:
+			s.step = stepGetApartmentNewName
+			return "Qual o novo nome do imóvel?", nil
+		case archiveApartmentAnswer, unarchiveApartmentAnswer:
+			s.apartment.Archived = answer == archiveApartmentAnswer
+			return s.save()
+		}
+		return "Selecione uma das opçoes", nil
+	case stepGetApartmentNewName:
+		newName := strings.TrimSpace(answer)
+		if err := s.store.RenameApartment(s.apartment.Name, newName); err != nil {
+			return fmt.Sprintf("Falha ao renomear o imóvel - %v. Qual o novo nome do imóvel?", err.Error()), nil
+		}
+		s.step = stepEnd
+		s.apartmentName = newName
+		return "Imóvel renomeado!", nil
+	case stepGetApartmentAddress:
+		if !skip {
+			s.apartment.Address = answer
+		}
+		s.step = stepGetApartmentAcquisitionDate
+		return "Qual a data de aquisiçao do imóvel? informe no formato dd/mm/aaaa", skipKeyboard()
+	case stepGetApartmentAcquisitionDate:
+		if !skip {
+			t, err := parseDateFromFullDate(answer)
+			if err != nil {
+				return err.Error(), nil
+			}
+			s.apartment.AcquisitionDate = t
+		}
+		s.step = stepGetApartmentPurchasePrice
+		return "Qual o preço de compra do imóvel?", skipKeyboard()
+	case stepGetApartmentPurchasePrice:
+		if !skip {
+			value, err := parsePriceFromStr(answer)
+			if err != nil {
+				return err.Error(), nil
+			}
+			s.apartment.PurchasePrice = value
+		}
+		s.step = stepGetApartmentFinancingBank
+		return "Qual o banco do financiamento?", skipKeyboard()
+	case stepGetApartmentFinancingBank:
+		if !skip {
+			s.apartment.FinancingBank = answer
+		}
+		s.step = stepGetApartmentListingURLs
+		return "Quais os links dos anúncios (Airbnb, Booking, etc.)? separe-os por espaço", skipKeyboard()
+	case stepGetApartmentListingURLs:
+		if !skip {
+			s.apartment.ListingURLs = strings.Fields(answer)
+		}
+		return s.save()
+	}
+	return "", nil
+}
+
+func (s *apartmentManagementSession) save() (string, interface{}) {
+	if err := s.store.UpdateApartment(s.apartment); err != nil {
+		return fmt.Sprintf("Falha ao salvar o imóvel - %v", err.Error()), nil
+	}
+	s.step = stepEnd
+	return "Imóvel salvo!\n" + s.apartment.Details(), nil
+}
+
+func (s *apartmentManagementSession) findApartment() (*models.Apartment, error) {
+	apartments, err := s.store.GetApartments()
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range apartments {
+		if a.Name == s.apartmentName {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("imóvel %v nao encontrado", s.apartmentName)
+}
@@ -0,0 +1,148 @@
+package chat_flow
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// apartmentColumns is how many apartments are shown side by side in the selection keyboard
+const apartmentColumns = 3
+
+// apartmentSelector asks which apartment the conversation is about, it is shared by every session that acts on a single apartment
+type apartmentSelector struct {
+	store storage.Store
+	// includeArchived offers archived apartments too, which are hidden by default
+	includeArchived bool
+	// userId is who is talking, whose last apartment is offered on top to be repeated
+	userId int64
+	// lastApartment is the apartment the user selected last, read along with the apartments
+	lastApartment string
+	apartmentName string
+	keyboard      *paginatedKeyboard
+	// apartments is read once per conversation, the keyboard and the command argument are matched against it
+	apartments []*models.Apartment
+}
+
+func newApartmentSelector(store storage.Store) apartmentSelector {
+	return apartmentSelector{
+		store: store,
+	}
+}
+
+// SetUser tells who is talking, so their last apartment is offered first
+func (s *apartmentSelector) SetUser(userId int64) {
+	s.userId = userId
+}
+
+// apartmentSelected reports whether the apartment of the conversation is already known
+func (s *apartmentSelector) apartmentSelected() bool {
+	return len(s.apartmentName) > 0
+}
+
+// selectApartment returns the question to be sent until a valid apartment is answered, after that it returns an empty reply.
+// When the apartments can't be read it reports the failure, whose reply ends the conversation
+func (s *apartmentSelector) selectApartment(answer string) (string, interface{}, bool) {
+	if s.keyboard == nil {
+		apartments, err := s.loadApartments()
+		if err != nil {
+			return s.failedToLoad(err), nil, true
+		}
+		var names []string
+		for _, apt := range apartments {
+			if s.includeArchived || !apt.Archived {
+				names = append(names, apt.Name)
+			}
+		}
+		if len(names) == 1 {
+			s.apartmentName = names[0]
+			return "", nil, false
+		}
+		s.keyboard = newPaginatedKeyboard(newKeyboardOptions(names), apartmentColumns)
+		s.keyboard.pin(s.lastApartment, fmt.Sprintf("%s (%s)", sameAsLastAnswer, s.lastApartment))
+		return "Selecione o apartamento", s.keyboard.markup(), false
+	}
+
+	if consumed, matched := s.keyboard.navigate(answer); consumed {
+		if !matched {
+			return fmt.Sprintf("Nenhum imóvel encontrado com \"%s\". De qual imóvel estamos falando?", answer), s.keyboard.markup(), false
+		}
+		option, ok := s.keyboard.only()
+		if !ok {
+			return "Selecione o apartamento", s.keyboard.markup(), false
+		}
+		answer = option.data
+	}
+	s.apartmentName = answer
+	s.rememberApartment()
+
+	return "", nil, false
+}
+
+// preselectApartment selects the apartment named along with the command, as in /grafico Centro, when it exists. Like
+// selectApartment, it reports the failure to read the apartments along with its reply
+func (s *apartmentSelector) preselectApartment(name string) (string, bool) {
+	if len(name) == 0 {
+		return "", false
+	}
+	apartments, err := s.loadApartments()
+	if err != nil {
+		return s.failedToLoad(err), true
+	}
+	for _, apt := range apartments {
+		if strings.EqualFold(apt.Name, name) && (s.includeArchived || !apt.Archived) {
+			s.apartmentName = apt.Name
+			s.rememberApartment()
+		}
+	}
+	return "", false
+}
+
+// failedToLoad logs why the apartments couldn't be read and returns the reply to the chat
+func (s *apartmentSelector) failedToLoad(err error) string {
+	log.Printf("error while getting available apartments: %v", err.Error())
+	return "Ocorreu um erro inesperado, tente novamente :("
+}
+
+// loadApartments reads the apartments on the first call of the conversation and reuses them afterwards
+func (s *apartmentSelector) loadApartments() ([]*models.Apartment, error) {
+	if s.apartments == nil {
+		apartments, err := s.store.GetApartments()
+		if err != nil {
+			return nil, err
+		}
+		s.apartments = apartments
+
+		preference, err := s.store.GetUserPreference(s.userId)
+		if err != nil {
+			log.Printf("error while reading the preference of user %d: %v", s.userId, err.Error())
+		} else {
+			s.lastApartment = preference.Apartment
+		}
+	}
+	return s.apartments, nil
+}
+
+// rememberApartment saves the selected apartment as the preference of the user, a failure only loses the suggestion
+func (s *apartmentSelector) rememberApartment() {
+	if s.userId == 0 || s.apartmentName == s.lastApartment {
+		return
+	}
+	err := s.store.SetUserPreference(&models.UserPreference{UserId: s.userId, Apartment: s.apartmentName})
+	if err != nil {
+		log.Printf("error while saving the preference of user %d: %v", s.userId, err.Error())
+		return
+	}
+	s.lastApartment = s.apartmentName
+}
+
+// withApartmentName prefixes the reply with the apartment of the conversation
+func (s *apartmentSelector) withApartmentName(replyText string) string {
+	if len(replyText) > 0 && len(s.apartmentName) > 0 {
+		return fmt.Sprintf("[%s] %s", s.apartmentName, replyText)
+	}
+	return replyText
+}
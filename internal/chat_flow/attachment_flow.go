@@ -0,0 +1,75 @@
+package chat_flow
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+var attachmentExtensions = []string{".jpg", ".jpeg", ".png", ".pdf"}
+
+// askAttachment ends the flow of an expense offering to attach its receipt
+func (f *flow[T]) askAttachment(replyText string, recordType models.RecordType, date time.Time, value float64) (string, interface{}) {
+	f.attachment = &models.Attachment{
+		RecordType: recordType,
+		Date:       date,
+		Value:      value,
+		Apartment:  models.Apartment{Name: f.apartmentName},
+	}
+	f.step = stepGetAttachment
+
+	return replyText + "\nSe quiser anexar o comprovante, envie uma foto ou PDF", skipKeyboard()
+}
+
+func (f *flow[T]) attachmentFlow(answer string) (string, interface{}) {
+	if answer == skipAnswer {
+		f.step = stepEnd
+		return "Ok, sem comprovante", nil
+	}
+	return "Envie uma foto ou PDF do comprovante, ou toque em Pular", nil
+}
+
+func (f *flow[T]) ReceiveDocument(name string, data []byte) (string, interface{}) {
+	if f.step != stepGetAttachment {
+		return f.withApartmentName("Nao estou esperando um arquivo agora"), nil
+	}
+
+	ext := strings.ToLower(filepath.Ext(name))
+	if !isAttachmentExtension(ext) {
+		return f.withApartmentName("Envie o comprovante como foto ou PDF"), nil
+	}
+
+	f.attachment.Key = attachmentKey(f.attachment, ext)
+	if err := f.blobs.Put(f.attachment.Key, data); err != nil {
+		log.Printf("error while storing attachment %s: %v", f.attachment.Key, err.Error())
+		return f.withApartmentName(fmt.Sprintf("Falha ao guardar o comprovante - %v", err.Error())), nil
+	}
+	if err := f.store.AddAttachment(f.attachment); err != nil {
+		return f.withApartmentName(fmt.Sprintf("Falha ao registrar o comprovante - %v", err.Error())), nil
+	}
+
+	f.step = stepEnd
+	return f.withApartmentName(fmt.Sprintf("Comprovante anexado: %v", f.attachment.ToString())), nil
+}
+
+func isAttachmentExtension(ext string) bool {
+	for _, e := range attachmentExtensions {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// attachmentKey names the blob after its record, with a random suffix as a record may have more than one receipt
+func attachmentKey(a *models.Attachment, ext string) string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s/%s/%s-%s%s", a.Apartment.Name, a.RecordType, a.Date.Format("2006-01-02"), hex.EncodeToString(suffix), ext)
+}
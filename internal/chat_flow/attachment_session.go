@@ -0,0 +1,104 @@
+package chat_flow
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+	"github.com/gustavolopess/hoteleiro/internal/storage/blob"
+)
+
+type attachmentSession struct {
+	apartmentSelector
+	store       storage.Store
+	blobs       blob.Store
+	step        Step
+	attachments []*models.Attachment
+	keyboard    *paginatedKeyboard
+}
+
+// NewAttachmentSession sends back to the chat the receipt attached to a record
+func NewAttachmentSession(store storage.Store, blobs blob.Store) ChatSession {
+	return &attachmentSession{
+		apartmentSelector: newApartmentSelector(store),
+		store:             store,
+		blobs:             blobs,
+		step:              stepBeginAttachmentFetch,
+	}
+}
+
+func (s *attachmentSession) Next(answer string) (string, interface{}) {
+	replyText, markup := s.next(answer)
+	return s.withApartmentName(replyText), markup
+}
+
+// Ended tells the conversation is over
+func (s *attachmentSession) Ended() bool {
+	return s.step == stepEnd
+}
+
+func (s *attachmentSession) next(answer string) (string, interface{}) {
+	if !s.apartmentSelected() {
+		replyText, markup, failed := s.selectApartment(answer)
+		if failed {
+			s.step = stepEnd
+		}
+		if !s.apartmentSelected() {
+			return replyText, markup
+		}
+	}
+
+	switch s.step {
+	case stepBeginAttachmentFetch:
+		attachments, err := s.store.GetAttachments(models.Apartment{Name: s.apartmentName})
+		if err != nil {
+			s.step = stepEnd
+			return fmt.Sprintf("Falha ao consultar os comprovantes - %v", err.Error()), nil
+		}
+		if len(attachments) == 0 {
+			s.step = stepEnd
+			return "Nenhum comprovante anexado", nil
+		}
+
+		sort.SliceStable(attachments, func(i, j int) bool {
+			return attachments[i].Date.After(attachments[j].Date)
+		})
+		s.attachments = attachments
+
+		options := make([]keyboardOption, 0, len(attachments))
+		for i, a := range attachments {
+			options = append(options, keyboardOption{label: a.ToString(), data: strconv.Itoa(i)})
+		}
+		s.keyboard = newPaginatedKeyboard(options, 1)
+		s.step = stepGetAttachmentToFetch
+		return "De qual registro você quer o comprovante?", s.keyboard.markup()
+	case stepGetAttachmentToFetch:
+		if consumed, matched := s.keyboard.navigate(answer); consumed {
+			option, ok := s.keyboard.only()
+			if !ok {
+				if !matched {
+					return fmt.Sprintf("Nenhum registro encontrado com \"%s\"", answer), s.keyboard.markup()
+				}
+				return "De qual registro você quer o comprovante?", s.keyboard.markup()
+			}
+			answer = option.data
+		}
+		i, err := strconv.Atoi(answer)
+		if err != nil || i < 0 || i >= len(s.attachments) {
+			return "Selecione um dos registros da lista", nil
+		}
+		a := s.attachments[i]
+		data, err := s.blobs.Get(a.Key)
+		if err != nil {
+			log.Printf("error while reading attachment %s: %v", a.Key, err.Error())
+			return fmt.Sprintf("Falha ao buscar o comprovante - %v", err.Error()), nil
+		}
+		s.step = stepEnd
+		return fmt.Sprintf("Comprovante: %v", a.ToString()), Document{Name: path.Base(a.Key), Data: data}
+	}
+	return "", nil
+}
@@ -0,0 +1,120 @@
+package chat_flow
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gustavolopess/hoteleiro/internal/importer"
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// maxReportLines limits each section of a report so the message fits in Telegram
+const maxReportLines = 20
+
+type bankStatementSession struct {
+	store storage.Store
+	step  Step
+}
+
+// NewBankStatementSession reconciles a bank statement (OFX or CSV) against the registered expenses
+func NewBankStatementSession(store storage.Store) ChatSession {
+	return &bankStatementSession{
+		store: store,
+		step:  stepBeginBankStatementImport,
+	}
+}
+
+func (s *bankStatementSession) Next(answer string) (string, interface{}) {
+	switch s.step {
+	case stepBeginBankStatementImport:
+		s.step = stepGetBankStatement
+		return "Envie o extrato bancário em OFX ou CSV", nil
+	case stepGetBankStatement:
+		return "Envie o extrato bancário como arquivo", nil
+	}
+	return "", nil
+}
+
+// Ended tells the conversation is over
+func (s *bankStatementSession) Ended() bool {
+	return s.step == stepEnd
+}
+
+func (s *bankStatementSession) ReceiveDocument(name string, data []byte) (string, interface{}) {
+	if s.step != stepGetBankStatement {
+		return "Nao estou esperando um arquivo agora", nil
+	}
+
+	lines, err := importer.ParseBankStatement(data)
+	if err != nil {
+		return fmt.Sprintf("Falha ao ler o extrato %s - %v", name, err.Error()), nil
+	}
+
+	rules, err := s.store.GetStatementRules()
+	if err != nil {
+		log.Printf("error while getting statement rules: %v", err.Error())
+		return "Falha ao ler as regras do extrato, confira a aba \"[Regras extrato]\" da planilha (colunas: texto do extrato, tipo e imóvel)", nil
+	}
+	if len(rules) == 0 {
+		return "Nenhuma regra cadastrada na aba \"[Regras extrato]\" da planilha (colunas: texto do extrato, tipo e imóvel)", nil
+	}
+
+	var records []*importer.Record
+	loaded := make(map[string]bool)
+	for _, r := range rules {
+		if loaded[r.Apartment.Name] {
+			continue
+		}
+		loaded[r.Apartment.Name] = true
+		apartmentRecords, err := importer.LoadRecords(s.store, models.Apartment{Name: r.Apartment.Name})
+		if err != nil {
+			return fmt.Sprintf("Falha ao consultar as despesas de %v - %v", r.Apartment.Name, err.Error()), nil
+		}
+		records = append(records, apartmentRecords...)
+	}
+
+	s.step = stepEnd
+	return reconciliationReport(importer.Reconcile(lines, rules, records)), nil
+}
+
+func reconciliationReport(rec *importer.Reconciliation) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "✅ %d lançamento(s) conciliado(s)\n", len(rec.Matched))
+
+	var unregistered []string
+	for _, m := range rec.Unregistered {
+		unregistered = append(unregistered, fmt.Sprintf("%v → %v de %v", m.Line.ToString(), m.Rule.RecordType, m.Rule.Apartment.Name))
+	}
+	writeReportSection(&b, "⚠️ Lançamentos do extrato sem registro:", unregistered)
+
+	var unpaid []string
+	for _, r := range rec.Unpaid {
+		unpaid = append(unpaid, r.ToString())
+	}
+	writeReportSection(&b, "⚠️ Registros sem lançamento no extrato:", unpaid)
+
+	var unknown []string
+	for _, l := range rec.Unknown {
+		unknown = append(unknown, l.ToString())
+	}
+	writeReportSection(&b, "❓ Débitos sem regra:", unknown)
+
+	return strings.TrimSpace(b.String())
+}
+
+func writeReportSection(b *strings.Builder, title string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n%s\n", title)
+	for i, l := range lines {
+		if i == maxReportLines {
+			fmt.Fprintf(b, "... e mais %d\n", len(lines)-maxReportLines)
+			break
+		}
+		fmt.Fprintf(b, "- %s\n", l)
+	}
+}
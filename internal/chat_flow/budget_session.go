@@ -0,0 +1,187 @@
+package chat_flow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/report"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+const (
+	removeBudgetAnswer = "Remover orçamento"
+	keepBudgetsAnswer  = "Nenhum"
+)
+
+type budgetSession struct {
+	apartmentSelector
+	chatId  int64
+	store   storage.Store
+	step    Step
+	period  *report.Period
+	budgets []*models.Budget
+	budget  *models.Budget
+}
+
+// NewBudgetSession compares the monthly budgets of an apartment to what was spent in the current month, or in the
+// month or year given as in /orcamento 2024, and changes them. Alerts of the budgets are sent to the chat of the
+// session
+func NewBudgetSession(chatId int64, store storage.Store) ChatSession {
+	return &budgetSession{
+		apartmentSelector: newApartmentSelector(store),
+		chatId:            chatId,
+		store:             store,
+		step:              stepBeginBudget,
+	}
+}
+
+func (s *budgetSession) Next(answer string) (string, interface{}) {
+	if s.period == nil {
+		now := time.Now()
+		period := strings.TrimSpace(answer)
+		if len(period) == 0 {
+			period = now.Format("01/2006")
+		}
+		var err error
+		if s.period, err = report.ParsePeriod(period, now); err != nil {
+			s.step = stepEnd
+			return err.Error(), nil
+		}
+	}
+	replyText, markup := s.next(answer)
+	return s.withApartmentName(replyText), markup
+}
+
+// Ended tells the conversation is over
+func (s *budgetSession) Ended() bool {
+	return s.step == stepEnd
+}
+
+func (s *budgetSession) next(answer string) (string, interface{}) {
+	if !s.apartmentSelected() {
+		replyText, markup, failed := s.selectApartment(answer)
+		if failed {
+			s.step = stepEnd
+		}
+		if !s.apartmentSelected() {
+			return replyText, markup
+		}
+	}
+
+	switch s.step {
+	case stepBeginBudget:
+		budgets, err := s.store.GetBudgets()
+		if err != nil {
+			s.step = stepEnd
+			return fmt.Sprintf("Falha ao consultar os orçamentos - %v", err.Error()), nil
+		}
+		for _, b := range budgets {
+			if b.Apartment.Name == s.apartmentName {
+				s.budgets = append(s.budgets, b)
+			}
+		}
+		table, err := s.varianceTable()
+		if err != nil {
+			s.step = stepEnd
+			return fmt.Sprintf("Falha ao consultar as despesas - %v", err.Error()), nil
+		}
+
+		var row []tgbotapi.InlineKeyboardButton
+		for _, t := range models.BudgetTypes {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(string(t), string(t)))
+		}
+		s.step = stepGetBudgetType
+		return table + "\nDeseja alterar o orçamento de qual despesa?", tgbotapi.NewInlineKeyboardMarkup(row,
+			tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(keepBudgetsAnswer, keepBudgetsAnswer)))
+	case stepGetBudgetType:
+		if answer == keepBudgetsAnswer {
+			s.step = stepEnd
+			return "Orçamentos mantidos", nil
+		}
+		t, ok := models.ParseRecordType(answer)
+		if !ok || !models.IsBudgetType(t) {
+			return "Selecione um dos tipos de despesa", nil
+		}
+		s.budget = &models.Budget{Apartment: models.Apartment{Name: s.apartmentName}, Type: t, ChatId: s.chatId}
+		s.step = stepGetBudgetValue
+		if s.findBudget(t) != nil {
+			return "Qual o orçamento mensal?", tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(removeBudgetAnswer, removeBudgetAnswer),
+			))
+		}
+		return "Qual o orçamento mensal?", nil
+	case stepGetBudgetValue:
+		if answer == removeBudgetAnswer {
+			s.step = stepEnd
+			if err := s.store.RemoveBudget(s.budget); err != nil {
+				return fmt.Sprintf("Falha ao remover o orçamento - %v", err.Error()), nil
+			}
+			return "Orçamento removido", nil
+		}
+		value, err := parsePriceFromStr(answer)
+		if err != nil {
+			return err.Error(), nil
+		}
+		s.budget.Value = value
+		s.step = stepEnd
+		if err := s.store.SetBudget(s.budget); err != nil {
+			return fmt.Sprintf("Falha ao salvar o orçamento - %v", err.Error()), nil
+		}
+		return fmt.Sprintf("Orçamento salvo: %v. Os alertas de 80%% e 100%% serao enviados neste chat", s.budget.ToString()), nil
+	}
+	return "", nil
+}
+
+func (s *budgetSession) findBudget(t models.RecordType) *models.Budget {
+	for _, b := range s.budgets {
+		if b.Type == t {
+			return b
+		}
+	}
+	return nil
+}
+
+// varianceTable lists what was spent on each type of expense against its budget, month by month of the period
+func (s *budgetSession) varianceTable() (string, error) {
+	apartment := models.Apartment{Name: s.apartmentName}
+	spent := make(map[models.RecordType]map[time.Time]float64)
+	for _, t := range models.BudgetTypes {
+		byMonth, err := storage.SpentByMonth(s.store, apartment, t)
+		if err != nil {
+			return "", err
+		}
+		spent[t] = byMonth
+	}
+
+	lines := []string{fmt.Sprintf("Orçado x realizado - %v", s.period.Label)}
+	for month := s.period.Begin; month.Before(s.period.End); month = month.AddDate(0, 1, 0) {
+		lines = append(lines, month.Format("01/2006"))
+		var totalSpent, totalBudget float64
+		for _, t := range models.BudgetTypes {
+			value := spent[t][month]
+			totalSpent += value
+			b := s.findBudget(t)
+			if b == nil {
+				lines = append(lines, fmt.Sprintf("- %v: R$%.2f, sem orçamento", t, value))
+				continue
+			}
+			totalBudget += b.Value
+			lines = append(lines, fmt.Sprintf("- %v: R$%.2f de R$%.2f (%.0f%%), %v", t, value, b.Value, value/b.Value*100, variance(b.Value-value)))
+		}
+		if totalBudget > 0 {
+			lines = append(lines, fmt.Sprintf("- total: R$%.2f de R$%.2f orçados", totalSpent, totalBudget))
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func variance(left float64) string {
+	if left < 0 {
+		return fmt.Sprintf("R$%.2f acima", -left)
+	}
+	return fmt.Sprintf("restam R$%.2f", left)
+}
@@ -0,0 +1,80 @@
+package chat_flow
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/gustavolopess/hoteleiro/internal/calendar"
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+type calendarExportSession struct {
+	apartmentSelector
+	store storage.Store
+	feed  *calendar.Feed
+	done  bool
+}
+
+// NewCalendarExportSession sends the iCalendar file of an apartment along with the address of its feed, the feed
+// being nil when it is disabled
+func NewCalendarExportSession(store storage.Store, feed *calendar.Feed) ChatSession {
+	return &calendarExportSession{
+		apartmentSelector: newApartmentSelector(store),
+		store:             store,
+		feed:              feed,
+	}
+}
+
+func (s *calendarExportSession) Next(answer string) (string, interface{}) {
+	if s.done {
+		return "", nil
+	}
+
+	if !s.apartmentSelected() {
+		replyText, markup, failed := s.selectApartment(answer)
+		if failed {
+			s.done = true
+		}
+		if !s.apartmentSelected() {
+			return replyText, markup
+		}
+	}
+
+	s.done = true
+	apartment, err := s.findApartment()
+	if err != nil {
+		log.Printf("error while exporting calendar of %s: %v", s.apartmentName, err.Error())
+		return fmt.Sprintf("Falha ao exportar o calendário - %v", err.Error()), nil
+	}
+	ics, err := calendar.Calendar(s.store, *apartment)
+	if err != nil {
+		log.Printf("error while exporting calendar of %s: %v", s.apartmentName, err.Error())
+		return fmt.Sprintf("Falha ao exportar o calendário - %v", err.Error()), nil
+	}
+
+	caption := fmt.Sprintf("Calendário de %s", s.apartmentName)
+	if s.feed != nil {
+		caption += fmt.Sprintf(". Para sincronizar com Airbnb e Booking use o endereço: %s", s.feed.URL(*apartment))
+	}
+	return caption, Document{Name: s.apartmentName + ".ics", Data: ics}
+}
+
+// Ended tells the conversation is over
+func (s *calendarExportSession) Ended() bool {
+	return s.done
+}
+
+// findApartment returns the selected apartment with its id, which identifies its feed and events
+func (s *calendarExportSession) findApartment() (*models.Apartment, error) {
+	apartments, err := s.store.GetApartments()
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range apartments {
+		if a.Name == s.apartmentName {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("imóvel %v nao encontrado", s.apartmentName)
+}
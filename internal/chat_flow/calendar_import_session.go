@@ -0,0 +1,173 @@
+package chat_flow
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/calendar"
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+const skipAnswer = "Pular"
+
+type calendarImportSession struct {
+	apartmentSelector
+	store   storage.Store
+	step    Step
+	missing []*calendar.Event
+	rent    *models.Rent
+}
+
+// NewCalendarImportSession reads the iCalendar of an OTA and offers to register the reservations that have no rent yet
+func NewCalendarImportSession(store storage.Store) ChatSession {
+	return &calendarImportSession{
+		apartmentSelector: newApartmentSelector(store),
+		store:             store,
+		step:              stepBeginCalendarImport,
+	}
+}
+
+func (s *calendarImportSession) Next(answer string) (string, interface{}) {
+	replyText, markup := s.next(answer)
+	return s.withApartmentName(replyText), markup
+}
+
+// Ended tells the conversation is over
+func (s *calendarImportSession) Ended() bool {
+	return s.step == stepEnd
+}
+
+func (s *calendarImportSession) ReceiveDocument(name string, data []byte) (string, interface{}) {
+	if s.step != stepGetCalendarSource {
+		return "Nao estou esperando um arquivo agora", nil
+	}
+
+	cal, err := calendar.Parse(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Sprintf("Falha ao ler o calendário %s - %v", name, err.Error()), nil
+	}
+
+	replyText, markup := s.diff(cal)
+	return s.withApartmentName(replyText), markup
+}
+
+func (s *calendarImportSession) next(answer string) (string, interface{}) {
+	if !s.apartmentSelected() {
+		replyText, markup, failed := s.selectApartment(answer)
+		if failed {
+			s.step = stepEnd
+		}
+		if !s.apartmentSelected() {
+			return replyText, markup
+		}
+	}
+
+	switch s.step {
+	case stepBeginCalendarImport:
+		s.step = stepGetCalendarSource
+		return "Envie o arquivo .ics ou a URL do calendário do Airbnb/Booking", nil
+	case stepGetCalendarSource:
+		url := strings.TrimSpace(answer)
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			return "Isso nao parece uma URL, envie o arquivo .ics ou a URL do calendário", nil
+		}
+		cal, err := calendar.Fetch(url)
+		if err != nil {
+			return fmt.Sprintf("Falha ao ler o calendário - %v", err.Error()), nil
+		}
+		return s.diff(cal)
+	case stepGetImportedRentValue:
+		if answer == skipAnswer {
+			return s.askNextMissing("")
+		}
+		value, err := parsePriceFromStr(answer)
+		if err != nil {
+			return err.Error(), nil
+		}
+		s.rent.Value = value
+		if len(s.rent.Renter) == 0 {
+			s.step = stepGetImportedRenter
+			return "Qual o nome do inquilino?", nil
+		}
+		s.step = stepGetImportedRentReceiver
+		return "Quem recebeu o dinheiro do aluguel?", assembleKeyboardMenuWithPayers(s.store)
+	case stepGetImportedRenter:
+		s.rent.Renter = answer
+		s.step = stepGetImportedRentReceiver
+		return "Quem recebeu o dinheiro do aluguel?", assembleKeyboardMenuWithPayers(s.store)
+	case stepGetImportedRentReceiver:
+		s.rent.Receiver = answer
+		if err := s.store.AddRent(s.rent); err != nil {
+			return s.askNextMissing(fmt.Sprintf("Falha ao adicionar o aluguel %v - %v", s.rent.ToString(), err.Error()))
+		}
+		return s.askNextMissing(fmt.Sprintf("Aluguel adicionado! %v", s.rent.ToString()))
+	}
+	return "", nil
+}
+
+// diff compares the calendar against the registered rents and the previous sync of the same source, reports the
+// divergences and starts asking for the missing rents
+func (s *calendarImportSession) diff(cal *calendar.ParsedCalendar) (string, interface{}) {
+	apartment := models.Apartment{Name: s.apartmentName}
+	rents, err := s.store.GetExistingRents(apartment)
+	if err != nil {
+		s.step = stepEnd
+		return fmt.Sprintf("Falha ao consultar os aluguéis - %v", err.Error()), nil
+	}
+
+	previous, err := s.store.GetCalendarBookings(apartment, cal.Source)
+	if err != nil {
+		s.step = stepEnd
+		return fmt.Sprintf("Falha ao consultar a última sincronizaçao - %v", err.Error()), nil
+	}
+
+	d := calendar.DiffRents(cal, previous, rents, time.Now())
+	s.missing = d.Missing
+
+	if err := s.store.SetCalendarBookings(apartment, cal.Source, cal.Bookings(apartment)); err != nil {
+		log.Printf("error while saving the sync of the calendar of %v: %v", s.apartmentName, err.Error())
+	}
+
+	var report []string
+	for _, c := range d.Cancelled {
+		report = append(report, fmt.Sprintf("⚠️ Reserva cancelada, mas o aluguel continua registrado: %v", c.Rent.ToString()))
+	}
+	for _, c := range d.Changed {
+		report = append(report, fmt.Sprintf("⚠️ Reserva alterada para %v a %v, mas o aluguel registrado é %v",
+			c.Event.DateBegin.Format("02/01/2006"), c.Event.DateEnd.Format("02/01/2006"), c.Rent.ToString()))
+	}
+	report = append(report, fmt.Sprintf("%d reserva(s) sem aluguel registrado", len(d.Missing)))
+
+	return s.askNextMissing(strings.Join(report, "\n"))
+}
+
+// askNextMissing asks the value of the next reservation to be registered, prefixing the question with the given report
+func (s *calendarImportSession) askNextMissing(report string) (string, interface{}) {
+	if len(s.missing) == 0 {
+		s.step = stepEnd
+		return strings.TrimSpace(report + "\nImportaçao concluída"), nil
+	}
+
+	e := s.missing[0]
+	s.missing = s.missing[1:]
+	s.rent = &models.Rent{
+		DateBegin: e.DateBegin,
+		DateEnd:   e.DateEnd,
+		Renter:    e.GuestName(),
+		Apartment: models.Apartment{Name: s.apartmentName},
+	}
+	s.step = stepGetImportedRentValue
+
+	guest := ""
+	if len(s.rent.Renter) > 0 {
+		guest = " de " + s.rent.Renter
+	}
+	question := fmt.Sprintf("Reserva%s do dia %v ao dia %v. Qual o valor do aluguel?", guest,
+		e.DateBegin.Format("02/01/2006"), e.DateEnd.Format("02/01/2006"))
+
+	return strings.TrimSpace(report + "\n" + question), skipKeyboard()
+}
@@ -0,0 +1,93 @@
+package chat_flow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/chart"
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/report"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// chartMonths is the period of the charts when none is given
+const chartMonths = 12
+
+type chartSession struct {
+	apartmentSelector
+	store  storage.Store
+	step   Step
+	period *report.Period
+}
+
+// NewChartSession sends the charts of the income, expenses and occupancy of an apartment, as in
+// /grafico Centro 2024. The apartment is asked when not given and the period is the last twelve months by default
+func NewChartSession(store storage.Store) ChatSession {
+	selector := newApartmentSelector(store)
+	selector.includeArchived = true
+	return &chartSession{
+		apartmentSelector: selector,
+		store:             store,
+		step:              stepBeginChart,
+	}
+}
+
+func (s *chartSession) Next(answer string) (string, interface{}) {
+	if s.period == nil {
+		name := strings.TrimSpace(answer)
+		s.period = report.LastMonths(chartMonths, time.Now())
+		if fields := strings.Fields(name); len(fields) > 0 {
+			if period, err := report.ParsePeriod(fields[len(fields)-1], time.Now()); err == nil {
+				s.period = period
+				name = strings.Join(fields[:len(fields)-1], " ")
+			}
+		}
+		if replyText, failed := s.preselectApartment(name); failed {
+			s.step = stepEnd
+			return replyText, nil
+		}
+	}
+	replyText, markup := s.next(answer)
+	return s.withApartmentName(replyText), markup
+}
+
+// Ended tells the conversation is over
+func (s *chartSession) Ended() bool {
+	return s.step == stepEnd
+}
+
+func (s *chartSession) next(answer string) (string, interface{}) {
+	if !s.apartmentSelected() {
+		replyText, markup, failed := s.selectApartment(answer)
+		if failed {
+			s.step = stepEnd
+		}
+		if !s.apartmentSelected() {
+			return replyText, markup
+		}
+	}
+
+	if s.step != stepBeginChart {
+		return "", nil
+	}
+	s.step = stepEnd
+
+	apartment := models.Apartment{Name: s.apartmentName}
+	statement, err := report.BuildStatement(s.store, apartment, s.period)
+	if err != nil {
+		return fmt.Sprintf("Falha ao consultar os registros - %v", err.Error()), nil
+	}
+	rents, err := s.store.GetExistingRents(apartment)
+	if err != nil {
+		return fmt.Sprintf("Falha ao consultar os aluguéis - %v", err.Error()), nil
+	}
+	image, err := chart.Render(chart.NewData(statement, rents, time.Now()))
+	if err != nil {
+		return fmt.Sprintf("Falha ao desenhar os gráficos - %v", err.Error()), nil
+	}
+	return fmt.Sprintf("Gráficos de %v", s.period.Label), Photo{
+		Name: fmt.Sprintf("grafico-%v.png", s.apartmentName),
+		Data: image,
+	}
+}
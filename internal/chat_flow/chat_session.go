@@ -3,24 +3,78 @@ package chat_flow
 import (
 	"github.com/gustavolopess/hoteleiro/internal/models"
 	"github.com/gustavolopess/hoteleiro/internal/storage"
+	"github.com/gustavolopess/hoteleiro/internal/storage/blob"
 )
 
 type ChatSession interface {
 	Next(string) (string, interface{})
 }
 
+// EndedSession is a session which tells when its conversation is over, so it can be forgotten
+type EndedSession interface {
+	Ended() bool
+}
+
 type chatSession[T models.Models] struct {
 	chatId   int64
 	chatFlow Flow[T]
 }
 
-func NewChatSession[T models.Models](chatId int64, store storage.Store) ChatSession {
+func NewChatSession[T models.Models](chatId int64, store storage.Store, blobs blob.Store) ChatSession {
+	return &chatSession[T]{
+		chatId:   chatId,
+		chatFlow: NewFlow[T](store, blobs),
+	}
+}
+
+// NewRecordSession starts the entry of a record of the type in the apartment, without asking for the apartment
+func NewRecordSession(chatId int64, recordType models.RecordType, apartment string, store storage.Store, blobs blob.Store) ChatSession {
+	switch recordType {
+	case models.RecordCleaning:
+		return newApartmentChatSession[models.Cleaning](chatId, apartment, store, blobs)
+	case models.RecordCondo:
+		return newApartmentChatSession[models.Condo](chatId, apartment, store, blobs)
+	case models.RecordEnergyBill:
+		return newApartmentChatSession[models.EnergyBill](chatId, apartment, store, blobs)
+	case models.RecordFinancingInstallment:
+		return newApartmentChatSession[models.FinancingInstallment](chatId, apartment, store, blobs)
+	case models.RecordMiscellaneousExpense:
+		return newApartmentChatSession[models.MiscellaneousExpense](chatId, apartment, store, blobs)
+	case models.RecordAmortization:
+		return newApartmentChatSession[models.Amortization](chatId, apartment, store, blobs)
+	}
+	return nil
+}
+
+func newApartmentChatSession[T models.Models](chatId int64, apartment string, store storage.Store, blobs blob.Store) ChatSession {
+	f := NewFlow[T](store, blobs).(*flow[T])
+	f.apartmentName = apartment
 	return &chatSession[T]{
 		chatId:   chatId,
-		chatFlow: NewFlow[T](store),
+		chatFlow: f,
 	}
 }
 
 func (s *chatSession[T]) Next(answer string) (string, interface{}) {
 	return s.chatFlow.Next(answer)
 }
+
+func (s *chatSession[T]) ReceiveDocument(name string, data []byte) (string, interface{}) {
+	if receiver, ok := s.chatFlow.(DocumentReceiver); ok {
+		return receiver.ReceiveDocument(name, data)
+	}
+	return "Nao estou esperando um arquivo agora", nil
+}
+
+func (s *chatSession[T]) Ended() bool {
+	if ended, ok := s.chatFlow.(EndedSession); ok {
+		return ended.Ended()
+	}
+	return false
+}
+
+func (s *chatSession[T]) SetUser(userId int64) {
+	if userSession, ok := s.chatFlow.(UserSession); ok {
+		userSession.SetUser(userId)
+	}
+}
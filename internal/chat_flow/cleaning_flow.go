@@ -15,9 +15,9 @@ func (f *flow[T]) cleaningFlow(answer string) (string, interface{}) {
 			},
 		}
 		f.step = stepGetValueCleaning
-		return "Qual o valor pago na faxina?", nil
+		return f.askValue("Qual o valor pago na faxina?", models.RecordCleaning)
 	case stepGetValueCleaning:
-		value, err := parsePriceFromStr(answer)
+		value, err := f.parseValue(answer)
 		if err != nil {
 			return err.Error(), nil
 		}
@@ -31,12 +31,15 @@ func (f *flow[T]) cleaningFlow(answer string) (string, interface{}) {
 		}
 		f.value.(*models.Cleaning).Date = t
 		f.step = stepGetCleaningPayer
-		return "Quem pagou pela faxina?", f.assembleKeyboardMenuWithPayers()
+		return f.askPayer("Quem pagou pela faxina?")
 	case stepGetCleaningPayer:
-		f.value.(*models.Cleaning).Payer = answer
-		f.store.AddCleaning(f.value.(*models.Cleaning))
-		f.step = stepEnd
-		return fmt.Sprintf("Faxina registrada: %v", f.value.(*models.Cleaning).ToString()), nil
+		c := f.value.(*models.Cleaning)
+		c.Payer = answer
+		if err := f.store.AddCleaning(c); err != nil {
+			return fmt.Sprintf("Falha ao registrar a faxina %v - %v", c.ToString(), err.Error()), nil
+		}
+		f.rememberDefault(models.RecordCleaning, c.Value, c.Payer)
+		return f.askAttachment(fmt.Sprintf("Faxina registrada: %v", c.ToString()), models.RecordCleaning, c.Date, c.Value)
 	}
 	return "", nil
 }
@@ -10,15 +10,16 @@ func (f *flow[T]) condoFlow(answer string) (string, interface{}) {
 	switch f.step {
 	case stepBeginCondo:
 		f.step = stepGetValueCondo
-		return "Qual o valor do condomínio?", nil
+		return f.askValue("Qual o valor do condomínio?", models.RecordCondo)
 	case stepGetValueCondo:
-		value, err := parsePriceFromStr(answer)
+		value, err := f.parseValue(answer)
 		if err != nil {
 			return err.Error(), nil
 		}
 		f.step = stepGetDateCondo
 		f.value = &models.Condo{
-			Value: value,
+			Value:     value,
+			Apartment: models.Apartment{Name: f.apartmentName},
 		}
 		return "Em que data esta taxa de condomínio foi paga? informe uma data no formato dd/mm/aaaa", nil
 	case stepGetDateCondo:
@@ -28,14 +29,15 @@ func (f *flow[T]) condoFlow(answer string) (string, interface{}) {
 		}
 		f.value.(*models.Condo).Date = t
 		f.step = stepGetPayerCondo
-		return "Quem pagou essa taxa de condomínio?", f.assembleKeyboardMenuWithPayers()
+		return f.askPayer("Quem pagou essa taxa de condomínio?")
 	case stepGetPayerCondo:
-		f.value.(*models.Condo).Payer = answer
-		if err := f.store.AddCondo(f.value.(*models.Condo)); err != nil {
+		c := f.value.(*models.Condo)
+		c.Payer = answer
+		if err := f.store.AddCondo(c); err != nil {
 			return fmt.Sprintf("Falha ao adicionar taxa de condomínio: %v", err.Error()), nil
 		}
-		f.step = stepEnd
-		return fmt.Sprintf("Taxa de condomínio registrada: %v", f.value.(*models.Condo).ToString()), nil
+		f.rememberDefault(models.RecordCondo, c.Value, c.Payer)
+		return f.askAttachment(fmt.Sprintf("Taxa de condomínio registrada: %v", c.ToString()), models.RecordCondo, c.Date, c.Value)
 	}
 	return "", nil
 }
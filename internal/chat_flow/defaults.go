@@ -0,0 +1,75 @@
+package chat_flow
+
+import (
+	"fmt"
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+// sameAsLastAnswer repeats the apartment the user selected last, or the value and payer registered last for the same
+// kind of record
+const sameAsLastAnswer = "Usar o mesmo"
+
+// UserSession is a session whose questions are adapted to the user who is talking
+type UserSession interface {
+	SetUser(userId int64)
+}
+
+// defaultLabel describes the default offered to be repeated
+func defaultLabel(d *models.RecordDefault) string {
+	return fmt.Sprintf("%s (%s, R$%.2f, %s)", sameAsLastAnswer, d.Apartment, d.Value, d.Payer)
+}
+
+// askValue asks the value of a record, offering to repeat the value and payer the user registered last for the same
+// type of record in the apartment
+func (f *flow[T]) askValue(question string, recordType models.RecordType) (string, interface{}) {
+	d, err := f.store.GetRecordDefault(f.userId, f.apartmentName, recordType)
+	if err != nil {
+		log.Printf("error while looking for the default %v of %s: %v", recordType, f.apartmentName, err.Error())
+	}
+	f.recordDefault = d
+	if d == nil {
+		return question, nil
+	}
+	return question, tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(defaultLabel(d), sameAsLastAnswer)),
+	)
+}
+
+// parseValue reads the answer to askValue, remembering if the default is being repeated
+func (f *flow[T]) parseValue(answer string) (float64, error) {
+	if answer == sameAsLastAnswer && f.recordDefault != nil {
+		f.repeatsDefault = true
+		return f.recordDefault.Value, nil
+	}
+	return parsePriceFromStr(answer)
+}
+
+// askPayer asks who paid the record, unless the default was repeated and its payer is reused
+func (f *flow[T]) askPayer(question string) (string, interface{}) {
+	if f.repeatsDefault {
+		return f.currentFlow(f.recordDefault.Payer)
+	}
+	return question, assembleKeyboardMenuWithPayers(f.store)
+}
+
+// rememberDefault saves the value and payer of the record just registered as the default of the user, a failure only
+// loses the suggestion
+func (f *flow[T]) rememberDefault(recordType models.RecordType, value float64, payer string) {
+	if f.userId == 0 {
+		return
+	}
+	err := f.store.SetRecordDefault(&models.RecordDefault{
+		UserId:     f.userId,
+		Apartment:  f.apartmentName,
+		RecordType: recordType,
+		Value:      value,
+		Payer:      payer,
+	})
+	if err != nil {
+		log.Printf("error while saving the default %v of %s: %v", recordType, f.apartmentName, err.Error())
+	}
+}
@@ -0,0 +1,18 @@
+package chat_flow
+
+// Document is returned in place of a keyboard markup when the reply is a file, the reply text being its caption
+type Document struct {
+	Name string
+	Data []byte
+}
+
+// Photo is returned in place of a keyboard markup when the reply is an image, the reply text being its caption
+type Photo struct {
+	Name string
+	Data []byte
+}
+
+// DocumentReceiver is implemented by sessions which accept a file uploaded to the chat as an answer
+type DocumentReceiver interface {
+	ReceiveDocument(name string, data []byte) (string, interface{})
+}
@@ -10,9 +10,9 @@ func (f *flow[T]) energyBillFlow(answer string) (string, interface{}) {
 	switch f.step {
 	case stepBeginEnergyBill:
 		f.step = stepGetValueEnergyBill
-		return "Qual o valor da conta de energia?", nil
+		return f.askValue("Qual o valor da conta de energia?", models.RecordEnergyBill)
 	case stepGetValueEnergyBill:
-		value, err := parsePriceFromStr(answer)
+		value, err := f.parseValue(answer)
 		if err != nil {
 			return err.Error(), nil
 		}
@@ -31,13 +31,15 @@ func (f *flow[T]) energyBillFlow(answer string) (string, interface{}) {
 		}
 		f.value.(*models.EnergyBill).Date = t
 		f.step = stepGetPayerEnergyBill
-		return "Quem pagou essa conta de energia?", f.assembleKeyboardMenuWithPayers()
+		return f.askPayer("Quem pagou essa conta de energia?")
 	case stepGetPayerEnergyBill:
-		f.value.(*models.EnergyBill).Payer = answer
-		if err := f.store.AddBill(f.value.(*models.EnergyBill)); err != nil {
-			return fmt.Sprintf("Falha ao registrar conta de energia %v - %v", f.value.(*models.EnergyBill).ToString(), err.Error()), nil
+		e := f.value.(*models.EnergyBill)
+		e.Payer = answer
+		if err := f.store.AddBill(e); err != nil {
+			return fmt.Sprintf("Falha ao registrar conta de energia %v - %v", e.ToString(), err.Error()), nil
 		}
-		return fmt.Sprintf("Conta de energia adicionada - %v", f.value.(*models.EnergyBill).ToString()), nil
+		f.rememberDefault(models.RecordEnergyBill, e.Value, e.Payer)
+		return f.askAttachment(fmt.Sprintf("Conta de energia adicionada - %v", e.ToString()), models.RecordEnergyBill, e.Date, e.Value)
 	}
 	return "", nil
 }
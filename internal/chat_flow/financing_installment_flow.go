@@ -10,9 +10,9 @@ func (f *flow[T]) financingInstallmentFlow(answer string) (string, interface{})
 	switch f.step {
 	case stepBeginFinancingInstallment:
 		f.step = stepGetFinancialInstallmentValue
-		return "Qual o valor pago na parcela?", nil
+		return f.askValue("Qual o valor pago na parcela?", models.RecordFinancingInstallment)
 	case stepGetFinancialInstallmentValue:
-		v, err := parsePriceFromStr(answer)
+		v, err := f.parseValue(answer)
 		if err != nil {
 			return err.Error(), nil
 		}
@@ -29,15 +29,16 @@ func (f *flow[T]) financingInstallmentFlow(answer string) (string, interface{})
 		}
 		f.value.(*models.FinancingInstallment).Date = t
 		f.step = stepGetFinancialInstallmentPayer
-		return "Quem pagou esta parcela?", f.assembleKeyboardMenuWithPayers()
+		return f.askPayer("Quem pagou esta parcela?")
 	case stepGetFinancialInstallmentPayer:
-		f.value.(*models.FinancingInstallment).Payer = answer
-		err := f.store.AddFinancingInstallment(f.value.(*models.FinancingInstallment))
+		fi := f.value.(*models.FinancingInstallment)
+		fi.Payer = answer
+		err := f.store.AddFinancingInstallment(fi)
 		if err != nil {
 			return fmt.Sprintf("Falha ao registrar pagamento de parcela - %v", err.Error()), nil
 		}
-		f.step = stepEnd
-		return fmt.Sprintf("Pagamento de parcela registrado: %v", f.value.(*models.FinancingInstallment).ToString()), nil
+		f.rememberDefault(models.RecordFinancingInstallment, fi.Value, fi.Payer)
+		return f.askAttachment(fmt.Sprintf("Pagamento de parcela registrado: %v", fi.ToString()), models.RecordFinancingInstallment, fi.Date, fi.Value)
 	}
 	return "", nil
 }
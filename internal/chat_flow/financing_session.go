@@ -0,0 +1,192 @@
+package chat_flow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/financing"
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+const (
+	financingScheduleAnswer = "Ver cronograma"
+	editFinancingAnswer     = "Alterar contrato"
+	removeFinancingAnswer   = "Remover contrato"
+	noIndexAnswer           = "Nenhum"
+)
+
+type financingSession struct {
+	apartmentSelector
+	store    storage.Store
+	step     Step
+	contract *models.FinancingContract
+	status   *financing.Status
+}
+
+// NewFinancingSession shows the balance and remaining term of the financing of an apartment, reconciled with the
+// installments and amortizations recorded, and registers its contract
+func NewFinancingSession(store storage.Store) ChatSession {
+	return &financingSession{
+		apartmentSelector: newApartmentSelector(store),
+		store:             store,
+		step:              stepBeginFinancing,
+	}
+}
+
+func (s *financingSession) Next(answer string) (string, interface{}) {
+	replyText, markup := s.next(answer)
+	return s.withApartmentName(replyText), markup
+}
+
+// Ended tells the conversation is over
+func (s *financingSession) Ended() bool {
+	return s.step == stepEnd
+}
+
+func (s *financingSession) next(answer string) (string, interface{}) {
+	if !s.apartmentSelected() {
+		replyText, markup, failed := s.selectApartment(answer)
+		if failed {
+			s.step = stepEnd
+		}
+		if !s.apartmentSelected() {
+			return replyText, markup
+		}
+	}
+
+	switch s.step {
+	case stepBeginFinancing:
+		contract, err := financing.FindContract(s.store, s.apartmentName)
+		if err != nil {
+			s.step = stepEnd
+			return fmt.Sprintf("Falha ao consultar o financiamento - %v", err.Error()), nil
+		}
+		if contract == nil {
+			s.contract = &models.FinancingContract{Apartment: models.Apartment{Name: s.apartmentName}}
+			s.step = stepGetFinancingPrincipal
+			return "Nenhum financiamento cadastrado. Qual foi o valor financiado?", nil
+		}
+
+		s.contract = contract
+		s.status, err = financing.StoredStatus(s.store, contract, time.Now())
+		if err != nil {
+			s.step = stepEnd
+			return fmt.Sprintf("Falha ao consultar os pagamentos do financiamento - %v", err.Error()), nil
+		}
+		s.step = stepGetFinancingAction
+		return formatFinancingStatus(contract, s.status), tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(financingScheduleAnswer, financingScheduleAnswer),
+			tgbotapi.NewInlineKeyboardButtonData(editFinancingAnswer, editFinancingAnswer),
+			tgbotapi.NewInlineKeyboardButtonData(removeFinancingAnswer, removeFinancingAnswer),
+		))
+	case stepGetFinancingAction:
+		switch answer {
+		case financingScheduleAnswer:
+			s.step = stepEnd
+			return "Cronograma do financiamento", Document{
+				Name: fmt.Sprintf("financiamento-%v.csv", s.apartmentName),
+				Data: financing.ScheduleCSV(s.status.Schedule),
+			}
+		case editFinancingAnswer:
+			s.step = stepGetFinancingPrincipal
+			return "Qual foi o valor financiado?", nil
+		case removeFinancingAnswer:
+			s.step = stepEnd
+			if err := s.store.RemoveFinancingContract(s.contract); err != nil {
+				return fmt.Sprintf("Falha ao remover o financiamento - %v", err.Error()), nil
+			}
+			return "Financiamento removido", nil
+		}
+		return "Selecione uma das opçoes", nil
+	case stepGetFinancingPrincipal:
+		v, err := parsePriceFromStr(answer)
+		if err != nil {
+			return err.Error(), nil
+		}
+		s.contract.Principal = v
+		s.step = stepGetFinancingRate
+		return "Qual a taxa de juros efetiva ao ano, em %?", nil
+	case stepGetFinancingRate:
+		rate, err := strconv.ParseFloat(strings.Replace(strings.TrimSuffix(strings.TrimSpace(answer), "%"), ",", ".", 1), 64)
+		if err != nil {
+			return fmt.Sprintf("%v nao é uma taxa válida, informe por exemplo 9,5", answer), nil
+		}
+		s.contract.AnnualRate = rate
+		s.step = stepGetFinancingTerm
+		return "Em quantas parcelas mensais?", nil
+	case stepGetFinancingTerm:
+		term, err := strconv.Atoi(strings.TrimSpace(answer))
+		if err != nil || term < 1 {
+			return fmt.Sprintf("%v nao é um prazo válido, informe o número de parcelas", answer), nil
+		}
+		s.contract.Term = term
+		s.step = stepGetFinancingSystem
+		var row []tgbotapi.InlineKeyboardButton
+		for _, system := range models.AmortizationSystems {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(string(system), string(system)))
+		}
+		return "Qual o sistema de amortizaçao?", tgbotapi.NewInlineKeyboardMarkup(row)
+	case stepGetFinancingSystem:
+		system, ok := models.ParseAmortizationSystem(answer)
+		if !ok {
+			return "Selecione um dos sistemas de amortizaçao", nil
+		}
+		s.contract.System = system
+		s.step = stepGetFinancingStart
+		return "Qual a data de vencimento da primeira parcela?", nil
+	case stepGetFinancingStart:
+		t, err := parseDateFromFullDate(answer)
+		if err != nil {
+			return err.Error(), nil
+		}
+		s.contract.Start = t
+		s.step = stepGetFinancingIndex
+		row := tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(noIndexAnswer, noIndexAnswer))
+		for _, index := range models.CorrectionIndexes {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(string(index), string(index)))
+		}
+		return "Qual o índice de correçao do saldo devedor?", tgbotapi.NewInlineKeyboardMarkup(row)
+	case stepGetFinancingIndex:
+		if answer == noIndexAnswer {
+			answer = string(models.IndexNone)
+		}
+		index, ok := models.ParseCorrectionIndex(answer)
+		if !ok {
+			return "Selecione um dos índices", nil
+		}
+		s.contract.Index = index
+		s.step = stepEnd
+		if err := s.store.SetFinancingContract(s.contract); err != nil {
+			return fmt.Sprintf("Falha ao salvar o financiamento - %v", err.Error()), nil
+		}
+		return fmt.Sprintf("Financiamento salvo: %v", s.contract.ToString()), nil
+	}
+	return "", nil
+}
+
+func formatFinancingStatus(contract *models.FinancingContract, status *financing.Status) string {
+	lines := []string{
+		contract.ToString(),
+		fmt.Sprintf("Parcelas pagas: %d de %d vencidas", status.PaidInstallments, status.DueInstallments),
+		fmt.Sprintf("Total pago em parcelas: R$%.2f (previsto R$%.2f)", status.PaidValue, status.ScheduledValue),
+		fmt.Sprintf("Total amortizado: R$%.2f", status.ExtraValue),
+		fmt.Sprintf("Saldo devedor: R$%.2f", status.Balance),
+		fmt.Sprintf("Prazo restante: %d meses", status.RemainingTerm),
+	}
+	if next := status.NextInstallment(); next != nil {
+		lines = append(lines, fmt.Sprintf("Próxima parcela: R$%.2f em %v", next.Payment, next.Date.Format("02/01/2006")))
+	}
+	if late := status.DueInstallments - status.PaidInstallments; late > 0 {
+		lines = append(lines, fmt.Sprintf("%d parcelas vencidas sem registro", late))
+	}
+	if months := status.UncorrectedMonths; len(months) > 0 {
+		lines = append(lines, fmt.Sprintf("Atençao: %d meses sem valor do %v (%v a %v) foram corrigidos em 0%%, importe a série com /indices %v",
+			len(months), contract.Index, months[0].Format("01/2006"), months[len(months)-1].Format("01/2006"), contract.Index))
+	}
+	return strings.Join(lines, "\n")
+}
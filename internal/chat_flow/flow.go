@@ -10,6 +10,7 @@ import (
 	"github.com/gustavolopess/hoteleiro/internal/format"
 	"github.com/gustavolopess/hoteleiro/internal/models"
 	"github.com/gustavolopess/hoteleiro/internal/storage"
+	"github.com/gustavolopess/hoteleiro/internal/storage/blob"
 )
 
 type Flow[T models.Models] interface {
@@ -28,6 +29,8 @@ const (
 	stepGetValueRent
 	stepGetDateBeginRent
 	stepGetDateEndRent
+	stepGetBookingDateRent
+	stepGetReceiptDateRent
 	stepGetRenter
 	stepGetRentReceiver
 
@@ -49,34 +52,133 @@ const (
 	stepGetDescriptionMiscellaneousExpense
 	stepGetValueMiscellaneousExpense
 	stepGetDateMiscellaneousExpense
+	stepGetCategoryMiscellaneousExpense
 	stepGetPayerMiscellaneousExpense
 
 	stepBeginAmortization
 	stepGetPayerAmortization
 	stepGetValueAmortization
 	stepGetDateAmortization
+	stepGetOptionAmortization
 
 	stepBeginFinancingInstallment
 	stepGetFinancialInstallmentDate
 	stepGetFinancialInstallmentValue
 	stepGetFinancialInstallmentPayer
 
+	stepBeginCalendarImport
+	stepGetCalendarSource
+	stepGetImportedRentValue
+	stepGetImportedRenter
+	stepGetImportedRentReceiver
+
+	stepBeginAirbnbImport
+	stepGetAirbnbCsv
+	stepGetAirbnbListing
+	stepGetAirbnbReceiver
+	stepConfirmAirbnbImport
+
+	stepBeginBankStatementImport
+	stepGetBankStatement
+
+	stepGetAttachment
+
+	stepBeginAttachmentFetch
+	stepGetAttachmentToFetch
+
+	stepBeginInvite
+	stepGetInviteRole
+
+	stepBeginRevoke
+	stepGetRevoked
+
+	stepBeginApartmentManagement
+	stepGetApartmentAction
+	stepGetApartmentNewName
+	stepGetApartmentAddress
+	stepGetApartmentAcquisitionDate
+	stepGetApartmentPurchasePrice
+	stepGetApartmentFinancingBank
+	stepGetApartmentListingURLs
+
+	stepBeginRecurringExpense
+	stepGetRecurringExpenseAction
+	stepGetRecurringExpenseType
+	stepGetRecurringExpenseValue
+	stepGetRecurringExpenseDay
+	stepGetRecurringExpensePayer
+	stepGetRecurringExpenseStart
+	stepGetRecurringExpenseEnd
+	stepGetRecurringExpenseMode
+	stepGetRemovedRecurringExpense
+
+	stepBeginPaymentDue
+	stepGetPaymentDueType
+	stepGetPaymentDueDay
+
+	stepBeginCleaningSchedule
+	stepGetScheduledCleaner
+	stepGetScheduledCleaningPayer
+
+	stepBeginScheduledCleanings
+	stepGetScheduledCleaning
+	stepGetScheduledCleaningValue
+
+	stepBeginReportSubscription
+	stepGetReportSubscriptionAction
+
+	stepBeginIndicators
+
+	stepBeginFinancing
+	stepGetFinancingAction
+	stepGetFinancingPrincipal
+	stepGetFinancingRate
+	stepGetFinancingTerm
+	stepGetFinancingSystem
+	stepGetFinancingStart
+	stepGetFinancingIndex
+
+	stepGetImportedIndex
+	stepGetIndexCsv
+
+	stepGetTaxReportFormat
+
+	stepBeginStatement
+
+	stepBeginChart
+
+	stepBeginProfitability
+
+	stepBeginBudget
+	stepGetBudgetType
+	stepGetBudgetValue
+
+	stepBeginForecast
+
 	stepEnd
 )
 
 type flow[T models.Models] struct {
-	store               storage.Store
-	step                Step
-	askedApartment      bool
-	apartmentName       string
-	availableApartments []string
-	value               any
-	currentFlow         func(string) (string, interface{})
+	apartmentSelector
+	// selectsApartment is false for flows which don't act on an existing apartment
+	selectsApartment bool
+	store            storage.Store
+	blobs            blob.Store
+	step             Step
+	value            any
+	attachment       *models.Attachment
+	// recordDefault is offered to be repeated, and repeatsDefault tells if it was accepted
+	recordDefault  *models.RecordDefault
+	repeatsDefault bool
+	currentFlow    func(string) (string, interface{})
 }
 
-func NewFlow[T models.Models](store storage.Store) Flow[T] {
+func NewFlow[T models.Models](store storage.Store, blobs blob.Store) Flow[T] {
 	f := &flow[T]{
-		store: store,
+		apartmentSelector: newApartmentSelector(store),
+		selectsApartment:  true,
+		store:             store,
+		blobs:             blobs,
 	}
 
 	var b T
@@ -95,6 +197,7 @@ func NewFlow[T models.Models](store storage.Store) Flow[T] {
 		f.currentFlow = f.condoFlow
 	case models.Apartment:
 		f.step = stepBeginApartment
+		f.selectsApartment = false
 		f.currentFlow = f.apartmentFlow
 	case models.MiscellaneousExpense:
 		f.step = stepBeginMiscellaneousExpense
@@ -110,75 +213,59 @@ func NewFlow[T models.Models](store storage.Store) Flow[T] {
 	return f
 }
 
-func (f *flow[T]) isApartmentValid(apartment string) bool {
-	for _, apt := range f.availableApartments {
-		if apt == apartment {
-			return true
-		}
+// assembleKeyboardMenuWithPayers offers the partners registered in the store, a failure to read them only leaves the
+// name to be typed
+func assembleKeyboardMenuWithPayers(store storage.Store) tgbotapi.InlineKeyboardMarkup {
+	users, err := store.GetUsers()
+	if err != nil {
+		log.Printf("error while reading the partners: %v", err.Error())
 	}
-	return false
-}
-
-func (f *flow[T]) assembleKeyboardMenuWithApartments() tgbotapi.InlineKeyboardMarkup {
-	var currRow []tgbotapi.InlineKeyboardButton
-
-	keyboard := tgbotapi.NewInlineKeyboardMarkup()
-	for i, apt := range f.availableApartments {
-		if i > 0 && i%3 == 0 {
-			keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, currRow)
-			currRow = []tgbotapi.InlineKeyboardButton{}
-		} else {
-			currRow = append(currRow, tgbotapi.NewInlineKeyboardButtonData(apt, apt))
-		}
+	var row []tgbotapi.InlineKeyboardButton
+	for _, p := range models.PartnerNames(users) {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(p, p))
 	}
-	if len(currRow) > 0 {
-		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, currRow)
+	if len(row) == 0 {
+		return tgbotapi.NewInlineKeyboardMarkup()
 	}
-
-	return keyboard
+	return tgbotapi.NewInlineKeyboardMarkup(row)
 }
 
-func (f *flow[T]) assembleKeyboardMenuWithPayers() tgbotapi.InlineKeyboardMarkup {
+// skipKeyboard offers to skip an optional question
+func skipKeyboard() tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Gustavo", "Gustavo"),
-			tgbotapi.NewInlineKeyboardButtonData("Emerson", "Emerson"),
-		),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(skipAnswer, skipAnswer)),
 	)
 }
 
 func (f *flow[T]) Next(answer string) (string, interface{}) {
 	replyText, markup := f.next(answer)
-	if len(replyText) > 0 && len(f.apartmentName) > 0 {
-		replyText = fmt.Sprintf("[%s] %s", f.apartmentName, replyText)
-	}
-	return replyText, markup
+	return f.withApartmentName(replyText), markup
+}
+
+// Ended tells the conversation is over
+func (f *flow[T]) Ended() bool {
+	return f.step == stepEnd
 }
 
 func (f *flow[T]) next(answer string) (string, interface{}) {
-	if !f.askedApartment {
-		f.askedApartment = true
-		availableApartments, err := f.store.GetAvailableApartments()
-		if err != nil {
+	if f.selectsApartment && !f.apartmentSelected() {
+		replyText, markup, failed := f.selectApartment(answer)
+		if failed {
 			f.step = stepEnd
-			log.Printf("error while getting available apartments: %v", err.Error())
-			return "Ocorreu um erro inesperado, tenete novamente :(", nil
 		}
-		f.availableApartments = availableApartments
-		return "Selecione o apartamento", f.assembleKeyboardMenuWithApartments()
-	}
-
-	if f.apartmentName == "" {
-		if !f.isApartmentValid(answer) {
-			return "Imóvel nao existe. De qual imóvel estamos falando?", nil
+		if !f.apartmentSelected() {
+			return replyText, markup
 		}
-		f.apartmentName = answer
 	}
 
 	if f.step == stepEnd {
 		return "", nil
 	}
 
+	if f.step == stepGetAttachment {
+		return f.attachmentFlow(answer)
+	}
+
 	return f.currentFlow(answer)
 }
 
@@ -0,0 +1,73 @@
+package chat_flow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/report"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+const (
+	forecastMonths    = 6
+	minForecastMonths = 3
+	maxForecastMonths = 12
+)
+
+type forecastSession struct {
+	store storage.Store
+	step  Step
+}
+
+// NewForecastSession projects the cash flow of every active apartment and of all of them together over the next six
+// months, or over the number of months given as in /previsao 12
+func NewForecastSession(store storage.Store) ChatSession {
+	return &forecastSession{
+		store: store,
+		step:  stepBeginForecast,
+	}
+}
+
+func (s *forecastSession) Next(answer string) (string, interface{}) {
+	if s.step != stepBeginForecast {
+		return "", nil
+	}
+	s.step = stepEnd
+
+	months := forecastMonths
+	if answer = strings.TrimSpace(answer); len(answer) > 0 {
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < minForecastMonths || n > maxForecastMonths {
+			return fmt.Sprintf("%v nao é válido, informe de %d a %d meses", answer, minForecastMonths, maxForecastMonths), nil
+		}
+		months = n
+	}
+
+	apartments, err := s.store.GetApartments()
+	if err != nil {
+		return fmt.Sprintf("Falha ao consultar os imóveis - %v", err.Error()), nil
+	}
+	var active []*models.Apartment
+	for _, apt := range apartments {
+		if !apt.Archived {
+			active = append(active, apt)
+		}
+	}
+	if len(active) == 0 {
+		return "Nenhum imóvel ativo", nil
+	}
+
+	forecast, err := report.BuildForecast(s.store, active, time.Now(), months)
+	if err != nil {
+		return fmt.Sprintf("Falha ao calcular a previsao - %v", err.Error()), nil
+	}
+	return forecast.Format(), nil
+}
+
+// Ended tells the conversation is over
+func (s *forecastSession) Ended() bool {
+	return s.step == stepEnd
+}
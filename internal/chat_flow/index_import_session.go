@@ -0,0 +1,81 @@
+package chat_flow
+
+import (
+	"bytes"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/importer"
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+type indexImportSession struct {
+	store storage.Store
+	step  Step
+	index models.CorrectionIndex
+}
+
+// NewIndexImportSession reads the monthly variations of TR or IPCA from a CSV, as exported by the SGS of the Banco
+// Central, to correct the balance of the financings
+func NewIndexImportSession(store storage.Store) ChatSession {
+	return &indexImportSession{
+		store: store,
+		step:  stepGetImportedIndex,
+	}
+}
+
+func (s *indexImportSession) Next(answer string) (string, interface{}) {
+	switch s.step {
+	case stepGetImportedIndex:
+		index, ok := models.ParseCorrectionIndex(answer)
+		if !ok || index == models.IndexNone {
+			var row []tgbotapi.InlineKeyboardButton
+			for _, i := range models.CorrectionIndexes {
+				row = append(row, tgbotapi.NewInlineKeyboardButtonData(string(i), string(i)))
+			}
+			return "De qual índice sao os valores?", tgbotapi.NewInlineKeyboardMarkup(row)
+		}
+		s.index = index
+		s.step = stepGetIndexCsv
+		return fmt.Sprintf("Envie o CSV com as variaçoes mensais de %v, com as colunas data e valor (%%)", index), nil
+	case stepGetIndexCsv:
+		return fmt.Sprintf("Envie o CSV com as variaçoes mensais de %v como arquivo", s.index), nil
+	}
+	return "", nil
+}
+
+// Ended tells the conversation is over
+func (s *indexImportSession) Ended() bool {
+	return s.step == stepEnd
+}
+
+func (s *indexImportSession) ReceiveDocument(name string, data []byte) (string, interface{}) {
+	if s.step != stepGetIndexCsv {
+		return "Nao estou esperando um arquivo agora", nil
+	}
+
+	values, err := importer.ParseIndexSeries(bytes.NewReader(data), s.index)
+	if err != nil {
+		return fmt.Sprintf("Falha ao ler o arquivo %s - %v", name, err.Error()), nil
+	}
+	if len(values) == 0 {
+		return "Nenhum valor encontrado no arquivo", nil
+	}
+
+	s.step = stepEnd
+	if err := s.store.AddIndexValues(values); err != nil {
+		return fmt.Sprintf("Falha ao salvar os valores - %v", err.Error()), nil
+	}
+	first, last := values[0].Month, values[0].Month
+	for _, v := range values {
+		if v.Month.Before(first) {
+			first = v.Month
+		}
+		if v.Month.After(last) {
+			last = v.Month
+		}
+	}
+	return fmt.Sprintf("%d valores de %v importados, de %v a %v", len(values), s.index, first.Format("01/2006"), last.Format("01/2006")), nil
+}
@@ -0,0 +1,105 @@
+package chat_flow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/analytics"
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+type indicatorsSession struct {
+	apartmentSelector
+	store storage.Store
+	step  Step
+	// begin and end delimit the months of the indicators, end being the first day after them
+	begin time.Time
+	end   time.Time
+}
+
+// NewIndicatorsSession shows the occupancy, daily rate and stay indicators of an apartment, month by month, of the
+// last twelve months or of the year given as in /indicadores 2024
+func NewIndicatorsSession(store storage.Store) ChatSession {
+	selector := newApartmentSelector(store)
+	selector.includeArchived = true
+	return &indicatorsSession{
+		apartmentSelector: selector,
+		store:             store,
+		step:              stepBeginIndicators,
+	}
+}
+
+func (s *indicatorsSession) Next(answer string) (string, interface{}) {
+	if s.begin.IsZero() {
+		s.setPeriod(strings.TrimSpace(answer))
+	}
+	replyText, markup := s.next(answer)
+	return s.withApartmentName(replyText), markup
+}
+
+// Ended tells the conversation is over
+func (s *indicatorsSession) Ended() bool {
+	return s.step == stepEnd
+}
+
+func (s *indicatorsSession) setPeriod(year string) {
+	if y, err := strconv.Atoi(year); err == nil && y > 1900 {
+		s.begin = time.Date(y, time.January, 1, 0, 0, 0, 0, time.UTC)
+		s.end = s.begin.AddDate(1, 0, 0)
+		return
+	}
+	now := time.Now()
+	s.end = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+	s.begin = s.end.AddDate(-1, 0, 0)
+}
+
+func (s *indicatorsSession) next(answer string) (string, interface{}) {
+	if !s.apartmentSelected() {
+		replyText, markup, failed := s.selectApartment(answer)
+		if failed {
+			s.step = stepEnd
+		}
+		if !s.apartmentSelected() {
+			return replyText, markup
+		}
+	}
+
+	if s.step != stepBeginIndicators {
+		return "", nil
+	}
+	s.step = stepEnd
+
+	apartment := models.Apartment{Name: s.apartmentName}
+	rents, err := s.store.GetExistingRents(apartment)
+	if err != nil {
+		return fmt.Sprintf("Falha ao consultar os aluguéis - %v", err.Error()), nil
+	}
+
+	lines := []string{fmt.Sprintf("Indicadores de %v a %v", s.begin.Format("01/2006"), s.end.AddDate(0, 0, -1).Format("01/2006"))}
+	var booked, available int
+	var revenue float64
+	for _, m := range analytics.Monthly(rents, s.begin, s.end.AddDate(0, 0, -1), time.Now()) {
+		lines = append(lines, fmt.Sprintf("%v: %d noites, ocupaçao %.0f%%, diária média R$%.2f, RevPAR R$%.2f",
+			m.Month.Format("01/2006"), m.NightsBooked, m.Occupancy()*100, m.ADR(), m.RevPAR()))
+		booked += m.NightsBooked
+		available += m.NightsAvailable
+		revenue += m.Revenue
+	}
+	total := analytics.MonthlyMetrics{NightsBooked: booked, NightsAvailable: available, Revenue: revenue}
+	lines = append(lines, fmt.Sprintf("Total: %d noites, ocupaçao %.0f%%, diária média R$%.2f, RevPAR R$%.2f",
+		total.NightsBooked, total.Occupancy()*100, total.ADR(), total.RevPAR()))
+
+	stays := analytics.Stays(rents, s.begin, s.end)
+	lines = append(lines, fmt.Sprintf("Estadias: %d, duraçao média de %.1f noites", stays.Stays, stays.AverageLengthOfStay))
+	if stays.LeadTimeStays > 0 {
+		lines = append(lines, fmt.Sprintf("Antecedência média da reserva: %.1f dias (%d de %d estadias com data de reserva)",
+			stays.AverageLeadTime, stays.LeadTimeStays, stays.Stays))
+	} else {
+		lines = append(lines, "Antecedência média da reserva: nenhuma estadia com data de reserva")
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
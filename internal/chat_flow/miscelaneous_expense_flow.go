@@ -3,6 +3,8 @@ package chat_flow
 import (
 	"fmt"
 
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
 	"github.com/gustavolopess/hoteleiro/internal/models"
 )
 
@@ -17,9 +19,9 @@ func (f *flow[T]) miscellaneousExpenseFlow(answer string) (string, interface{})
 			Description: answer,
 			Apartment:   models.Apartment{Name: f.apartmentName},
 		}
-		return "Qual o valor da despesa?", nil
+		return f.askValue("Qual o valor da despesa?", models.RecordMiscellaneousExpense)
 	case stepGetValueMiscellaneousExpense:
-		value, err := parsePriceFromStr(answer)
+		value, err := f.parseValue(answer)
 		if err != nil {
 			return err.Error(), nil
 		}
@@ -32,15 +34,31 @@ func (f *flow[T]) miscellaneousExpenseFlow(answer string) (string, interface{})
 			return err.Error(), nil
 		}
 		f.value.(*models.MiscellaneousExpense).Date = t
+		f.step = stepGetCategoryMiscellaneousExpense
+		var row []tgbotapi.InlineKeyboardButton
+		for _, c := range models.ExpenseCategories {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(string(c), string(c)))
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(skipAnswer, skipAnswer))
+		return "A despesa é de alguma dessas categorias?", tgbotapi.NewInlineKeyboardMarkup(row)
+	case stepGetCategoryMiscellaneousExpense:
+		if answer != skipAnswer {
+			category, ok := models.ParseExpenseCategory(answer)
+			if !ok {
+				return "Selecione uma das categorias", nil
+			}
+			f.value.(*models.MiscellaneousExpense).Category = category
+		}
 		f.step = stepGetPayerMiscellaneousExpense
-		return "Quem pagou por essa despesa?", f.assembleKeyboardMenuWithPayers()
+		return f.askPayer("Quem pagou por essa despesa?")
 	case stepGetPayerMiscellaneousExpense:
-		f.value.(*models.MiscellaneousExpense).Payer = answer
-		if err := f.store.AddMiscellaneousExpense(f.value.(*models.MiscellaneousExpense)); err != nil {
-			return fmt.Sprintf("Falha ao adicionar a despesa %v - %v", f.value.(*models.MiscellaneousExpense).ToString(), err.Error()), nil
+		m := f.value.(*models.MiscellaneousExpense)
+		m.Payer = answer
+		if err := f.store.AddMiscellaneousExpense(m); err != nil {
+			return fmt.Sprintf("Falha ao adicionar a despesa %v - %v", m.ToString(), err.Error()), nil
 		}
-		f.step = stepEnd
-		return fmt.Sprintf("Despesa registrada: %v", f.value.(*models.MiscellaneousExpense).ToString()), nil
+		f.rememberDefault(models.RecordMiscellaneousExpense, m.Value, m.Payer)
+		return f.askAttachment(fmt.Sprintf("Despesa registrada: %v", m.ToString()), models.RecordMiscellaneousExpense, m.Date, m.Value)
 	}
 	return "", nil
 }
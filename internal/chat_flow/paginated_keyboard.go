@@ -0,0 +1,176 @@
+package chat_flow
+
+import (
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	previousPageAnswer = "◀ Anteriores"
+	nextPageAnswer     = "Próximos ▶"
+	// keyboardPageRows is how many rows of options are shown at once
+	keyboardPageRows = 4
+)
+
+// keyboardOption is a button of a paginated keyboard, the label is shown and the data is answered when it is pressed
+type keyboardOption struct {
+	label string
+	data  string
+}
+
+func newKeyboardOptions(values []string) []keyboardOption {
+	options := make([]keyboardOption, 0, len(values))
+	for _, v := range values {
+		options = append(options, keyboardOption{label: v, data: v})
+	}
+	return options
+}
+
+// paginatedKeyboard offers a long list of options some pages at a time, typed text filters the options by their labels
+type paginatedKeyboard struct {
+	options []keyboardOption
+	// pinned is shown on top of every page while the options are not filtered
+	pinned  *keyboardOption
+	columns int
+	page    int
+	filter  string
+}
+
+func newPaginatedKeyboard(options []keyboardOption, columns int) *paginatedKeyboard {
+	if columns < 1 {
+		columns = 1
+	}
+	return &paginatedKeyboard{
+		options: options,
+		columns: columns,
+	}
+}
+
+// pin moves the option answering data to the top of the keyboard under the label, it does nothing if there is no such
+// option
+func (k *paginatedKeyboard) pin(data, label string) {
+	for i, o := range k.options {
+		if o.data == data {
+			pinned := keyboardOption{label: label, data: o.data}
+			k.pinned = &pinned
+			k.options = append(k.options[:i:i], k.options[i+1:]...)
+			return
+		}
+	}
+}
+
+// isOption reports whether the answer is the data of one of the options
+func (k *paginatedKeyboard) isOption(answer string) bool {
+	if k.pinned != nil && k.pinned.data == answer {
+		return true
+	}
+	for _, o := range k.options {
+		if o.data == answer {
+			return true
+		}
+	}
+	return false
+}
+
+// navigate consumes the answers that do not choose an option: the page buttons and any typed text, which filters the
+// options. It reports whether the answer was consumed and, for typed text, whether some option matched it
+func (k *paginatedKeyboard) navigate(answer string) (consumed, matched bool) {
+	switch {
+	case k.isOption(answer):
+		return false, true
+	case answer == previousPageAnswer:
+		if k.page > 0 {
+			k.page--
+		}
+		return true, true
+	case answer == nextPageAnswer:
+		if k.page < k.pageCount()-1 {
+			k.page++
+		}
+		return true, true
+	}
+
+	k.page = 0
+	k.filter = strings.ToLower(strings.TrimSpace(answer))
+	if len(k.visible()) == 0 {
+		k.filter = ""
+		return true, false
+	}
+	return true, true
+}
+
+// only returns the single option left by the filter
+func (k *paginatedKeyboard) only() (keyboardOption, bool) {
+	visible := k.visible()
+	if len(k.filter) == 0 || len(visible) != 1 {
+		return keyboardOption{}, false
+	}
+	return visible[0], true
+}
+
+func (k *paginatedKeyboard) visible() []keyboardOption {
+	if len(k.filter) == 0 {
+		return k.options
+	}
+	var visible []keyboardOption
+	if k.pinned != nil && strings.Contains(strings.ToLower(k.pinned.label), k.filter) {
+		visible = append(visible, *k.pinned)
+	}
+	for _, o := range k.options {
+		if strings.Contains(strings.ToLower(o.label), k.filter) {
+			visible = append(visible, o)
+		}
+	}
+	return visible
+}
+
+func (k *paginatedKeyboard) pageSize() int {
+	return k.columns * keyboardPageRows
+}
+
+func (k *paginatedKeyboard) pageCount() int {
+	return (len(k.visible()) + k.pageSize() - 1) / k.pageSize()
+}
+
+// markup returns the buttons of the current page
+func (k *paginatedKeyboard) markup() tgbotapi.InlineKeyboardMarkup {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup()
+	if k.pinned != nil && len(k.filter) == 0 {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(k.pinned.label, k.pinned.data),
+		))
+	}
+
+	visible := k.visible()
+	begin := k.page * k.pageSize()
+	end := begin + k.pageSize()
+	if end > len(visible) {
+		end = len(visible)
+	}
+
+	var row []tgbotapi.InlineKeyboardButton
+	for _, o := range visible[begin:end] {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(o.label, o.data))
+		if len(row) == k.columns {
+			keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+	}
+
+	var navigation []tgbotapi.InlineKeyboardButton
+	if k.page > 0 {
+		navigation = append(navigation, tgbotapi.NewInlineKeyboardButtonData(previousPageAnswer, previousPageAnswer))
+	}
+	if end < len(visible) {
+		navigation = append(navigation, tgbotapi.NewInlineKeyboardButtonData(nextPageAnswer, nextPageAnswer))
+	}
+	if len(navigation) > 0 {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, navigation)
+	}
+
+	return keyboard
+}
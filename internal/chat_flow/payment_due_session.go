@@ -0,0 +1,122 @@
+package chat_flow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+const removePaymentDueAnswer = "Remover vencimento"
+
+type paymentDueSession struct {
+	apartmentSelector
+	chatId int64
+	store  storage.Store
+	step   Step
+	dues   []*models.PaymentDue
+	due    *models.PaymentDue
+}
+
+// NewPaymentDueSession sets the due days of the payments of an apartment, whose reminders are sent to the chat of
+// the session
+func NewPaymentDueSession(chatId int64, store storage.Store) ChatSession {
+	return &paymentDueSession{
+		apartmentSelector: newApartmentSelector(store),
+		chatId:            chatId,
+		store:             store,
+		step:              stepBeginPaymentDue,
+	}
+}
+
+func (s *paymentDueSession) Next(answer string) (string, interface{}) {
+	replyText, markup := s.next(answer)
+	return s.withApartmentName(replyText), markup
+}
+
+// Ended tells the conversation is over
+func (s *paymentDueSession) Ended() bool {
+	return s.step == stepEnd
+}
+
+func (s *paymentDueSession) next(answer string) (string, interface{}) {
+	if !s.apartmentSelected() {
+		replyText, markup, failed := s.selectApartment(answer)
+		if failed {
+			s.step = stepEnd
+		}
+		if !s.apartmentSelected() {
+			return replyText, markup
+		}
+	}
+
+	switch s.step {
+	case stepBeginPaymentDue:
+		dues, err := s.store.GetPaymentDues()
+		if err != nil {
+			s.step = stepEnd
+			return fmt.Sprintf("Falha ao consultar os vencimentos - %v", err.Error()), nil
+		}
+		lines := []string{"Vencimentos:"}
+		for _, d := range dues {
+			if d.Apartment.Name == s.apartmentName {
+				s.dues = append(s.dues, d)
+				lines = append(lines, "- "+d.ToString())
+			}
+		}
+		if len(s.dues) == 0 {
+			lines = []string{"Nenhum vencimento cadastrado"}
+		}
+
+		var row []tgbotapi.InlineKeyboardButton
+		for _, t := range models.PaymentDueTypes {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(string(t), string(t)))
+		}
+		s.step = stepGetPaymentDueType
+		return strings.Join(lines, "\n") + "\nDe qual pagamento deseja alterar o vencimento?", tgbotapi.NewInlineKeyboardMarkup(row)
+	case stepGetPaymentDueType:
+		t, ok := models.ParseRecordType(answer)
+		if !ok || !models.IsPaymentDueType(t) {
+			return "Selecione um dos tipos de pagamento", nil
+		}
+		// a new due day is reminded from the current month on
+		s.due = &models.PaymentDue{Apartment: models.Apartment{Name: s.apartmentName}, Type: t, ChatId: s.chatId, Start: time.Now()}
+		s.step = stepGetPaymentDueDay
+		for _, d := range s.dues {
+			if d.Type == t {
+				// the months already reminded are kept when the due day changes
+				changed := *d
+				s.due = &changed
+				s.due.ChatId = s.chatId
+				return "Em que dia do mês o pagamento vence?", tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(removePaymentDueAnswer, removePaymentDueAnswer),
+				))
+			}
+		}
+		return "Em que dia do mês o pagamento vence?", nil
+	case stepGetPaymentDueDay:
+		if answer == removePaymentDueAnswer {
+			s.step = stepEnd
+			if err := s.store.RemovePaymentDue(s.due); err != nil {
+				return fmt.Sprintf("Falha ao remover o vencimento - %v", err.Error()), nil
+			}
+			return "Vencimento removido", nil
+		}
+		day, err := strconv.Atoi(strings.TrimSpace(answer))
+		if err != nil || day < 1 || day > 31 {
+			return fmt.Sprintf("%v nao é um dia válido, informe um número entre 1 e 31", answer), nil
+		}
+		s.due.Day = day
+		s.step = stepEnd
+		if err := s.store.SetPaymentDue(s.due); err != nil {
+			return fmt.Sprintf("Falha ao salvar o vencimento - %v", err.Error()), nil
+		}
+		return fmt.Sprintf("Vencimento salvo: %v. Os lembretes serao enviados neste chat", s.due.ToString()), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,69 @@
+package chat_flow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/report"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// profitabilityMonths is the period of the returns when none is given
+const profitabilityMonths = 12
+
+type profitabilitySession struct {
+	store storage.Store
+	step  Step
+}
+
+// NewProfitabilitySession shows the return of every active apartment and ranks them, of the last twelve months or
+// of the month or year given as in /rentabilidade 2024
+func NewProfitabilitySession(store storage.Store) ChatSession {
+	return &profitabilitySession{
+		store: store,
+		step:  stepBeginProfitability,
+	}
+}
+
+func (s *profitabilitySession) Next(answer string) (string, interface{}) {
+	if s.step != stepBeginProfitability {
+		return "", nil
+	}
+	s.step = stepEnd
+
+	period := report.LastMonths(profitabilityMonths, time.Now())
+	if len(strings.TrimSpace(answer)) > 0 {
+		var err error
+		if period, err = report.ParsePeriod(answer, time.Now()); err != nil {
+			return err.Error(), nil
+		}
+	}
+
+	apartments, err := s.store.GetApartments()
+	if err != nil {
+		return fmt.Sprintf("Falha ao consultar os imóveis - %v", err.Error()), nil
+	}
+	var blocks []string
+	var profits []*report.Profitability
+	for _, apt := range apartments {
+		p, err := report.BuildProfitability(s.store, apt, period)
+		if err != nil {
+			return fmt.Sprintf("Falha ao calcular a rentabilidade de %v - %v", apt.Name, err.Error()), nil
+		}
+		profits = append(profits, p)
+		blocks = append(blocks, p.Format())
+	}
+	if len(profits) == 0 {
+		return "Nenhum imóvel cadastrado", nil
+	}
+	if len(profits) > 1 {
+		blocks = append(blocks, report.CompareProfitability(profits))
+	}
+	return strings.Join(blocks, "\n\n"), nil
+}
+
+// Ended tells the conversation is over
+func (s *profitabilitySession) Ended() bool {
+	return s.step == stepEnd
+}
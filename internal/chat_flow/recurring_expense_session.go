@@ -0,0 +1,193 @@
+package chat_flow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+const (
+	addRecurringExpenseAnswer    = "Adicionar"
+	removeRecurringExpenseAnswer = "Remover"
+	automaticModeAnswer          = "Automático"
+	confirmModeAnswer            = "Pedir confirmaçao"
+)
+
+type recurringExpenseSession struct {
+	apartmentSelector
+	chatId   int64
+	store    storage.Store
+	step     Step
+	expenses []*models.RecurringExpense
+	expense  *models.RecurringExpense
+}
+
+// NewRecurringExpenseSession lists, adds and removes the recurring expenses of an apartment, the confirmations of
+// the recurring expenses added are asked in the chat of the session
+func NewRecurringExpenseSession(chatId int64, store storage.Store) ChatSession {
+	return &recurringExpenseSession{
+		apartmentSelector: newApartmentSelector(store),
+		chatId:            chatId,
+		store:             store,
+		step:              stepBeginRecurringExpense,
+	}
+}
+
+func (s *recurringExpenseSession) Next(answer string) (string, interface{}) {
+	replyText, markup := s.next(answer)
+	return s.withApartmentName(replyText), markup
+}
+
+// Ended tells the conversation is over
+func (s *recurringExpenseSession) Ended() bool {
+	return s.step == stepEnd
+}
+
+func (s *recurringExpenseSession) next(answer string) (string, interface{}) {
+	if !s.apartmentSelected() {
+		replyText, markup, failed := s.selectApartment(answer)
+		if failed {
+			s.step = stepEnd
+		}
+		if !s.apartmentSelected() {
+			return replyText, markup
+		}
+	}
+
+	switch s.step {
+	case stepBeginRecurringExpense:
+		expenses, err := s.store.GetRecurringExpenses()
+		if err != nil {
+			s.step = stepEnd
+			return fmt.Sprintf("Falha ao consultar as despesas recorrentes - %v", err.Error()), nil
+		}
+		for _, e := range expenses {
+			if e.Apartment.Name == s.apartmentName {
+				s.expenses = append(s.expenses, e)
+			}
+		}
+
+		row := tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(addRecurringExpenseAnswer, addRecurringExpenseAnswer))
+		if len(s.expenses) > 0 {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(removeRecurringExpenseAnswer, removeRecurringExpenseAnswer))
+		}
+		s.step = stepGetRecurringExpenseAction
+		return s.listExpenses() + "\nO que deseja fazer?", tgbotapi.NewInlineKeyboardMarkup(row)
+	case stepGetRecurringExpenseAction:
+		switch answer {
+		case addRecurringExpenseAnswer:
+			s.expense = &models.RecurringExpense{Apartment: models.Apartment{Name: s.apartmentName}, ChatId: s.chatId}
+			s.step = stepGetRecurringExpenseType
+			var row []tgbotapi.InlineKeyboardButton
+			for _, t := range models.RecurringExpenseTypes {
+				row = append(row, tgbotapi.NewInlineKeyboardButtonData(string(t), string(t)))
+			}
+			return "Qual o tipo da despesa?", tgbotapi.NewInlineKeyboardMarkup(row)
+		case removeRecurringExpenseAnswer:
+			if len(s.expenses) == 0 {
+				return "Nenhuma despesa recorrente para remover", nil
+			}
+			s.step = stepGetRemovedRecurringExpense
+			keyboard := tgbotapi.NewInlineKeyboardMarkup()
+			for i, e := range s.expenses {
+				keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(e.ToString(), strconv.Itoa(i)),
+				))
+			}
+			return "Qual despesa recorrente deve ser removida?", keyboard
+		}
+		return "Selecione uma das opçoes", nil
+	case stepGetRecurringExpenseType:
+		t, ok := models.ParseRecordType(answer)
+		if !ok || !models.IsRecurringExpenseType(t) {
+			return "Selecione um dos tipos de despesa", nil
+		}
+		s.expense.Type = t
+		s.step = stepGetRecurringExpenseValue
+		return "Qual o valor da despesa?", nil
+	case stepGetRecurringExpenseValue:
+		value, err := parsePriceFromStr(answer)
+		if err != nil {
+			return err.Error(), nil
+		}
+		s.expense.Value = value
+		s.step = stepGetRecurringExpenseDay
+		return "Em que dia do mês a despesa vence?", nil
+	case stepGetRecurringExpenseDay:
+		day, err := strconv.Atoi(strings.TrimSpace(answer))
+		if err != nil || day < 1 || day > 31 {
+			return fmt.Sprintf("%v nao é um dia válido, informe um número entre 1 e 31", answer), nil
+		}
+		s.expense.Day = day
+		s.step = stepGetRecurringExpensePayer
+		return "Quem paga essa despesa?", assembleKeyboardMenuWithPayers(s.store)
+	case stepGetRecurringExpensePayer:
+		s.expense.Payer = answer
+		s.step = stepGetRecurringExpenseStart
+		return "A partir de qual data a despesa deve ser registrada? informe no formato dd/mm/aaaa", nil
+	case stepGetRecurringExpenseStart:
+		t, err := parseDateFromFullDate(answer)
+		if err != nil {
+			return err.Error(), nil
+		}
+		s.expense.Start = t
+		s.step = stepGetRecurringExpenseEnd
+		return "Até qual data a despesa deve ser registrada? informe no formato dd/mm/aaaa", skipKeyboard()
+	case stepGetRecurringExpenseEnd:
+		if answer != skipAnswer {
+			t, err := parseDateFromFullDate(answer)
+			if err != nil {
+				return err.Error(), nil
+			}
+			s.expense.End = t
+		}
+		s.step = stepGetRecurringExpenseMode
+		return "Os registros devem ser criados automaticamente ou após uma confirmaçao aqui no chat?", tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(automaticModeAnswer, automaticModeAnswer),
+				tgbotapi.NewInlineKeyboardButtonData(confirmModeAnswer, confirmModeAnswer),
+			),
+		)
+	case stepGetRecurringExpenseMode:
+		if answer != automaticModeAnswer && answer != confirmModeAnswer {
+			return "Selecione uma das opçoes", nil
+		}
+		s.expense.Confirm = answer == confirmModeAnswer
+		// past months are not registered, only the ones from now on
+		now := time.Now()
+		s.expense.LastMonth = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -1, 0)
+		s.step = stepEnd
+		if err := s.store.AddRecurringExpense(s.expense); err != nil {
+			return fmt.Sprintf("Falha ao adicionar a despesa recorrente - %v", err.Error()), nil
+		}
+		return fmt.Sprintf("Despesa recorrente adicionada: %v", s.expense.ToString()), nil
+	case stepGetRemovedRecurringExpense:
+		i, err := strconv.Atoi(answer)
+		if err != nil || i < 0 || i >= len(s.expenses) {
+			return "Selecione uma das despesas da lista", nil
+		}
+		s.step = stepEnd
+		if err := s.store.RemoveRecurringExpense(s.expenses[i]); err != nil {
+			return fmt.Sprintf("Falha ao remover a despesa recorrente - %v", err.Error()), nil
+		}
+		return fmt.Sprintf("Despesa recorrente removida: %v", s.expenses[i].ToString()), nil
+	}
+	return "", nil
+}
+
+func (s *recurringExpenseSession) listExpenses() string {
+	if len(s.expenses) == 0 {
+		return "Nenhuma despesa recorrente cadastrada"
+	}
+	lines := []string{"Despesas recorrentes:"}
+	for _, e := range s.expenses {
+		lines = append(lines, "- "+e.ToString())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -38,12 +38,32 @@ func (f *flow[T]) rentFlow(answer string) (string, interface{}) {
 			return err.Error(), nil
 		}
 		f.value.(*models.Rent).DateEnd = t
+		f.step = stepGetBookingDateRent
+		return "Em que data a reserva foi feita? informe a data no formato dd/mm/aaaa", skipKeyboard()
+	case stepGetBookingDateRent:
+		if answer != skipAnswer {
+			t, err := parseDateFromFullDate(answer)
+			if err != nil {
+				return err.Error(), nil
+			}
+			f.value.(*models.Rent).BookedAt = t
+		}
+		f.step = stepGetReceiptDateRent
+		return "Em que data o aluguel foi recebido? informe a data no formato dd/mm/aaaa, ou pule se foi na entrada", skipKeyboard()
+	case stepGetReceiptDateRent:
+		if answer != skipAnswer {
+			t, err := parseDateFromFullDate(answer)
+			if err != nil {
+				return err.Error(), nil
+			}
+			f.value.(*models.Rent).ReceivedAt = t
+		}
 		f.step = stepGetRenter
 		return "Qual o nome do inquilino?", nil
 	case stepGetRenter:
 		f.value.(*models.Rent).Renter = answer
 		f.step = stepGetRentReceiver
-		return "Quem recebeu o dinheiro do aluguel?", f.assembleKeyboardMenuWithPayers()
+		return "Quem recebeu o dinheiro do aluguel?", assembleKeyboardMenuWithPayers(f.store)
 	case stepGetRentReceiver:
 		f.value.(*models.Rent).Receiver = answer
 		err := f.store.AddRent(f.value.(*models.Rent))
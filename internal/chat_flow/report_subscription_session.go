@@ -0,0 +1,113 @@
+package chat_flow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+const cancelSubscriptionPrefix = "Cancelar "
+
+type reportSubscriptionSession struct {
+	chatId int64
+	store  storage.Store
+	// now is the clock of the scheduler, which delivers the reports
+	now           func() time.Time
+	step          Step
+	subscriptions []*models.ReportSubscription
+}
+
+// NewReportSubscriptionSession subscribes the chat to the weekly or monthly report, which can be given right away as
+// in /assinar mensal, or cancels a subscription
+func NewReportSubscriptionSession(chatId int64, store storage.Store, now func() time.Time) ChatSession {
+	return &reportSubscriptionSession{
+		chatId: chatId,
+		store:  store,
+		now:    now,
+		step:   stepBeginReportSubscription,
+	}
+}
+
+func (s *reportSubscriptionSession) Next(answer string) (string, interface{}) {
+	answer = strings.TrimSpace(answer)
+	switch s.step {
+	case stepBeginReportSubscription:
+		if frequency, ok := models.ParseReportFrequency(strings.ToLower(answer)); ok {
+			return s.subscribe(frequency)
+		}
+
+		subscriptions, err := s.store.GetReportSubscriptions()
+		if err != nil {
+			s.step = stepEnd
+			return fmt.Sprintf("Falha ao consultar as assinaturas - %v", err.Error()), nil
+		}
+		lines := []string{"Assinaturas deste chat:"}
+		for _, sub := range subscriptions {
+			if sub.ChatId == s.chatId {
+				s.subscriptions = append(s.subscriptions, sub)
+				lines = append(lines, "- "+sub.ToString())
+			}
+		}
+		if len(s.subscriptions) == 0 {
+			lines = []string{"Este chat nao assina nenhum relatório"}
+		}
+
+		var row []tgbotapi.InlineKeyboardButton
+		for _, f := range models.ReportFrequencies {
+			label := string(f)
+			if s.subscription(f) != nil {
+				label = cancelSubscriptionPrefix + label
+			}
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, label))
+		}
+		s.step = stepGetReportSubscriptionAction
+		return strings.Join(lines, "\n") + "\nQual relatório deseja assinar ou cancelar?", tgbotapi.NewInlineKeyboardMarkup(row)
+	case stepGetReportSubscriptionAction:
+		if frequency, ok := models.ParseReportFrequency(strings.TrimPrefix(answer, cancelSubscriptionPrefix)); ok {
+			if sub := s.subscription(frequency); sub != nil && strings.HasPrefix(answer, cancelSubscriptionPrefix) {
+				s.step = stepEnd
+				if err := s.store.RemoveReportSubscription(sub); err != nil {
+					return fmt.Sprintf("Falha ao cancelar a assinatura - %v", err.Error()), nil
+				}
+				return fmt.Sprintf("Assinatura do relatório %v cancelada", frequency), nil
+			}
+			return s.subscribe(frequency)
+		}
+		return "Selecione uma das opçoes", nil
+	}
+	return "", nil
+}
+
+// Ended tells the conversation is over
+func (s *reportSubscriptionSession) Ended() bool {
+	return s.step == stepEnd
+}
+
+func (s *reportSubscriptionSession) subscription(frequency models.ReportFrequency) *models.ReportSubscription {
+	for _, sub := range s.subscriptions {
+		if sub.Frequency == frequency {
+			return sub
+		}
+	}
+	return nil
+}
+
+// subscribe starts the subscription at the current period, so its report is the first delivered
+func (s *reportSubscriptionSession) subscribe(frequency models.ReportFrequency) (string, interface{}) {
+	s.step = stepEnd
+	current := frequency.CurrentPeriod(s.now())
+	sub := &models.ReportSubscription{
+		ChatId:     s.chatId,
+		Frequency:  frequency,
+		LastPeriod: frequency.PreviousPeriod(current),
+	}
+	if err := s.store.SetReportSubscription(sub); err != nil {
+		return fmt.Sprintf("Falha ao assinar o relatório - %v", err.Error()), nil
+	}
+	return fmt.Sprintf("Relatório %v assinado, o primeiro será enviado em %v", frequency, frequency.NextPeriod(current).Format("02/01/2006")), nil
+}
@@ -0,0 +1,170 @@
+package chat_flow
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+type cleaningScheduleSession struct {
+	store    storage.Store
+	step     Step
+	cleaning *models.ScheduledCleaning
+	cleaners []*models.User
+	keyboard *paginatedKeyboard
+}
+
+// NewCleaningScheduleSession assigns the cleaning of the apartment at the date to one of the cleaners
+func NewCleaningScheduleSession(apartment string, date time.Time, store storage.Store) ChatSession {
+	return &cleaningScheduleSession{
+		store: store,
+		step:  stepBeginCleaningSchedule,
+		cleaning: &models.ScheduledCleaning{
+			Apartment: models.Apartment{Name: apartment},
+			Date:      date,
+		},
+	}
+}
+
+func (s *cleaningScheduleSession) Next(answer string) (string, interface{}) {
+	switch s.step {
+	case stepBeginCleaningSchedule:
+		users, err := s.store.GetUsers()
+		if err != nil {
+			s.step = stepEnd
+			return fmt.Sprintf("Falha ao consultar os usuários - %v", err.Error()), nil
+		}
+		var options []keyboardOption
+		for _, u := range users {
+			if u.Role == models.RoleCleaner {
+				s.cleaners = append(s.cleaners, u)
+				options = append(options, keyboardOption{label: u.Name, data: strconv.FormatInt(u.Id, 10)})
+			}
+		}
+		if len(s.cleaners) == 0 {
+			s.step = stepEnd
+			return fmt.Sprintf("Nenhum usuário com o papel %v, convide um com /convidar", models.RoleCleaner), nil
+		}
+		s.keyboard = newPaginatedKeyboard(options, 2)
+		s.step = stepGetScheduledCleaner
+		return fmt.Sprintf("Quem vai fazer a faxina de %v no dia %v?", s.cleaning.Apartment.Name, s.cleaning.Date.Format("02/01/2006")), s.keyboard.markup()
+	case stepGetScheduledCleaner:
+		if consumed, _ := s.keyboard.navigate(answer); consumed {
+			option, ok := s.keyboard.only()
+			if !ok {
+				return "Selecione quem vai fazer a faxina", s.keyboard.markup()
+			}
+			answer = option.data
+		}
+		for _, u := range s.cleaners {
+			if strconv.FormatInt(u.Id, 10) == answer {
+				s.cleaning.CleanerId, s.cleaning.CleanerName = u.Id, u.Name
+				s.step = stepGetScheduledCleaningPayer
+				return "Quem vai pagar pela faxina?", assembleKeyboardMenuWithPayers(s.store)
+			}
+		}
+		return "Selecione quem vai fazer a faxina", s.keyboard.markup()
+	case stepGetScheduledCleaningPayer:
+		s.cleaning.Payer = answer
+		s.step = stepEnd
+		if err := s.store.AddScheduledCleaning(s.cleaning); err != nil {
+			return fmt.Sprintf("Falha ao agendar a faxina - %v", err.Error()), nil
+		}
+		return fmt.Sprintf("Faxina agendada: %v", s.cleaning.ToString()), nil
+	}
+	return "", nil
+}
+
+// Ended tells the conversation is over
+func (s *cleaningScheduleSession) Ended() bool {
+	return s.step == stepEnd
+}
+
+type scheduledCleaningsSession struct {
+	store storage.Store
+	step  Step
+	// cleanerId restricts the session to the cleanings assigned to a cleaner, zero shows every cleaning
+	cleanerId int64
+	cleanings []*models.ScheduledCleaning
+	cleaning  *models.ScheduledCleaning
+	keyboard  *paginatedKeyboard
+}
+
+// NewScheduledCleaningsSession registers a scheduled cleaning once it is done, with its value
+func NewScheduledCleaningsSession(cleanerId int64, store storage.Store) ChatSession {
+	return &scheduledCleaningsSession{
+		store:     store,
+		step:      stepBeginScheduledCleanings,
+		cleanerId: cleanerId,
+	}
+}
+
+func (s *scheduledCleaningsSession) Next(answer string) (string, interface{}) {
+	switch s.step {
+	case stepBeginScheduledCleanings:
+		cleanings, err := s.store.GetScheduledCleanings()
+		if err != nil {
+			s.step = stepEnd
+			return fmt.Sprintf("Falha ao consultar as faxinas agendadas - %v", err.Error()), nil
+		}
+		for _, c := range cleanings {
+			if s.cleanerId == 0 || c.CleanerId == s.cleanerId {
+				s.cleanings = append(s.cleanings, c)
+			}
+		}
+		if len(s.cleanings) == 0 {
+			s.step = stepEnd
+			return "Nenhuma faxina agendada", nil
+		}
+		sort.SliceStable(s.cleanings, func(i, j int) bool {
+			return s.cleanings[i].Date.Before(s.cleanings[j].Date)
+		})
+
+		options := make([]keyboardOption, 0, len(s.cleanings))
+		for i, c := range s.cleanings {
+			options = append(options, keyboardOption{label: c.ToString(), data: strconv.Itoa(i)})
+		}
+		s.keyboard = newPaginatedKeyboard(options, 1)
+		s.step = stepGetScheduledCleaning
+		return "Qual faxina foi feita?", s.keyboard.markup()
+	case stepGetScheduledCleaning:
+		if consumed, _ := s.keyboard.navigate(answer); consumed {
+			option, ok := s.keyboard.only()
+			if !ok {
+				return "Qual faxina foi feita?", s.keyboard.markup()
+			}
+			answer = option.data
+		}
+		i, err := strconv.Atoi(answer)
+		if err != nil || i < 0 || i >= len(s.cleanings) {
+			return "Selecione uma das faxinas da lista", s.keyboard.markup()
+		}
+		s.cleaning = s.cleanings[i]
+		s.step = stepGetScheduledCleaningValue
+		return "Qual o valor da faxina?", nil
+	case stepGetScheduledCleaningValue:
+		value, err := parsePriceFromStr(answer)
+		if err != nil {
+			return err.Error(), nil
+		}
+		c := &models.Cleaning{Date: s.cleaning.Date, Value: value, Payer: s.cleaning.Payer, Apartment: s.cleaning.Apartment}
+		s.step = stepEnd
+		if err := s.store.AddCleaning(c); err != nil {
+			return fmt.Sprintf("Falha ao registrar a faxina %v - %v", c.ToString(), err.Error()), nil
+		}
+		if err := s.store.RemoveScheduledCleaning(s.cleaning); err != nil {
+			return fmt.Sprintf("Faxina registrada, mas nao foi possível retirá-la da agenda - %v", err.Error()), nil
+		}
+		return fmt.Sprintf("Faxina registrada: %v", c.ToString()), nil
+	}
+	return "", nil
+}
+
+// Ended tells the conversation is over
+func (s *scheduledCleaningsSession) Ended() bool {
+	return s.step == stepEnd
+}
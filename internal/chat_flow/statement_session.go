@@ -0,0 +1,73 @@
+package chat_flow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/report"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+type statementSession struct {
+	apartmentSelector
+	store  storage.Store
+	step   Step
+	period *report.Period
+}
+
+// NewStatementSession sends the PDF statement of an apartment in a month or a year, the previous month unless
+// given as in /demonstrativo 03/2024 or /demonstrativo 2024
+func NewStatementSession(store storage.Store) ChatSession {
+	selector := newApartmentSelector(store)
+	selector.includeArchived = true
+	return &statementSession{
+		apartmentSelector: selector,
+		store:             store,
+		step:              stepBeginStatement,
+	}
+}
+
+func (s *statementSession) Next(answer string) (string, interface{}) {
+	if s.period == nil {
+		period, err := report.ParsePeriod(answer, time.Now())
+		if err != nil {
+			s.step = stepEnd
+			return err.Error(), nil
+		}
+		s.period = period
+	}
+	replyText, markup := s.next(answer)
+	return s.withApartmentName(replyText), markup
+}
+
+// Ended tells the conversation is over
+func (s *statementSession) Ended() bool {
+	return s.step == stepEnd
+}
+
+func (s *statementSession) next(answer string) (string, interface{}) {
+	if !s.apartmentSelected() {
+		replyText, markup, failed := s.selectApartment(answer)
+		if failed {
+			s.step = stepEnd
+		}
+		if !s.apartmentSelected() {
+			return replyText, markup
+		}
+	}
+
+	if s.step != stepBeginStatement {
+		return "", nil
+	}
+	s.step = stepEnd
+
+	statement, err := report.BuildStatement(s.store, models.Apartment{Name: s.apartmentName}, s.period)
+	if err != nil {
+		return fmt.Sprintf("Falha ao montar o demonstrativo - %v", err.Error()), nil
+	}
+	return fmt.Sprintf("Demonstrativo de %v", s.period.Label), Document{
+		Name: fmt.Sprintf("demonstrativo-%v-%v.pdf", s.apartmentName, s.period.Begin.Format("2006-01")),
+		Data: statement.PDF(),
+	}
+}
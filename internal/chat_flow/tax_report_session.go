@@ -0,0 +1,68 @@
+package chat_flow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+	"github.com/gustavolopess/hoteleiro/internal/tax"
+)
+
+const (
+	csvFormatAnswer = "CSV"
+	pdfFormatAnswer = "PDF"
+)
+
+type taxReportSession struct {
+	store  storage.Store
+	step   Step
+	report *tax.Report
+}
+
+// NewTaxReportSession builds the carnê-leão of each partner in a year, the previous one unless given as in
+// /irpf 2024, and sends it as CSV or PDF
+func NewTaxReportSession(store storage.Store) ChatSession {
+	return &taxReportSession{
+		store: store,
+		step:  stepGetTaxReportFormat,
+	}
+}
+
+func (s *taxReportSession) Next(answer string) (string, interface{}) {
+	if s.report == nil {
+		year := time.Now().Year() - 1
+		if y, err := strconv.Atoi(strings.TrimSpace(answer)); err == nil && y > 1900 {
+			year = y
+		}
+		report, err := tax.Build(s.store, year)
+		if err != nil {
+			s.step = stepEnd
+			return fmt.Sprintf("Falha ao montar o carnê-leao - %v", err.Error()), nil
+		}
+		s.report = report
+		return report.Format() + "\nEm qual formato deseja o relatório?", tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(csvFormatAnswer, csvFormatAnswer),
+			tgbotapi.NewInlineKeyboardButtonData(pdfFormatAnswer, pdfFormatAnswer),
+		))
+	}
+
+	name := fmt.Sprintf("carne-leao-%d", s.report.Year)
+	switch answer {
+	case csvFormatAnswer:
+		s.step = stepEnd
+		return fmt.Sprintf("Carnê-leao de %d", s.report.Year), Document{Name: name + ".csv", Data: s.report.CSV()}
+	case pdfFormatAnswer:
+		s.step = stepEnd
+		return fmt.Sprintf("Carnê-leao de %d", s.report.Year), Document{Name: name + ".pdf", Data: s.report.PDF()}
+	}
+	return "Selecione um dos formatos", nil
+}
+
+// Ended tells the conversation is over
+func (s *taxReportSession) Ended() bool {
+	return s.step == stepEnd
+}
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 const (
 	AwsRegion                   = "us-east-2"
 	AwsProfile                  = "default"
@@ -9,4 +11,14 @@ const (
 	GoogleSheetsCredentialsInS3 = "credentials.json"
 	GoogleSheetsTokenInS3       = "token.json"
 	S3Bucket                    = "hoteleiro-bot2"
+	AttachmentsLocalDir         = "" // when set, attachments are kept in this directory instead of S3
+	AttachmentsS3Prefix         = "anexos/"
+	HttpServerAddr              = ":8080"
+	HttpTLSCertFile             = "" // when set, HTTP is served over TLS, otherwise a proxy must terminate it
+	HttpTLSKeyFile              = ""
+	CalendarFeedBaseURL         = "https://hoteleiro.gustavolopess.com" // feeds are disabled unless it is https
+	SchedulerInterval           = time.Hour
+	ReminderDaysBefore          = 3
+	ReminderSnooze              = 24 * time.Hour
+	DigestHour                  = 8 // hour of the day the check-ins and check-outs are sent
 )
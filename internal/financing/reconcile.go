@@ -0,0 +1,103 @@
+package financing
+
+import (
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+// Status is where a financing stands according to the installments and amortizations recorded
+type Status struct {
+	Schedule []*Installment
+	// PaidInstallments are the installments recorded since the start of the contract, DueInstallments the ones due
+	// until the date of the status
+	PaidInstallments int
+	DueInstallments  int
+	// PaidValue is the sum of the installments recorded, ScheduledValue the one the schedule expected for them
+	PaidValue      float64
+	ScheduledValue float64
+	ExtraValue     float64
+	Balance        float64
+	RemainingTerm  int
+	// Payments are the installments recorded, each with the one of the schedule it pays
+	Payments []*Payment
+	// UncorrectedMonths are the months of the installments due until the date without a value of the index of the
+	// contract, corrected by 0%
+	UncorrectedMonths []time.Time
+}
+
+// Payment is an installment recorded, Installment being nil when it is beyond the schedule
+type Payment struct {
+	Date        time.Time
+	Value       float64
+	Installment *Installment
+}
+
+// NextInstallment is the first installment not paid yet, nil once the financing is paid off
+func (s *Status) NextInstallment() *Installment {
+	if s.PaidInstallments >= len(s.Schedule) {
+		return nil
+	}
+	return s.Schedule[s.PaidInstallments]
+}
+
+// Extras turns the amortizations recorded into extra amortizations of the schedule, by the options chosen for them.
+// Amortizations without an option reduce the term
+func Extras(amortizations []*models.Amortization) []Extra {
+	var extras []Extra
+	for _, a := range amortizations {
+		extras = append(extras, Extra{Date: a.Date, Value: a.Value, ReduceTerm: a.Option != models.ReduceInstallment})
+	}
+	return extras
+}
+
+// Reconcile matches the installments and extra amortizations recorded against the schedule of the contract at the
+// date, each installment recorded paying the earliest one of the schedule still open
+func Reconcile(c *models.FinancingContract, corrections Corrections, installments []*models.FinancingInstallment, allExtras []Extra, date time.Time) *Status {
+	var extras []Extra
+	for _, e := range allExtras {
+		if !e.Date.Before(c.Start.AddDate(0, -1, 0)) {
+			extras = append(extras, e)
+		}
+	}
+
+	status := &Status{Schedule: Schedule(c, corrections, extras), Balance: c.Principal}
+	for _, e := range extras {
+		status.ExtraValue += e.Value
+	}
+	for _, inst := range status.Schedule {
+		if !inst.Date.After(date) {
+			status.DueInstallments++
+			if _, ok := corrections[monthStart(inst.Date)]; c.Index != models.IndexNone && !ok {
+				status.UncorrectedMonths = append(status.UncorrectedMonths, monthStart(inst.Date))
+			}
+		}
+	}
+
+	for _, i := range installments {
+		// installments paid in advance are recorded some days before their due date
+		if i.Date.Before(c.Start.AddDate(0, -1, 0)) {
+			continue
+		}
+		status.PaidValue += i.Value
+		payment := &Payment{Date: i.Date, Value: i.Value}
+		if status.PaidInstallments < len(status.Schedule) {
+			payment.Installment = status.Schedule[status.PaidInstallments]
+			status.ScheduledValue += payment.Installment.Payment
+			status.PaidInstallments++
+		}
+		status.Payments = append(status.Payments, payment)
+	}
+
+	if status.PaidInstallments > 0 {
+		status.Balance = status.Schedule[status.PaidInstallments-1].Balance
+	} else {
+		for _, e := range extras {
+			if !e.Date.After(date) {
+				status.Balance -= e.Value
+			}
+		}
+	}
+	status.RemainingTerm = len(status.Schedule) - status.PaidInstallments
+	return status
+}
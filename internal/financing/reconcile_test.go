@@ -0,0 +1,59 @@
+package financing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+func TestBundledSeries(t *testing.T) {
+	tests := []struct {
+		index      models.CorrectionIndex
+		begin, end time.Time
+	}{
+		{models.IndexIPCA, time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		// the months the TR was zero
+		{models.IndexTR, time.Date(2017, time.September, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		values, err := BundledSeries(tt.index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		corrections := NewCorrections(values)
+		for month := tt.begin; month.Before(tt.end); month = month.AddDate(0, 1, 0) {
+			if _, ok := corrections[month]; !ok {
+				t.Errorf("%v of %v missing", tt.index, month.Format("01/2006"))
+			}
+		}
+	}
+}
+
+func TestReconcileUncorrectedMonths(t *testing.T) {
+	c := contract(models.SystemSAC, 12)
+	c.Index = models.IndexTR
+	corrections := Corrections{
+		time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC):  0.001,
+		time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC): 0.001,
+	}
+	status := Reconcile(c, corrections, nil, nil, time.Date(2024, time.April, 20, 0, 0, 0, 0, time.UTC))
+
+	want := []time.Time{
+		time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if len(status.UncorrectedMonths) != len(want) {
+		t.Fatalf("uncorrected months %v, want %v", status.UncorrectedMonths, want)
+	}
+	for i, m := range want {
+		if !status.UncorrectedMonths[i].Equal(m) {
+			t.Errorf("uncorrected month %d is %v, want %v", i, status.UncorrectedMonths[i], m)
+		}
+	}
+
+	c.Index = models.IndexNone
+	if status := Reconcile(c, nil, nil, nil, time.Date(2024, time.April, 20, 0, 0, 0, 0, time.UTC)); len(status.UncorrectedMonths) > 0 {
+		t.Errorf("contract without index has uncorrected months %v", status.UncorrectedMonths)
+	}
+}
@@ -0,0 +1,162 @@
+package financing
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+// balances below it are rounding leftovers of a financing paid off
+const paidOffBalance = 0.01
+
+// Corrections are the monthly variations of a correction index, as fractions, by the first day of their month
+type Corrections map[time.Time]float64
+
+// Installment is a month of the schedule of a financing
+type Installment struct {
+	Number       int
+	Date         time.Time
+	Payment      float64
+	Amortization float64
+	Interest     float64
+	// Correction is the monetary correction added to the balance before the installment
+	Correction float64
+	// Extra is the amortization paid besides the installment, until the next one
+	Extra float64
+	// Balance is owed after the installment and the extra amortizations
+	Balance float64
+}
+
+// Extra is an amortization paid besides the installments, which either shortens the financing or lowers its
+// following installments
+type Extra struct {
+	Date       time.Time
+	Value      float64
+	ReduceTerm bool
+}
+
+// MonthlyRate converts the effective yearly rate of the contract, in percent, to the monthly one
+func MonthlyRate(c *models.FinancingContract) float64 {
+	return math.Pow(1+c.AnnualRate/100, 1.0/12) - 1
+}
+
+// Schedule calculates every installment of the contract, correcting the balance by the corrections of each month
+// and applying the extra amortizations after the installment due before them
+func Schedule(c *models.FinancingContract, corrections Corrections, extras []Extra) []*Installment {
+	extras = append([]Extra(nil), extras...)
+	sort.Slice(extras, func(i, j int) bool { return extras[i].Date.Before(extras[j].Date) })
+
+	rate := MonthlyRate(c)
+	balance := c.Principal
+	remaining := c.Term
+	var schedule []*Installment
+	for number := 1; remaining > 0 && balance > paidOffBalance; number++ {
+		date := c.InstallmentDate(number)
+		inst := &Installment{Number: number, Date: date}
+
+		// extra amortizations paid before the first installment
+		for len(extras) > 0 && number == 1 && extras[0].Date.Before(date) && remaining > 0 {
+			inst.Extra += math.Min(extras[0].Value, balance)
+			balance, remaining = applyExtra(c.System, rate, balance, remaining, extras[0])
+			extras = extras[1:]
+		}
+		// paid off before the first installment, which is then only the payoff
+		if remaining == 0 || balance <= paidOffBalance {
+			inst.Balance = 0
+			schedule = append(schedule, inst)
+			break
+		}
+
+		inst.Correction = balance * corrections[monthStart(date)]
+		balance += inst.Correction
+		inst.Interest = balance * rate
+		if c.System == models.SystemPrice {
+			inst.Payment = payment(balance, rate, remaining)
+			inst.Amortization = inst.Payment - inst.Interest
+		} else {
+			inst.Amortization = balance / float64(remaining)
+			inst.Payment = inst.Amortization + inst.Interest
+		}
+		balance -= inst.Amortization
+		remaining--
+
+		next := c.InstallmentDate(number + 1)
+		for len(extras) > 0 && extras[0].Date.Before(next) && remaining > 0 {
+			inst.Extra += math.Min(extras[0].Value, balance)
+			balance, remaining = applyExtra(c.System, rate, balance, remaining, extras[0])
+			extras = extras[1:]
+		}
+
+		inst.Balance = math.Max(balance, 0)
+		schedule = append(schedule, inst)
+	}
+
+	return schedule
+}
+
+// applyExtra lowers the balance keeping the value of the following installments when the term is reduced, or
+// keeping the term otherwise. An extra paying off the balance leaves no installments
+func applyExtra(system models.AmortizationSystem, rate, balance float64, remaining int, e Extra) (float64, int) {
+	if e.Value >= balance {
+		return 0, 0
+	}
+	if !e.ReduceTerm {
+		return balance - e.Value, remaining
+	}
+
+	var months float64
+	if system == models.SystemPrice {
+		p := payment(balance, rate, remaining)
+		months = termOf(balance-e.Value, rate, p)
+	} else {
+		months = (balance - e.Value) / (balance / float64(remaining))
+	}
+	// tolerates the rounding of exact divisions
+	return balance - e.Value, int(math.Ceil(months - 1e-9))
+}
+
+// payment is the constant installment paying off the balance in the remaining months
+func payment(balance, rate float64, remaining int) float64 {
+	if rate == 0 {
+		return balance / float64(remaining)
+	}
+	return balance * rate / (1 - math.Pow(1+rate, -float64(remaining)))
+}
+
+// termOf is the number of months the installment takes to pay off the balance
+func termOf(balance, rate, installment float64) float64 {
+	if rate == 0 {
+		return balance / installment
+	}
+	return -math.Log(1-balance*rate/installment) / math.Log(1+rate)
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// ScheduleCSV writes the schedule as a spreadsheet, values with comma decimals as the sheets of the store
+func ScheduleCSV(schedule []*Installment) []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = ';'
+	_ = w.Write([]string{"Parcela", "Vencimento", "Prestaçao", "Amortizaçao", "Juros", "Correçao", "Amortizaçao extra", "Saldo devedor"})
+	for _, i := range schedule {
+		_ = w.Write([]string{
+			strconv.Itoa(i.Number), i.Date.Format("02/01/2006"), decimal(i.Payment), decimal(i.Amortization),
+			decimal(i.Interest), decimal(i.Correction), decimal(i.Extra), decimal(i.Balance),
+		})
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+func decimal(v float64) string {
+	return strings.Replace(strconv.FormatFloat(v, 'f', 2, 64), ".", ",", 1)
+}
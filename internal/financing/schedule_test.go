@@ -0,0 +1,135 @@
+package financing
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+func contract(system models.AmortizationSystem, rate float64) *models.FinancingContract {
+	return &models.FinancingContract{
+		Principal:  12000,
+		AnnualRate: rate,
+		Term:       12,
+		System:     system,
+		Start:      time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
+
+// checkSchedule verifies every installment is a number and the amortizations pay off the principal
+func checkSchedule(t *testing.T, c *models.FinancingContract, schedule []*Installment) {
+	t.Helper()
+	if len(schedule) == 0 {
+		t.Fatal("empty schedule")
+	}
+	var amortized float64
+	for _, i := range schedule {
+		for _, v := range []float64{i.Payment, i.Amortization, i.Interest, i.Extra, i.Balance} {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				t.Fatalf("installment %d has an invalid value: %+v", i.Number, i)
+			}
+		}
+		amortized += i.Amortization + i.Extra
+	}
+	if !near(amortized, c.Principal) {
+		t.Errorf("amortized %.2f, want %.2f", amortized, c.Principal)
+	}
+	if last := schedule[len(schedule)-1]; !near(last.Balance, 0) {
+		t.Errorf("final balance %.2f, want 0", last.Balance)
+	}
+}
+
+func TestScheduleSAC(t *testing.T) {
+	c := contract(models.SystemSAC, 12)
+	schedule := Schedule(c, nil, nil)
+	checkSchedule(t, c, schedule)
+
+	if len(schedule) != c.Term {
+		t.Fatalf("%d installments, want %d", len(schedule), c.Term)
+	}
+	rate := MonthlyRate(c)
+	for _, i := range schedule {
+		if !near(i.Amortization, 1000) {
+			t.Errorf("installment %d amortizes %.2f, want 1000", i.Number, i.Amortization)
+		}
+	}
+	if !near(schedule[0].Interest, 12000*rate) {
+		t.Errorf("first interest %.2f, want %.2f", schedule[0].Interest, 12000*rate)
+	}
+	if schedule[1].Payment >= schedule[0].Payment {
+		t.Errorf("installments should decrease: %.2f then %.2f", schedule[0].Payment, schedule[1].Payment)
+	}
+}
+
+func TestSchedulePrice(t *testing.T) {
+	c := contract(models.SystemPrice, 12)
+	schedule := Schedule(c, nil, nil)
+	checkSchedule(t, c, schedule)
+
+	if len(schedule) != c.Term {
+		t.Fatalf("%d installments, want %d", len(schedule), c.Term)
+	}
+	want := payment(c.Principal, MonthlyRate(c), c.Term)
+	for _, i := range schedule {
+		if !near(i.Payment, want) {
+			t.Errorf("installment %d is %.2f, want %.2f", i.Number, i.Payment, want)
+		}
+	}
+}
+
+func TestScheduleExtras(t *testing.T) {
+	afterSecond := time.Date(2024, time.February, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		system models.AmortizationSystem
+		extra  Extra
+		// term is the number of installments of the schedule
+		term int
+	}{
+		{"SAC reducing the term", models.SystemSAC, Extra{Date: afterSecond, Value: 3000, ReduceTerm: true}, 9},
+		{"SAC reducing the installments", models.SystemSAC, Extra{Date: afterSecond, Value: 3000}, 12},
+		{"Price reducing the term", models.SystemPrice, Extra{Date: afterSecond, Value: 3000, ReduceTerm: true}, 9},
+		{"Price reducing the installments", models.SystemPrice, Extra{Date: afterSecond, Value: 3000}, 12},
+		{"SAC paid off", models.SystemSAC, Extra{Date: afterSecond, Value: 50000}, 2},
+		{"Price paid off", models.SystemPrice, Extra{Date: afterSecond, Value: 50000, ReduceTerm: true}, 2},
+		{"SAC paid off before the first installment", models.SystemSAC, Extra{Date: time.Date(2024, time.January, 5, 0, 0, 0, 0, time.UTC), Value: 12000}, 1},
+		{"Price paid off before the first installment", models.SystemPrice, Extra{Date: time.Date(2024, time.January, 5, 0, 0, 0, 0, time.UTC), Value: 50000}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := contract(tt.system, 12)
+			schedule := Schedule(c, nil, []Extra{tt.extra})
+			checkSchedule(t, c, schedule)
+
+			if len(schedule) != tt.term {
+				t.Errorf("%d installments, want %d", len(schedule), tt.term)
+			}
+			var extra float64
+			for _, i := range schedule {
+				extra += i.Extra
+			}
+			if extra > tt.extra.Value+0.01 {
+				t.Errorf("extras sum %.2f, more than the %.2f paid", extra, tt.extra.Value)
+			}
+		})
+	}
+}
+
+func TestScheduleReducedInstallments(t *testing.T) {
+	c := contract(models.SystemPrice, 12)
+	base := Schedule(c, nil, nil)
+	reduced := Schedule(c, nil, []Extra{{Date: time.Date(2024, time.February, 15, 0, 0, 0, 0, time.UTC), Value: 3000}})
+
+	if !near(reduced[1].Payment, base[1].Payment) {
+		t.Errorf("installment before the extra is %.2f, want %.2f", reduced[1].Payment, base[1].Payment)
+	}
+	if reduced[2].Payment >= base[2].Payment {
+		t.Errorf("installment after the extra is %.2f, should be lower than %.2f", reduced[2].Payment, base[2].Payment)
+	}
+}
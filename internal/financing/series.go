@@ -0,0 +1,35 @@
+package financing
+
+import (
+	"bytes"
+	"embed"
+
+	"github.com/gustavolopess/hoteleiro/internal/importer"
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+// bundled holds a CSV per index, in the format of the SGS exports of the Banco Central, so the financing is
+// corrected offline. The values imported to the store take precedence over them
+//
+//go:embed series/*.csv
+var bundled embed.FS
+
+// BundledSeries returns the monthly variations of the index shipped with the bot
+func BundledSeries(index models.CorrectionIndex) ([]*models.IndexValue, error) {
+	data, err := bundled.ReadFile("series/" + string(index) + ".csv")
+	if err != nil {
+		return nil, err
+	}
+	return importer.ParseIndexSeries(bytes.NewReader(data), index)
+}
+
+// NewCorrections turns the variations into corrections, the ones of the later series replacing the earlier
+func NewCorrections(series ...[]*models.IndexValue) Corrections {
+	corrections := make(Corrections)
+	for _, values := range series {
+		for _, v := range values {
+			corrections[monthStart(v.Month)] = v.Variation / 100
+		}
+	}
+	return corrections
+}
@@ -0,0 +1,51 @@
+package financing
+
+import (
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+// Outlook is how a schedule goes on after a date
+type Outlook struct {
+	Option models.AmortizationOption
+	// Interest is the total interest of the schedule, InterestSaved the difference to the schedule without the
+	// simulated amortization
+	Interest      float64
+	InterestSaved float64
+	End           time.Time
+	// Payment is the first installment due after the date, RemainingTerm counts it and the ones after it
+	Payment       float64
+	RemainingTerm int
+}
+
+// Simulate compares the schedule of the contract with the extras already paid to the ones with an extra amortization
+// of the value at the date, reducing the term or the installments
+func Simulate(c *models.FinancingContract, corrections Corrections, extras []Extra, value float64, date time.Time) (*Outlook, []*Outlook) {
+	current := outlook(Schedule(c, corrections, extras), date)
+
+	var simulations []*Outlook
+	for _, option := range models.AmortizationOptions {
+		simulated := append(append([]Extra(nil), extras...), Extra{Date: date, Value: value, ReduceTerm: option == models.ReduceTerm})
+		o := outlook(Schedule(c, corrections, simulated), date)
+		o.Option = option
+		o.InterestSaved = current.Interest - o.Interest
+		simulations = append(simulations, o)
+	}
+	return current, simulations
+}
+
+func outlook(schedule []*Installment, date time.Time) *Outlook {
+	o := &Outlook{}
+	for _, inst := range schedule {
+		o.Interest += inst.Interest
+		o.End = inst.Date
+		if inst.Date.After(date) {
+			if o.RemainingTerm == 0 {
+				o.Payment = inst.Payment
+			}
+			o.RemainingTerm++
+		}
+	}
+	return o
+}
@@ -0,0 +1,65 @@
+package financing
+
+import (
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// FindContract returns the financing of the apartment, nil when it has none
+func FindContract(store storage.Store, apartmentName string) (*models.FinancingContract, error) {
+	contracts, err := store.GetFinancingContracts()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range contracts {
+		if c.Apartment.Name == apartmentName {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+// StoredStatus matches the installments and amortizations recorded in the apartment against the contract
+func StoredStatus(store storage.Store, contract *models.FinancingContract, date time.Time) (*Status, error) {
+	installments, err := store.GetPayedFinancialInstallments(contract.Apartment)
+	if err != nil {
+		return nil, err
+	}
+	extras, err := StoredExtras(store, contract)
+	if err != nil {
+		return nil, err
+	}
+	corrections, err := StoredCorrections(store, contract)
+	if err != nil {
+		return nil, err
+	}
+	return Reconcile(contract, corrections, installments, extras, date), nil
+}
+
+// StoredCorrections returns the monthly corrections of the index of the contract, the values imported to the store
+// replacing the bundled ones
+func StoredCorrections(store storage.Store, contract *models.FinancingContract) (Corrections, error) {
+	if contract.Index == models.IndexNone {
+		return nil, nil
+	}
+	bundled, err := BundledSeries(contract.Index)
+	if err != nil {
+		return nil, err
+	}
+	imported, err := store.GetIndexValues(contract.Index)
+	if err != nil {
+		return nil, err
+	}
+	return NewCorrections(bundled, imported), nil
+}
+
+// StoredExtras returns the amortizations recorded in the apartment as extras of its schedule
+func StoredExtras(store storage.Store, contract *models.FinancingContract) ([]Extra, error) {
+	amortizations, err := store.GetPayedAmortizations(contract.Apartment)
+	if err != nil {
+		return nil, err
+	}
+	return Extras(amortizations), nil
+}
@@ -0,0 +1,28 @@
+package format
+
+import (
+	"strings"
+)
+
+// MaxCallbackDataLength is the limit of bytes Telegram accepts as the data of an inline keyboard button. Names typed
+// freely by the users, like the ones of the apartments or of the Airbnb listings, may not fit, so the answers carry
+// short identifiers instead, like the id of the apartment or the index of the listing
+const MaxCallbackDataLength = 64
+
+// CallbackData joins the fields of a keyboard answer after the prefix which tells who handles it
+func CallbackData(prefix string, fields ...string) string {
+	return prefix + strings.Join(fields, ":")
+}
+
+// ParseCallbackData splits the answer built by CallbackData, reporting false when it does not have the prefix or the
+// number of fields expected
+func ParseCallbackData(answer, prefix string, fields int) ([]string, bool) {
+	if !strings.HasPrefix(answer, prefix) {
+		return nil, false
+	}
+	parts := strings.Split(strings.TrimPrefix(answer, prefix), ":")
+	if len(parts) != fields {
+		return nil, false
+	}
+	return parts, true
+}
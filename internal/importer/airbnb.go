@@ -0,0 +1,267 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+// AirbnbReservation is a reservation line of the transaction history exported by Airbnb
+type AirbnbReservation struct {
+	ConfirmationCode string
+	DateBegin        time.Time
+	Nights           int
+	Guest            string
+	Listing          string
+	Amount           float64
+	ServiceFee       float64
+	CleaningFee      float64
+	GrossEarnings    float64
+	// BookedAt is zero when the export has no booking date column
+	BookedAt time.Time
+	// PaidAt is when Airbnb paid the reservation out, zero when the export has no date column
+	PaidAt time.Time
+}
+
+// DateEnd is the checkout date of the reservation
+func (a *AirbnbReservation) DateEnd() time.Time {
+	return a.DateBegin.AddDate(0, 0, a.Nights)
+}
+
+// Rent maps the reservation to a rent, valued by its gross earnings as the service fee is registered as an expense.
+// The cleaning fee is part of the gross earnings and is kept apart in the rent too
+func (a *AirbnbReservation) Rent(apartment models.Apartment, receiver string) *models.Rent {
+	return &models.Rent{
+		DateBegin:   a.DateBegin,
+		DateEnd:     a.DateEnd(),
+		Value:       a.GrossEarnings,
+		Renter:      a.Guest,
+		Receiver:    receiver,
+		BookedAt:    a.BookedAt,
+		CleaningFee: a.CleaningFee,
+		ReceivedAt:  a.PaidAt,
+		Apartment:   apartment,
+	}
+}
+
+// ServiceFeeExpense maps the service fee charged by Airbnb to an expense, withheld when the reservation is paid out.
+// It is nil if the reservation had no fee
+func (a *AirbnbReservation) ServiceFeeExpense(apartment models.Apartment, payer string) *models.MiscellaneousExpense {
+	if a.ServiceFee == 0 {
+		return nil
+	}
+	date := a.PaidAt
+	if date.IsZero() {
+		date = a.DateBegin
+	}
+	return &models.MiscellaneousExpense{
+		Value:       a.ServiceFee,
+		Date:        date,
+		Description: fmt.Sprintf("Taxa de serviço Airbnb %s", a.ConfirmationCode),
+		Payer:       payer,
+		Category:    models.CategoryServiceFee,
+		Apartment:   apartment,
+	}
+}
+
+func (a *AirbnbReservation) ToString() string {
+	return fmt.Sprintf("%s: %s, do dia %v ao dia %v, repasse de R$%.2f (taxa de serviço R$%.2f, taxa de limpeza R$%.2f)",
+		a.ConfirmationCode, a.Guest, a.DateBegin.Format("02/01/2006"), a.DateEnd().Format("02/01/2006"),
+		a.Amount, a.ServiceFee, a.CleaningFee)
+}
+
+var airbnbColumns = struct {
+	kind, paid, code, booked, start, nights, guest, listing, amount, serviceFee, cleaningFee, gross []string
+}{
+	kind:        []string{"Type", "Tipo"},
+	paid:        []string{"Date", "Data"},
+	code:        []string{"Confirmation Code", "Código de confirmação"},
+	booked:      []string{"Booking Date", "Data da reserva"},
+	start:       []string{"Start Date", "Data de início"},
+	nights:      []string{"Nights", "Noites"},
+	guest:       []string{"Guest", "Hóspede"},
+	listing:     []string{"Listing", "Anúncio"},
+	amount:      []string{"Amount", "Valor"},
+	serviceFee:  []string{"Service Fee", "Taxa de serviço"},
+	cleaningFee: []string{"Cleaning Fee", "Taxa de limpeza"},
+	gross:       []string{"Gross Earnings", "Ganhos brutos"},
+}
+
+// ParseAirbnbCSV reads the reservations of the transaction history CSV exported by Airbnb, ignoring payouts and adjustments
+func ParseAirbnbCSV(r io.Reader) ([]*AirbnbReservation, error) {
+	t, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c := airbnbColumns
+	for _, required := range [][]string{c.kind, c.code, c.start, c.nights, c.guest, c.amount} {
+		if !t.has(required...) {
+			return nil, fmt.Errorf("coluna %q nao encontrada, esse arquivo nao parece ser o histórico de transações do Airbnb", required[0])
+		}
+	}
+
+	// the portuguese export writes dates as dd/mm/yyyy, while the english one as mm/dd/yyyy
+	dateLayout := "01/02/2006"
+	if t.has("Tipo") {
+		dateLayout = "02/01/2006"
+	}
+
+	var reservations []*AirbnbReservation
+	for i, row := range t.rows {
+		kind := strings.ToLower(cell(row, t.column(c.kind...)))
+		if kind != "reservation" && kind != "reserva" {
+			continue
+		}
+
+		line := i + 2
+		start, err := time.Parse(dateLayout, cell(row, t.column(c.start...)))
+		if err != nil {
+			return nil, fmt.Errorf("linha %d: data de início inválida", line)
+		}
+		nights, err := strconv.Atoi(cell(row, t.column(c.nights...)))
+		if err != nil || nights <= 0 {
+			return nil, fmt.Errorf("linha %d: número de noites inválido", line)
+		}
+
+		res := &AirbnbReservation{
+			ConfirmationCode: cell(row, t.column(c.code...)),
+			DateBegin:        start,
+			Nights:           nights,
+			Guest:            cell(row, t.column(c.guest...)),
+			Listing:          cell(row, t.column(c.listing...)),
+		}
+
+		amounts := []struct {
+			dst     *float64
+			columns []string
+		}{
+			{&res.Amount, c.amount},
+			{&res.ServiceFee, c.serviceFee},
+			{&res.CleaningFee, c.cleaningFee},
+			{&res.GrossEarnings, c.gross},
+		}
+		for _, a := range amounts {
+			v, err := parseAmount(cell(row, t.column(a.columns...)))
+			if err != nil {
+				return nil, fmt.Errorf("linha %d: valor inválido na coluna %q", line, a.columns[0])
+			}
+			*a.dst = math.Abs(v)
+		}
+		if booked := cell(row, t.column(c.booked...)); len(booked) > 0 {
+			res.BookedAt, err = time.Parse(dateLayout, booked)
+			if err != nil {
+				return nil, fmt.Errorf("linha %d: data da reserva inválida", line)
+			}
+		}
+		if paid := cell(row, t.column(c.paid...)); len(paid) > 0 {
+			res.PaidAt, err = time.Parse(dateLayout, paid)
+			if err != nil {
+				return nil, fmt.Errorf("linha %d: data inválida", line)
+			}
+		}
+		if res.GrossEarnings == 0 {
+			res.GrossEarnings = res.Amount + res.ServiceFee
+		}
+
+		reservations = append(reservations, res)
+	}
+
+	return reservations, nil
+}
+
+// Listings returns the distinct listings of the reservations, in order of appearance
+func Listings(reservations []*AirbnbReservation) []string {
+	var listings []string
+	seen := make(map[string]bool)
+	for _, r := range reservations {
+		if !seen[r.Listing] {
+			seen[r.Listing] = true
+			listings = append(listings, r.Listing)
+		}
+	}
+	return listings
+}
+
+// AirbnbPreview is the dry-run of an import, splitting the reservations in what would be written and what would be skipped
+type AirbnbPreview struct {
+	New        []*AirbnbReservation
+	Duplicated []*AirbnbReservation
+	Conflicts  []*AirbnbReservation
+}
+
+// PreviewAirbnbImport classifies the reservations of a listing against the rents already registered:
+// a reservation whose check-in and checkout are at most a day apart from the ones of a rent is a duplicate, as
+// rents are often registered by hand with a shifted day, and one that overlaps a rent is a conflict
+func PreviewAirbnbImport(reservations []*AirbnbReservation, listing string, existingRents []*models.Rent) *AirbnbPreview {
+	p := &AirbnbPreview{}
+	var accepted []*AirbnbReservation
+	for _, res := range reservations {
+		if res.Listing != listing {
+			continue
+		}
+
+		switch {
+		case hasRentNear(existingRents, res.DateBegin, res.DateEnd()):
+			p.Duplicated = append(p.Duplicated, res)
+		case overlapsRents(existingRents, res.DateBegin, res.DateEnd()) || overlapsReservations(accepted, res):
+			p.Conflicts = append(p.Conflicts, res)
+		default:
+			accepted = append(accepted, res)
+			p.New = append(p.New, res)
+		}
+	}
+	return p
+}
+
+// Batch assembles the records to be written for the new reservations of the preview
+func (p *AirbnbPreview) Batch(apartment models.Apartment, receiver string) *models.Batch {
+	b := &models.Batch{}
+	for _, res := range p.New {
+		b.Rents = append(b.Rents, res.Rent(apartment, receiver))
+		if fee := res.ServiceFeeExpense(apartment, receiver); fee != nil {
+			b.MiscellaneousExpenses = append(b.MiscellaneousExpenses, fee)
+		}
+	}
+	return b
+}
+
+// duplicateTolerance is how far apart the dates of a reservation and of a rent may be for them to be the same stay
+const duplicateTolerance = 24 * time.Hour
+
+func hasRentNear(rents []*models.Rent, begin, end time.Time) bool {
+	for _, r := range rents {
+		if closeDates(r.DateBegin, begin) && closeDates(r.DateEnd, end) {
+			return true
+		}
+	}
+	return false
+}
+
+func closeDates(a, b time.Time) bool {
+	d := a.Sub(b)
+	return d <= duplicateTolerance && d >= -duplicateTolerance
+}
+
+func overlapsRents(rents []*models.Rent, begin, end time.Time) bool {
+	for _, r := range rents {
+		if begin.Before(r.DateEnd) && r.DateBegin.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlapsReservations(reservations []*AirbnbReservation, res *AirbnbReservation) bool {
+	for _, r := range reservations {
+		if res.DateBegin.Before(r.DateEnd()) && r.DateBegin.Before(res.DateEnd()) {
+			return true
+		}
+	}
+	return false
+}
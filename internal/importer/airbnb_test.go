@@ -0,0 +1,72 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+const airbnbCSV = `Date,Type,Confirmation Code,Booking Date,Start Date,Nights,Guest,Listing,Details,Reference,Currency,Amount,Paid Out,Service Fee,Cleaning Fee,Gross Earnings
+10/18/2022,Reservation,HMABCD1234,09/30/2022,10/20/2022,3,Maria Souza,Apto 101,,,BRL,"1,150.00",,-50.00,150.00,"1,200.00"
+10/18/2022,Payout,,,,,,,,,BRL,,"1,150.00",,,
+11/01/2022,Reservation,HMEFGH5678,10/10/2022,11/01/2022,4,Joao Lima,Apto 101,,,BRL,800.00,,-40.00,100.00,840.00
+`
+
+func TestParseAirbnbCSV(t *testing.T) {
+	reservations, err := ParseAirbnbCSV(strings.NewReader(airbnbCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reservations) != 2 {
+		t.Fatalf("got %d reservations, want 2", len(reservations))
+	}
+
+	r := reservations[0]
+	if r.ConfirmationCode != "HMABCD1234" || r.Nights != 3 || r.Guest != "Maria Souza" {
+		t.Errorf("unexpected reservation %+v", r)
+	}
+	if !r.DateBegin.Equal(time.Date(2022, 10, 20, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("check-in = %v, want 20/10/2022", r.DateBegin)
+	}
+	if r.Amount != 1150 || r.ServiceFee != 50 || r.CleaningFee != 150 || r.GrossEarnings != 1200 {
+		t.Errorf("amounts = %v, %v, %v, %v", r.Amount, r.ServiceFee, r.CleaningFee, r.GrossEarnings)
+	}
+	if rent := r.Rent(models.Apartment{Name: "Apto 101"}, "Gustavo"); rent.CleaningFee != 150 {
+		t.Errorf("rent cleaning fee = %v, want 150", rent.CleaningFee)
+	}
+
+	paid := time.Date(2022, 10, 18, 0, 0, 0, 0, time.UTC)
+	if rent := r.Rent(models.Apartment{Name: "Apto 101"}, "Gustavo"); !rent.ReceiptDate().Equal(paid) {
+		t.Errorf("rent received at %v, want 18/10/2022", rent.ReceiptDate())
+	}
+	fee := r.ServiceFeeExpense(models.Apartment{Name: "Apto 101"}, "Gustavo")
+	if fee.Category != models.CategoryServiceFee || !fee.Date.Equal(paid) {
+		t.Errorf("service fee of category %q at %v, want %q at 18/10/2022", fee.Category, fee.Date, models.CategoryServiceFee)
+	}
+}
+
+func TestPreviewAirbnbImportShiftedDates(t *testing.T) {
+	reservations, err := ParseAirbnbCSV(strings.NewReader(airbnbCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rents := []*models.Rent{
+		// registered by hand a day later than the reservation
+		{DateBegin: time.Date(2022, 10, 21, 0, 0, 0, 0, time.UTC), DateEnd: time.Date(2022, 10, 23, 0, 0, 0, 0, time.UTC)},
+		// overlaps the second reservation by more than a day
+		{DateBegin: time.Date(2022, 11, 3, 0, 0, 0, 0, time.UTC), DateEnd: time.Date(2022, 11, 7, 0, 0, 0, 0, time.UTC)},
+	}
+
+	p := PreviewAirbnbImport(reservations, "Apto 101", rents)
+	if len(p.New) != 0 {
+		t.Errorf("got %d new, want 0", len(p.New))
+	}
+	if len(p.Duplicated) != 1 || p.Duplicated[0].ConfirmationCode != "HMABCD1234" {
+		t.Errorf("duplicated = %v, want HMABCD1234", p.Duplicated)
+	}
+	if len(p.Conflicts) != 1 || p.Conflicts[0].ConfirmationCode != "HMEFGH5678" {
+		t.Errorf("conflicts = %v, want HMEFGH5678", p.Conflicts)
+	}
+}
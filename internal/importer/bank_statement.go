@@ -0,0 +1,178 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatementLine is a transaction of a bank statement, Value is negative for debits
+type StatementLine struct {
+	Date        time.Time
+	Description string
+	Value       float64
+}
+
+func (s *StatementLine) ToString() string {
+	return fmt.Sprintf("%v %v R$%.2f", s.Date.Format("02/01/2006"), s.Description, s.Value)
+}
+
+// ParseBankStatement reads an OFX file or a CSV exported by the bank
+func ParseBankStatement(data []byte) ([]*StatementLine, error) {
+	head := bytes.ToUpper(data[:minInt(len(data), 512)])
+	if bytes.Contains(head, []byte("OFXHEADER")) || bytes.Contains(head, []byte("<OFX>")) {
+		return ParseOFX(data)
+	}
+	return ParseBankCSV(data)
+}
+
+var ofxTransactionRegexp = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+
+// ParseOFX reads the transactions of an OFX file, both the SGML (1.x) and the XML (2.x) flavours
+func ParseOFX(data []byte) ([]*StatementLine, error) {
+	var lines []*StatementLine
+	for i, match := range ofxTransactionRegexp.FindAllSubmatch(data, -1) {
+		trn := string(match[1])
+
+		posted := ofxField(trn, "DTPOSTED")
+		if len(posted) < 8 {
+			return nil, fmt.Errorf("transaçao %d: data inválida %q", i+1, posted)
+		}
+		date, err := time.Parse("20060102", posted[:8])
+		if err != nil {
+			return nil, fmt.Errorf("transaçao %d: data inválida %q", i+1, posted)
+		}
+
+		value, err := strconv.ParseFloat(strings.ReplaceAll(ofxField(trn, "TRNAMT"), ",", "."), 64)
+		if err != nil {
+			return nil, fmt.Errorf("transaçao %d: valor inválido", i+1)
+		}
+		// some banks write debits unsigned, telling them only by the transaction type
+		if strings.EqualFold(ofxField(trn, "TRNTYPE"), "DEBIT") {
+			value = -math.Abs(value)
+		}
+
+		description := ofxField(trn, "MEMO")
+		if name := ofxField(trn, "NAME"); len(name) > 0 {
+			description = strings.TrimSpace(name + " " + description)
+		}
+
+		lines = append(lines, &StatementLine{Date: date, Description: description, Value: value})
+	}
+
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("nenhuma transaçao encontrada no arquivo OFX")
+	}
+	return lines, nil
+}
+
+// ofxField returns the value of a tag, which in SGML OFX is not closed and ends at the next tag or line
+func ofxField(trn string, tag string) string {
+	upper := strings.ToUpper(trn)
+	i := strings.Index(upper, "<"+tag+">")
+	if i < 0 {
+		return ""
+	}
+	value := trn[i+len(tag)+2:]
+	if end := strings.IndexAny(value, "<\r\n"); end >= 0 {
+		value = value[:end]
+	}
+	return strings.TrimSpace(value)
+}
+
+var bankColumns = struct {
+	date, description, value, credit, debit, sign []string
+}{
+	date:        []string{"Data", "Data Lançamento", "Data lançamento", "Date"},
+	description: []string{"Descrição", "Descricao", "Histórico", "Historico", "Lançamento", "Description"},
+	value:       []string{"Valor", "Valor (R$)", "Amount"},
+	credit:      []string{"Crédito", "Credito", "Entrada"},
+	debit:       []string{"Débito", "Debito", "Saída", "Saida"},
+	sign:        []string{"D/C", "C/D", "Natureza", "Tipo", "Sinal", "Type"},
+}
+
+// ParseBankCSV reads a statement exported as CSV, which must have date, description and value columns
+// (or separated columns for credits and debits). Banks which write every value unsigned have a D/C column telling the
+// debits from the credits
+func ParseBankCSV(data []byte) ([]*StatementLine, error) {
+	t, err := readCSV(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	c := bankColumns
+	if !t.has(c.date...) || !t.has(c.description...) || !(t.has(c.value...) || t.has(c.debit...)) {
+		return nil, fmt.Errorf("o extrato deve ter as colunas de data, descriçao e valor")
+	}
+
+	var lines []*StatementLine
+	for i, row := range t.rows {
+		dateStr := cell(row, t.column(c.date...))
+		if len(dateStr) == 0 {
+			continue
+		}
+		date, err := parseStatementDate(dateStr)
+		if err != nil {
+			return nil, fmt.Errorf("linha %d: data inválida %q", i+2, dateStr)
+		}
+
+		var value float64
+		if t.has(c.value...) {
+			value, err = parseAmount(cell(row, t.column(c.value...)))
+			if t.has(c.sign...) {
+				value = signedValue(value, cell(row, t.column(c.sign...)))
+			}
+		} else {
+			var credit, debit float64
+			credit, err = parseAmount(cell(row, t.column(c.credit...)))
+			if err == nil {
+				debit, err = parseAmount(cell(row, t.column(c.debit...)))
+			}
+			value = credit - math.Abs(debit)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("linha %d: valor inválido", i+2)
+		}
+
+		lines = append(lines, &StatementLine{
+			Date:        date,
+			Description: cell(row, t.column(c.description...)),
+			Value:       value,
+		})
+	}
+
+	return lines, nil
+}
+
+// signedValue makes the value negative when the sign column tells it is a debit, as "D", "Débito" or "-"
+func signedValue(value float64, sign string) float64 {
+	switch strings.ToUpper(strings.TrimSpace(sign)) {
+	case "D", "DÉBITO", "DEBITO", "DEBIT", "-":
+		return -math.Abs(value)
+	case "C", "CRÉDITO", "CREDITO", "CREDIT", "+":
+		return math.Abs(value)
+	}
+	return value
+}
+
+func parseStatementDate(s string) (time.Time, error) {
+	var t time.Time
+	var err error
+	for _, layout := range []string{"02/01/2006", "2006-01-02", "02/01/06"} {
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return t, err
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,40 @@
+package importer
+
+import "testing"
+
+func TestParseBankCSVSignColumn(t *testing.T) {
+	data := []byte("Data;Histórico;Valor;D/C\n" +
+		"05/10/2022;PIX FAXINA MARIA;150,00;D\n" +
+		"06/10/2022;TED AIRBNB;1.150,00;C\n" +
+		"07/10/2022;CONDOMINIO ED SOL;-620,00;\n")
+
+	lines, err := ParseBankCSV(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{-150, 1150, -620}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(want))
+	}
+	for i, l := range lines {
+		if l.Value != want[i] {
+			t.Errorf("line %d: value = %v, want %v", i, l.Value, want[i])
+		}
+	}
+}
+
+func TestParseOFXDebitType(t *testing.T) {
+	data := []byte(`OFXHEADER:100
+<OFX><BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20221005<TRNAMT>150.00<MEMO>PIX FAXINA</STMTTRN>
+<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20221006<TRNAMT>1150.00<MEMO>TED AIRBNB</STMTTRN>
+</BANKTRANLIST></OFX>`)
+
+	lines, err := ParseOFX(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 || lines[0].Value != -150 || lines[1].Value != 1150 {
+		t.Errorf("unexpected lines %v", lines)
+	}
+}
@@ -0,0 +1,110 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// csvTable is a CSV file whose columns are looked up by any of the known names of their header
+type csvTable struct {
+	header map[string]int
+	rows   [][]string
+}
+
+func readCSV(r io.Reader) (*csvTable, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("o arquivo nao está em UTF-8")
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = guessSeparator(data)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("o arquivo está vazio")
+	}
+
+	t := &csvTable{header: make(map[string]int), rows: records[1:]}
+	for i, name := range records[0] {
+		t.header[normalizeHeader(name)] = i
+	}
+	return t, nil
+}
+
+// column returns the index of the first column found among the names, or -1
+func (t *csvTable) column(names ...string) int {
+	for _, n := range names {
+		if i, ok := t.header[normalizeHeader(n)]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+func (t *csvTable) has(names ...string) bool {
+	return t.column(names...) >= 0
+}
+
+func cell(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+func normalizeHeader(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// guessSeparator tells whether the file is separated by semicolons, as spreadsheets in portuguese export it, or commas
+func guessSeparator(data []byte) rune {
+	firstLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		firstLine = data[:i]
+	}
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		return ';'
+	}
+	return ','
+}
+
+// parseAmount reads values written either as 1234.56 or in the brazilian format 1.234,56
+func parseAmount(s string) (float64, error) {
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "R$"))
+	if len(s) == 0 {
+		return 0, nil
+	}
+	// the last separator is the decimal one, unless it repeats: "1.234,56" and "1,234.56" are the same amount,
+	// as are "1.234.567" and "1,234,567"
+	decimal := strings.LastIndexAny(s, ".,")
+	if decimal >= 0 && strings.Count(s, s[decimal:decimal+1]) > 1 {
+		decimal = -1
+	}
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case i == decimal:
+			b.WriteRune('.')
+		case r == '.' || r == ',':
+			continue
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return strconv.ParseFloat(b.String(), 64)
+}
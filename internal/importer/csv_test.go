@@ -0,0 +1,30 @@
+package importer
+
+import "testing"
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"", 0},
+		{"123.45", 123.45},
+		{"123,45", 123.45},
+		{"1,234.56", 1234.56},
+		{"1.234,56", 1234.56},
+		{"R$ 1.234,56", 1234.56},
+		{"-1,234.56", -1234.56},
+		{"1,234,567", 1234567},
+		{"1.234.567,89", 1234567.89},
+	}
+	for _, tt := range tests {
+		got, err := parseAmount(tt.in)
+		if err != nil {
+			t.Errorf("parseAmount(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseAmount(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+var indexColumns = struct {
+	month, variation []string
+}{
+	month:     []string{"data", "mês", "mes", "month", "date"},
+	variation: []string{"valor", "variaçao", "variação", "variacao", "value"},
+}
+
+// monthLayouts are the dates of the series exported by the Banco Central (SGS) and of hand written ones
+var monthLayouts = []string{"02/01/2006", "01/2006", "2006-01", "2006-01-02"}
+
+// ParseIndexSeries reads the monthly variations, in percent, of a correction index from a CSV with the columns
+// data and valor, as exported by the SGS of the Banco Central
+func ParseIndexSeries(r io.Reader, index models.CorrectionIndex) ([]*models.IndexValue, error) {
+	t, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	if !t.has(indexColumns.month...) || !t.has(indexColumns.variation...) {
+		return nil, fmt.Errorf("o arquivo deve ter as colunas data e valor")
+	}
+
+	monthCol := t.column(indexColumns.month...)
+	variationCol := t.column(indexColumns.variation...)
+	var values []*models.IndexValue
+	for i, row := range t.rows {
+		if len(cell(row, monthCol)) == 0 {
+			continue
+		}
+		month, err := parseMonth(cell(row, monthCol))
+		if err != nil {
+			return nil, fmt.Errorf("linha %d: %v", i+2, err.Error())
+		}
+		variation, err := parseAmount(cell(row, variationCol))
+		if err != nil {
+			return nil, fmt.Errorf("linha %d: valor inválido %q", i+2, cell(row, variationCol))
+		}
+		values = append(values, &models.IndexValue{Index: index, Month: month, Variation: variation})
+	}
+	return values, nil
+}
+
+func parseMonth(s string) (time.Time, error) {
+	for _, layout := range monthLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("mês inválido %q", s)
+}
@@ -0,0 +1,175 @@
+package importer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// maxDaysBetweenRecordAndStatement is how far the date of a record may be from the date the bank debited it
+const maxDaysBetweenRecordAndStatement = 5
+
+// Record is an expense registered in the store, regardless of its type
+type Record struct {
+	Type      models.RecordType
+	Date      time.Time
+	Value     float64
+	Apartment models.Apartment
+}
+
+func (r *Record) ToString() string {
+	return fmt.Sprintf("%v de %v em %v R$%.2f", r.Type, r.Apartment.Name, r.Date.Format("02/01/2006"), r.Value)
+}
+
+// LoadRecords reads the expenses of the apartment which can be found in a bank statement
+func LoadRecords(store storage.Store, apartment models.Apartment) ([]*Record, error) {
+	var records []*Record
+
+	condos, err := store.GetPayedCondos(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range condos {
+		records = append(records, &Record{Type: models.RecordCondo, Date: c.Date, Value: c.Value, Apartment: apartment})
+	}
+
+	bills, err := store.GetPayedBills(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bills {
+		records = append(records, &Record{Type: models.RecordEnergyBill, Date: b.Date, Value: b.Value, Apartment: apartment})
+	}
+
+	cleanings, err := store.GetPayedCleanings(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cleanings {
+		records = append(records, &Record{Type: models.RecordCleaning, Date: c.Date, Value: c.Value, Apartment: apartment})
+	}
+
+	installments, err := store.GetPayedFinancialInstallments(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range installments {
+		records = append(records, &Record{Type: models.RecordFinancingInstallment, Date: f.Date, Value: f.Value, Apartment: apartment})
+	}
+
+	expenses, err := store.GetMiscellaneousExpenses(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range expenses {
+		records = append(records, &Record{Type: models.RecordMiscellaneousExpense, Date: e.Date, Value: e.Value, Apartment: apartment})
+	}
+
+	return records, nil
+}
+
+type Match struct {
+	Line   *StatementLine
+	Rule   *models.StatementRule
+	Record *Record
+}
+
+type Reconciliation struct {
+	// Matched are statement lines with the record that registers them
+	Matched []*Match
+	// Unregistered are statement lines recognized by a rule but without a record
+	Unregistered []*Match
+	// Unknown are debits which no rule recognizes
+	Unknown []*StatementLine
+	// Unpaid are records of the statement period which no statement line pays
+	Unpaid []*Record
+}
+
+// Reconcile matches the debits of the statement against the records, using the rules to tell which type of record
+// and apartment a line refers to. Only records dated within the statement period are reported as unpaid
+func Reconcile(lines []*StatementLine, rules []*models.StatementRule, records []*Record) *Reconciliation {
+	rec := &Reconciliation{}
+	used := make(map[*Record]bool)
+
+	var begin, end time.Time
+	for _, l := range lines {
+		if l.Value >= 0 {
+			continue
+		}
+		if begin.IsZero() || l.Date.Before(begin) {
+			begin = l.Date
+		}
+		if l.Date.After(end) {
+			end = l.Date
+		}
+
+		rule := matchRule(l, rules)
+		if rule == nil {
+			rec.Unknown = append(rec.Unknown, l)
+			continue
+		}
+
+		m := &Match{Line: l, Rule: rule, Record: matchRecord(l, rule, records, used)}
+		if m.Record == nil {
+			rec.Unregistered = append(rec.Unregistered, m)
+			continue
+		}
+		used[m.Record] = true
+		rec.Matched = append(rec.Matched, m)
+	}
+
+	for _, r := range records {
+		if used[r] || !isRuled(r, rules) || r.Date.Before(begin) || r.Date.After(end) {
+			continue
+		}
+		rec.Unpaid = append(rec.Unpaid, r)
+	}
+
+	return rec
+}
+
+func matchRule(l *StatementLine, rules []*models.StatementRule) *models.StatementRule {
+	description := strings.ToUpper(l.Description)
+	for _, r := range rules {
+		if strings.Contains(description, strings.ToUpper(r.Pattern)) {
+			return r
+		}
+	}
+	return nil
+}
+
+// matchRecord finds the closest record in date of the rule type and apartment with the value of the line
+func matchRecord(l *StatementLine, rule *models.StatementRule, records []*Record, used map[*Record]bool) *Record {
+	var best *Record
+	var bestDistance float64
+	for _, r := range records {
+		if used[r] || r.Type != rule.RecordType || r.Apartment.Name != rule.Apartment.Name {
+			continue
+		}
+		if math.Abs(r.Value-math.Abs(l.Value)) > 0.01 {
+			continue
+		}
+		distance := math.Abs(r.Date.Sub(l.Date).Hours() / 24)
+		if distance > maxDaysBetweenRecordAndStatement {
+			continue
+		}
+		if best == nil || distance < bestDistance {
+			best, bestDistance = r, distance
+		}
+	}
+	return best
+}
+
+// isRuled tells if records of this type and apartment are expected in the statement
+func isRuled(r *Record, rules []*models.StatementRule) bool {
+	for _, rule := range rules {
+		if rule.RecordType == r.Type && rule.Apartment.Name == r.Apartment.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -5,13 +5,40 @@ import (
 	"time"
 )
 
+// AmortizationOption is what an amortization reduces in the financing, the term or the following installments
+type AmortizationOption string
+
+const (
+	ReduceTerm        AmortizationOption = "reduzir prazo"
+	ReduceInstallment AmortizationOption = "reduzir parcela"
+)
+
+var AmortizationOptions = []AmortizationOption{ReduceTerm, ReduceInstallment}
+
+// ParseAmortizationOption validates an option written by the user
+func ParseAmortizationOption(s string) (AmortizationOption, bool) {
+	for _, o := range AmortizationOptions {
+		if string(o) == s {
+			return o, true
+		}
+	}
+	return "", false
+}
+
 type Amortization struct {
 	Payer string
 	Value float64
 	Date  time.Time
+	// Option is empty when the apartment had no financing contract to simulate, being taken as ReduceTerm
+	Option AmortizationOption
+	// Receipts are the keys of the receipts attached to the record in the blob store
+	Receipts []string
 	Apartment
 }
 
 func (a *Amortization) ToString() string {
+	if a.Option != "" {
+		return fmt.Sprintf("%v amortizou R$%v no dia %v para %v", a.Payer, a.Value, a.Date.Format("02/01/2006"), a.Option)
+	}
 	return fmt.Sprintf("%v amortizou R$%v no dia %v", a.Payer, a.Value, a.Date.Format("02/01/2006"))
 }
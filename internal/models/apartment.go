@@ -1,10 +1,43 @@
 package models
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 type Apartment struct {
-	Name    string
-	Address string
+	// Id identifies the apartment across renames, it is the id of the apartment sheet
+	Id              int64
+	Name            string
+	Address         string
+	AcquisitionDate time.Time
+	PurchasePrice   float64
+	FinancingBank   string
+	ListingURLs     []string
+	Archived        bool
 }
 
 func (a *Apartment) ToString() string {
 	return a.Name + " - " + a.Address
 }
+
+// Details describes every registry data of the apartment, one per line
+func (a *Apartment) Details() string {
+	status := "ativo"
+	if a.Archived {
+		status = "arquivado"
+	}
+	acquisitionDate := ""
+	if !a.AcquisitionDate.IsZero() {
+		acquisitionDate = a.AcquisitionDate.Format("02/01/2006")
+	}
+	return strings.Join([]string{
+		fmt.Sprintf("Imóvel: %v (%v)", a.Name, status),
+		fmt.Sprintf("Endereço: %v", a.Address),
+		fmt.Sprintf("Data de aquisiçao: %v", acquisitionDate),
+		fmt.Sprintf("Preço de compra: R$%v", a.PurchasePrice),
+		fmt.Sprintf("Banco do financiamento: %v", a.FinancingBank),
+		fmt.Sprintf("Anúncios: %v", strings.Join(a.ListingURLs, " ")),
+	}, "\n")
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Attachment links a receipt or invoice kept in the blob store to the record it proves
+type Attachment struct {
+	RecordType RecordType
+	Date       time.Time
+	Value      float64
+	Key        string
+	Apartment
+}
+
+func (a *Attachment) ToString() string {
+	return fmt.Sprintf("%v do dia %v de R$%v", a.RecordType, a.Date.Format("02/01/2006"), a.Value)
+}
@@ -0,0 +1,7 @@
+package models
+
+// Batch groups records which must be written to the store at once
+type Batch struct {
+	Rents                 []*Rent
+	MiscellaneousExpenses []*MiscellaneousExpense
+}
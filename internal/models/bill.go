@@ -9,6 +9,8 @@ type EnergyBill struct {
 	Date  time.Time
 	Value float64
 	Payer string
+	// Receipts are the keys of the receipts attached to the record in the blob store
+	Receipts []string
 	Apartment
 }
 
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// BudgetTypes are the record types which can have a monthly budget
+var BudgetTypes = []RecordType{RecordCleaning, RecordEnergyBill, RecordCondo, RecordMiscellaneousExpense}
+
+// BudgetThresholds are the fractions of a budget whose crossing is alerted, from the highest
+var BudgetThresholds = []float64{1, 0.8}
+
+// Budget is the most the apartment should spend on records of a type in a month, alerts are sent to ChatId
+type Budget struct {
+	Apartment
+	Type   RecordType
+	Value  float64
+	ChatId int64
+}
+
+func (b *Budget) ToString() string {
+	return fmt.Sprintf("%v de %v: R$%.2f por mês", b.Type, b.Apartment.Name, b.Value)
+}
+
+// IsBudgetType reports whether records of the type can have a budget
+func IsBudgetType(t RecordType) bool {
+	for _, bt := range BudgetTypes {
+		if bt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// BudgetAlert tells a record took what was spent in the month past a threshold of the budget of its type
+type BudgetAlert struct {
+	Budget    *Budget
+	Month     time.Time
+	Spent     float64
+	Threshold float64
+}
+
+func (a *BudgetAlert) ToString() string {
+	if a.Threshold >= 1 {
+		return fmt.Sprintf("Orçamento estourado: %v em %v soma R$%.2f, acima dos R$%.2f previstos para %v",
+			a.Budget.Type, a.Month.Format("01/2006"), a.Spent, a.Budget.Value, a.Budget.Apartment.Name)
+	}
+	return fmt.Sprintf("Atençao: %v em %v soma R$%.2f, %.0f%% dos R$%.2f previstos para %v",
+		a.Budget.Type, a.Month.Format("01/2006"), a.Spent, a.Spent/a.Budget.Value*100, a.Budget.Value, a.Budget.Apartment.Name)
+}
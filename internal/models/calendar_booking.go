@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// CalendarBooking is a reservation read in the last sync of the calendar of an OTA, the Source telling which OTA.
+// Airbnb drops cancelled reservations from its calendar instead of marking them, so a booking missing from the next
+// sync of the same source was cancelled
+type CalendarBooking struct {
+	Source    string
+	UID       string
+	DateBegin time.Time
+	DateEnd   time.Time
+	Apartment
+}
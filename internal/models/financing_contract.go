@@ -0,0 +1,81 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// AmortizationSystem is how the installments of a financing are calculated
+type AmortizationSystem string
+
+const (
+	// SystemSAC amortizes the same value every month, so the installments decrease with the interest
+	SystemSAC AmortizationSystem = "SAC"
+	// SystemPrice keeps the installments constant, the Tabela Price
+	SystemPrice AmortizationSystem = "Price"
+)
+
+var AmortizationSystems = []AmortizationSystem{SystemSAC, SystemPrice}
+
+// ParseAmortizationSystem validates a system written by the user
+func ParseAmortizationSystem(s string) (AmortizationSystem, bool) {
+	for _, a := range AmortizationSystems {
+		if string(a) == s {
+			return a, true
+		}
+	}
+	return "", false
+}
+
+// CorrectionIndex is the monetary index correcting the balance of a financing every month
+type CorrectionIndex string
+
+const (
+	IndexNone CorrectionIndex = ""
+	IndexTR   CorrectionIndex = "TR"
+	IndexIPCA CorrectionIndex = "IPCA"
+)
+
+var CorrectionIndexes = []CorrectionIndex{IndexTR, IndexIPCA}
+
+// ParseCorrectionIndex validates an index written by the user, an empty one meaning no correction
+func ParseCorrectionIndex(s string) (CorrectionIndex, bool) {
+	if s == string(IndexNone) {
+		return IndexNone, true
+	}
+	for _, i := range CorrectionIndexes {
+		if string(i) == s {
+			return i, true
+		}
+	}
+	return "", false
+}
+
+// FinancingContract is the financing of an apartment, one per apartment
+type FinancingContract struct {
+	Apartment
+	Principal float64
+	// AnnualRate is the effective yearly interest rate, in percent
+	AnnualRate float64
+	// Term is the number of monthly installments
+	Term   int
+	System AmortizationSystem
+	// Start is the due date of the first installment, the next ones are due on the same day of the following months
+	Start time.Time
+	Index CorrectionIndex
+}
+
+func (f *FinancingContract) ToString() string {
+	index := "sem correçao"
+	if f.Index != IndexNone {
+		index = "corrigido por " + string(f.Index)
+	}
+	return fmt.Sprintf("Financiamento de %v: R$%.2f a %.2f%% a.a. em %d parcelas (%v) a partir de %v, %v",
+		f.Apartment.Name, f.Principal, f.AnnualRate, f.Term, f.System, f.Start.Format("02/01/2006"), index)
+}
+
+// InstallmentDate returns the due date of the installment of the given number, the first being 1
+func (f *FinancingContract) InstallmentDate(number int) time.Time {
+	month := time.Date(f.Start.Year(), f.Start.Month(), 1, 0, 0, 0, 0, f.Start.Location()).AddDate(0, number-1, 0)
+	return DueDateInMonth(f.Start.Day(), month)
+}
@@ -9,6 +9,8 @@ type FinancingInstallment struct {
 	Value float64
 	Date  time.Time
 	Payer string
+	// Receipts are the keys of the receipts attached to the record in the blob store
+	Receipts []string
 	Apartment
 }
 
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// IndexValue is the variation of a correction index in a month
+type IndexValue struct {
+	Index CorrectionIndex
+	// Month is the first day of the month
+	Month time.Time
+	// Variation is in percent
+	Variation float64
+}
+
+func (i *IndexValue) ToString() string {
+	return fmt.Sprintf("%v de %v: %.4f%%", i.Index, i.Month.Format("01/2006"), i.Variation)
+}
@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+// JobRun is when a scheduled job last did its work, so it isn't done again after a restart
+type JobRun struct {
+	Job     string
+	LastRun time.Time
+}
@@ -5,14 +5,45 @@ import (
 	"time"
 )
 
+// ExpenseCategory classifies the miscellaneous expenses which are treated apart, as the deductible ones
+type ExpenseCategory string
+
+const (
+	CategoryNone ExpenseCategory = ""
+	// CategoryServiceFee are the fees charged by the platforms to pay out the rents, deductible from them
+	CategoryServiceFee ExpenseCategory = "taxa de serviço"
+)
+
+var ExpenseCategories = []ExpenseCategory{CategoryServiceFee}
+
+// ParseExpenseCategory validates a category written by the user, an empty one meaning no category
+func ParseExpenseCategory(s string) (ExpenseCategory, bool) {
+	if s == string(CategoryNone) {
+		return CategoryNone, true
+	}
+	for _, c := range ExpenseCategories {
+		if string(c) == s {
+			return c, true
+		}
+	}
+	return "", false
+}
+
 type MiscellaneousExpense struct {
 	Value       float64
 	Date        time.Time
 	Description string
 	Payer       string
+	Category    ExpenseCategory
+	// Receipts are the keys of the receipts attached to the record in the blob store
+	Receipts []string
 	Apartment
 }
 
 func (m *MiscellaneousExpense) ToString() string {
-	return fmt.Sprintf("despesa de %v, com identificaçao \"%v\", do dia %v, paga por %v", m.Value, m.Description, m.Date.Format("02/01/2006"), m.Payer)
+	s := fmt.Sprintf("despesa de %v, com identificaçao \"%v\", do dia %v, paga por %v", m.Value, m.Description, m.Date.Format("02/01/2006"), m.Payer)
+	if m.Category != CategoryNone {
+		s += fmt.Sprintf(" (%v)", m.Category)
+	}
+	return s
 }
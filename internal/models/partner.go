@@ -0,0 +1,13 @@
+package models
+
+// PartnerNames returns the names of the users with the partner role, who share the apartments, receiving the rents and
+// paying the expenses, and split their result equally
+func PartnerNames(users []*User) []string {
+	var names []string
+	for _, u := range users {
+		if u.Role == RolePartner && len(u.Name) > 0 {
+			names = append(names, u.Name)
+		}
+	}
+	return names
+}
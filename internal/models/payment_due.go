@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// PaymentDueTypes are the record types whose payment can be reminded
+var PaymentDueTypes = []RecordType{RecordCondo, RecordEnergyBill, RecordFinancingInstallment}
+
+// PaymentDue is the day of the month an expense of the apartment must be paid, reminders are sent to ChatId
+type PaymentDue struct {
+	Apartment
+	Type   RecordType
+	Day    int
+	ChatId int64
+	// Start is when the due day was set, the months before it are never reminded
+	Start time.Time
+	// RemindedMonth is the first day of the last month reminded before its due date, LastMonth is the first day of
+	// the last month either paid or reminded as overdue
+	RemindedMonth time.Time
+	LastMonth     time.Time
+	// SnoozedUntil holds the reminders back until it comes
+	SnoozedUntil time.Time
+}
+
+func (p *PaymentDue) ToString() string {
+	return fmt.Sprintf("%v de %v vence todo dia %d", p.Type, p.Apartment.Name, p.Day)
+}
+
+// DueDate returns when the payment is due in the month of the given date
+func (p *PaymentDue) DueDate(month time.Time) time.Time {
+	return DueDateInMonth(p.Day, month)
+}
+
+// FirstPendingMonth returns the first day of the first month not paid nor reminded as overdue yet
+func (p *PaymentDue) FirstPendingMonth() time.Time {
+	start := time.Date(p.Start.Year(), p.Start.Month(), 1, 0, 0, 0, 0, p.Start.Location())
+	if next := p.LastMonth.AddDate(0, 1, 0); !p.LastMonth.IsZero() && next.After(start) {
+		return next
+	}
+	return start
+}
+
+// IsPaymentDueType reports whether payments of records of the type can be reminded
+func IsPaymentDueType(t RecordType) bool {
+	for _, pt := range PaymentDueTypes {
+		if pt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// DueDateInMonth returns the day of the month of the given date, months shorter than the day end on their last day
+func DueDateInMonth(day int, month time.Time) time.Time {
+	firstDay := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	lastDay := firstDay.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstDay.AddDate(0, 0, day-1)
+}
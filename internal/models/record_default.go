@@ -0,0 +1,11 @@
+package models
+
+// RecordDefault is the value and payer offered to be repeated on the next record of a kind in an apartment: the ones
+// the user registered last or, when they have none, the ones of the latest record of that kind
+type RecordDefault struct {
+	UserId     int64
+	Apartment  string
+	RecordType RecordType
+	Value      float64
+	Payer      string
+}
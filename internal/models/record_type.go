@@ -0,0 +1,25 @@
+package models
+
+// RecordType identifies a kind of expense record
+type RecordType string
+
+const (
+	RecordCleaning             RecordType = "faxina"
+	RecordCondo                RecordType = "condominio"
+	RecordEnergyBill           RecordType = "luz"
+	RecordFinancingInstallment RecordType = "parcela"
+	RecordMiscellaneousExpense RecordType = "despesa"
+	RecordAmortization         RecordType = "amortizacao"
+)
+
+var RecordTypes = []RecordType{RecordCleaning, RecordCondo, RecordEnergyBill, RecordFinancingInstallment, RecordMiscellaneousExpense, RecordAmortization}
+
+// ParseRecordType validates a record type written by the user
+func ParseRecordType(s string) (RecordType, bool) {
+	for _, t := range RecordTypes {
+		if string(t) == s {
+			return t, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// RecurringExpenseTypes are the record types which can be registered every month by a recurring expense
+var RecurringExpenseTypes = []RecordType{RecordCondo, RecordFinancingInstallment}
+
+// RecurringExpense is an expense with the same value on the same day of every month, from Start until End
+type RecurringExpense struct {
+	Apartment
+	Type  RecordType
+	Value float64
+	// Day of the month the expense is due, months shorter than it are due on their last day
+	Day   int
+	Payer string
+	Start time.Time
+	// End is zero while the expense has no end
+	End time.Time
+	// Confirm asks in ChatId before registering each month, otherwise the records are registered automatically
+	Confirm bool
+	ChatId  int64
+	// LastMonth is the first day of the last month registered or ignored, the months after it are caught up
+	LastMonth time.Time
+}
+
+func (r *RecurringExpense) ToString() string {
+	mode := "automático"
+	if r.Confirm {
+		mode = "com confirmaçao"
+	}
+	return fmt.Sprintf("%v de %v todo dia %d de R$%.2f pago por %v (%v)", r.Type, r.Apartment.Name, r.Day, r.Value, r.Payer, mode)
+}
+
+// DueDate returns when the expense is due in the month of the given date
+func (r *RecurringExpense) DueDate(month time.Time) time.Time {
+	return DueDateInMonth(r.Day, month)
+}
+
+// FirstPendingMonth returns the first day of the first month not registered nor ignored yet
+func (r *RecurringExpense) FirstPendingMonth() time.Time {
+	start := time.Date(r.Start.Year(), r.Start.Month(), 1, 0, 0, 0, 0, r.Start.Location())
+	if next := r.LastMonth.AddDate(0, 1, 0); !r.LastMonth.IsZero() && next.After(start) {
+		return next
+	}
+	return start
+}
+
+// IsActiveAt reports whether the expense is due at the date, according to its start and end
+func (r *RecurringExpense) IsActiveAt(date time.Time) bool {
+	if date.Before(r.Start) {
+		return false
+	}
+	return r.End.IsZero() || !date.After(r.End)
+}
+
+// IsRecurringExpenseType reports whether records of the type can be registered by a recurring expense
+func IsRecurringExpenseType(t RecordType) bool {
+	for _, rt := range RecurringExpenseTypes {
+		if rt == t {
+			return true
+		}
+	}
+	return false
+}
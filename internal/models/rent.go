@@ -11,15 +11,33 @@ type Rent struct {
 	Value     float64
 	Renter    string
 	Receiver  string
+	// BookedAt is when the reservation was made, zero when unknown
+	BookedAt time.Time
+	// CleaningFee is the part of the value the guest paid for the cleaning, zero when unknown
+	CleaningFee float64
+	// ReceivedAt is when the receiver got the money, zero when unknown
+	ReceivedAt time.Time
 	Apartment
 }
 
+// ReceiptDate is when the rent was received, the check-in when unknown as the platforms pay out right after it
+func (r *Rent) ReceiptDate() time.Time {
+	if r.ReceivedAt.IsZero() {
+		return r.DateBegin
+	}
+	return r.ReceivedAt
+}
+
 func (r *Rent) ToString() string {
-	return fmt.Sprintf(`do dia %v ao dia %v pelo valor de R$%v para o inquilino %v - recebido por %v`,
+	s := fmt.Sprintf(`do dia %v ao dia %v pelo valor de R$%v para o inquilino %v - recebido por %v`,
 		r.DateBegin.Format("02/01/2006"),
 		r.DateEnd.Format("02/01/2006"),
 		r.Value,
 		r.Renter,
 		r.Receiver,
 	)
+	if r.CleaningFee > 0 {
+		s += fmt.Sprintf(" (taxa de limpeza de R$%v)", r.CleaningFee)
+	}
+	return s
 }
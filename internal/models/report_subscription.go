@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ReportFrequency is how often a subscribed report is delivered
+type ReportFrequency string
+
+const (
+	ReportWeekly  ReportFrequency = "semanal"
+	ReportMonthly ReportFrequency = "mensal"
+)
+
+var ReportFrequencies = []ReportFrequency{ReportWeekly, ReportMonthly}
+
+// ParseReportFrequency validates a frequency written by the user
+func ParseReportFrequency(s string) (ReportFrequency, bool) {
+	for _, f := range ReportFrequencies {
+		if string(f) == s {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// CurrentPeriod returns the first day of the period holding the time, records are dated at midnight UTC so the
+// periods are too
+func (f ReportFrequency) CurrentPeriod(now time.Time) time.Time {
+	return f.PeriodStart(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+}
+
+// PeriodStart returns the first day of the period holding the date, weeks start on mondays
+func (f ReportFrequency) PeriodStart(date time.Time) time.Time {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	if f == ReportWeekly {
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+	return day.AddDate(0, 0, 1-day.Day())
+}
+
+// NextPeriod returns the first day of the period after the one starting at the date
+func (f ReportFrequency) NextPeriod(start time.Time) time.Time {
+	if f == ReportWeekly {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+// PreviousPeriod returns the first day of the period before the one starting at the date
+func (f ReportFrequency) PreviousPeriod(start time.Time) time.Time {
+	if f == ReportWeekly {
+		return start.AddDate(0, 0, -7)
+	}
+	return start.AddDate(0, -1, 0)
+}
+
+// ReportSubscription delivers the report of every period to a chat, LastPeriod is the start of the last period
+// delivered
+type ReportSubscription struct {
+	ChatId     int64
+	Frequency  ReportFrequency
+	LastPeriod time.Time
+}
+
+func (s *ReportSubscription) ToString() string {
+	return fmt.Sprintf("relatório %v, último enviado referente a %v", s.Frequency, s.LastPeriod.Format("02/01/2006"))
+}
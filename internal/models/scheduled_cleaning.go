@@ -0,0 +1,19 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ScheduledCleaning is a cleaning assigned to a cleaner, registered as a Cleaning once it is done and its value known
+type ScheduledCleaning struct {
+	Date        time.Time
+	CleanerId   int64
+	CleanerName string
+	Payer       string
+	Apartment
+}
+
+func (c *ScheduledCleaning) ToString() string {
+	return fmt.Sprintf("faxina de %v no dia %v com %v", c.Apartment.Name, c.Date.Format("02/01/2006"), c.CleanerName)
+}
@@ -0,0 +1,14 @@
+package models
+
+import "fmt"
+
+// StatementRule tells which record a bank statement line refers to when its description contains Pattern
+type StatementRule struct {
+	Pattern    string
+	RecordType RecordType
+	Apartment
+}
+
+func (s *StatementRule) ToString() string {
+	return fmt.Sprintf("\"%v\" → %v de %v", s.Pattern, s.RecordType, s.Apartment.Name)
+}
@@ -0,0 +1,46 @@
+package models
+
+import "fmt"
+
+// Role defines what a Telegram user is allowed to do with the bot
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RolePartner  Role = "socio"
+	RoleReadOnly Role = "leitura"
+	RoleCleaner  Role = "faxina"
+)
+
+var Roles = []Role{RoleAdmin, RolePartner, RoleReadOnly, RoleCleaner}
+
+// ParseRole validates a role written by the user
+func ParseRole(s string) (Role, bool) {
+	for _, r := range Roles {
+		if string(r) == s {
+			return r, true
+		}
+	}
+	return "", false
+}
+
+// User is a Telegram user allowed to talk to the bot
+type User struct {
+	Id   int64
+	Name string
+	Role Role
+}
+
+func (u *User) ToString() string {
+	return fmt.Sprintf("%v (%v) - %v", u.Name, u.Id, u.Role)
+}
+
+// AllowedChat is a group chat where the bot answers to its allowed users
+type AllowedChat struct {
+	Id   int64
+	Name string
+}
+
+func (c *AllowedChat) ToString() string {
+	return fmt.Sprintf("grupo %v (%v)", c.Name, c.Id)
+}
@@ -0,0 +1,7 @@
+package models
+
+// UserPreference is the apartment a user selected last, offered on top of the next apartment keyboard
+type UserPreference struct {
+	UserId    int64
+	Apartment string
+}
@@ -0,0 +1,62 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+)
+
+const (
+	barHeight  = 12
+	barSpacing = 4
+	// labels are written left of the bars and values right of them
+	barLabelWidth = 150
+	barValueWidth = 90
+)
+
+// Bar is a labeled value of a bar chart
+type Bar struct {
+	Label string
+	Value float64
+	Color color.Color
+}
+
+type barChart struct {
+	bars []Bar
+	max  float64
+}
+
+// BarChart draws horizontal bars proportional to the largest value, negative values are drawn as zero
+func (d *Document) BarChart(bars []Bar) {
+	chart := barChart{bars: bars}
+	for _, b := range bars {
+		if b.Value > chart.max {
+			chart.max = b.Value
+		}
+	}
+	d.add(chart)
+}
+
+func (c barChart) height() float64 {
+	return float64(len(c.bars)*(barHeight+barSpacing) + barSpacing)
+}
+
+func (c barChart) draw(buf *bytes.Buffer, top float64) {
+	width := float64(pageWidth - 2*margin - barLabelWidth - barValueWidth)
+	y := top - barSpacing
+	for _, b := range c.bars {
+		y -= barHeight
+		fmt.Fprintf(buf, "0 0 0 rg\nBT /F1 %.0f Tf %d %.0f Td (%s) Tj ET\n", textFont.size, margin, y+3, escape(b.Label))
+
+		length := 0.0
+		if c.max > 0 && b.Value > 0 {
+			length = width * b.Value / c.max
+		}
+		rgb(buf, b.Color)
+		fmt.Fprintf(buf, "%d %.1f %.1f %d re f\n", margin+barLabelWidth, y, length, barHeight)
+
+		fmt.Fprintf(buf, "0 0 0 rg\nBT /F1 %.0f Tf %.1f %.0f Td (%s) Tj ET\n", textFont.size, margin+barLabelWidth+length+4, y+3,
+			escape(fmt.Sprintf("R$%.2f", b.Value)))
+		y -= barSpacing
+	}
+}
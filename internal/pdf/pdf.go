@@ -0,0 +1,168 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"strings"
+)
+
+// A4 page in points, the unit of PDF
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 40
+)
+
+type font struct {
+	name       string
+	size       float64
+	lineHeight float64
+}
+
+var (
+	headingFont = font{name: "F2", size: 13, lineHeight: 20}
+	textFont    = font{name: "F1", size: 9, lineHeight: 12}
+)
+
+// element is a block of the page, drawn from its top
+type element interface {
+	height() float64
+	draw(buf *bytes.Buffer, top float64)
+}
+
+type line struct {
+	font font
+	text string
+}
+
+func (l line) height() float64 {
+	return l.font.lineHeight
+}
+
+func (l line) draw(buf *bytes.Buffer, top float64) {
+	if len(l.text) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "BT /%s %.0f Tf %d %.0f Td (%s) Tj ET\n", l.font.name, l.font.size, margin, top-l.font.lineHeight, escape(l.text))
+}
+
+// Document is a text only PDF, headings in Helvetica and lines in Courier so tables written with padded columns
+// stay aligned. Pages are added as lines overflow them
+type Document struct {
+	pages [][]element
+	used  float64
+}
+
+func New() *Document {
+	return &Document{}
+}
+
+// Heading writes a title line
+func (d *Document) Heading(text string) {
+	d.add(line{font: headingFont, text: text})
+}
+
+// Line writes a line of text, longer ones are not wrapped
+func (d *Document) Line(text string) {
+	d.add(line{font: textFont, text: text})
+}
+
+// Blank leaves an empty line
+func (d *Document) Blank() {
+	d.Line("")
+}
+
+// PageBreak starts a new page
+func (d *Document) PageBreak() {
+	d.pages = append(d.pages, nil)
+	d.used = 0
+}
+
+func (d *Document) add(e element) {
+	if len(d.pages) == 0 || d.used+e.height() > pageHeight-2*margin {
+		d.PageBreak()
+	}
+	d.pages[len(d.pages)-1] = append(d.pages[len(d.pages)-1], e)
+	d.used += e.height()
+}
+
+// Bytes writes the document as a PDF file
+func (d *Document) Bytes() []byte {
+	pages := d.pages
+	if len(pages) == 0 {
+		pages = [][]element{nil}
+	}
+
+	var objects []string
+	// 1 catalog, 2 page tree, 3 and 4 fonts, then a page and its content for each page
+	var kids []string
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+2*i))
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	)
+	for i, page := range pages {
+		content := pageContent(page)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				pageWidth, pageHeight, 6+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func pageContent(page []element) string {
+	var buf bytes.Buffer
+	top := float64(pageHeight - margin)
+	for _, e := range page {
+		e.draw(&buf, top)
+		top -= e.height()
+	}
+	return buf.String()
+}
+
+// escape encodes the text in WinAnsi, which holds the accented letters of portuguese, escaping the delimiters of
+// PDF strings. Characters out of it are replaced by ?
+func escape(text string) string {
+	var buf bytes.Buffer
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			buf.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&buf, "\\%03o", r)
+		default:
+			buf.WriteByte('?')
+		}
+	}
+	return buf.String()
+}
+
+// rgb sets the fill color of the shapes drawn next
+func rgb(buf *bytes.Buffer, c color.Color) {
+	r, g, b, _ := c.RGBA()
+	fmt.Fprintf(buf, "%.3f %.3f %.3f rg\n", float64(r)/0xffff, float64(g)/0xffff, float64(b)/0xffff)
+}
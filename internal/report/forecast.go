@@ -0,0 +1,220 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/financing"
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// ForecastMonth is the income and the expenses expected in a month
+type ForecastMonth struct {
+	Month    time.Time
+	Income   float64
+	Expenses map[models.RecordType]float64
+}
+
+func (m *ForecastMonth) TotalExpenses() float64 {
+	var total float64
+	for _, v := range m.Expenses {
+		total += v
+	}
+	return total
+}
+
+func (m *ForecastMonth) Balance() float64 {
+	return m.Income - m.TotalExpenses()
+}
+
+// Deficit tells the expenses expected exceed the income
+func (m *ForecastMonth) Deficit() bool {
+	return m.TotalExpenses() > m.Income+0.005
+}
+
+// ApartmentForecast is the cash flow expected for an apartment month by month
+type ApartmentForecast struct {
+	Apartment string
+	Months    []*ForecastMonth
+}
+
+// Forecast is the cash flow expected for each apartment and for all of them together
+type Forecast struct {
+	Apartments   []*ApartmentForecast
+	Consolidated []*ForecastMonth
+}
+
+// dueEstimateMonths are the months before the forecast whose payments estimate the ones with a due date only
+const dueEstimateMonths = 6
+
+// BuildForecast projects the months after the one of now. The income are the rents already recorded, by check-in,
+// and the expenses are the recurring ones, the installments of the financing schedule, which replace a recurring
+// installment, and the payments with a due date and no recurring expense, estimated as the average of the months
+// paid recently
+func BuildForecast(store storage.Store, apartments []*models.Apartment, now time.Time, months int) (*Forecast, error) {
+	begin := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+	recurring, err := store.GetRecurringExpenses()
+	if err != nil {
+		return nil, err
+	}
+	dues, err := store.GetPaymentDues()
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Forecast{Consolidated: newForecastMonths(begin, months)}
+	for _, apt := range apartments {
+		af, err := forecastApartment(store, apt, recurring, dues, begin, months, now)
+		if err != nil {
+			return nil, err
+		}
+		f.Apartments = append(f.Apartments, af)
+		for i, m := range af.Months {
+			f.Consolidated[i].Income += m.Income
+			for t, v := range m.Expenses {
+				f.Consolidated[i].Expenses[t] += v
+			}
+		}
+	}
+	return f, nil
+}
+
+func newForecastMonths(begin time.Time, months int) []*ForecastMonth {
+	var forecast []*ForecastMonth
+	for i := 0; i < months; i++ {
+		forecast = append(forecast, &ForecastMonth{Month: begin.AddDate(0, i, 0), Expenses: make(map[models.RecordType]float64)})
+	}
+	return forecast
+}
+
+func forecastApartment(store storage.Store, apt *models.Apartment, recurring []*models.RecurringExpense, dues []*models.PaymentDue,
+	begin time.Time, months int, now time.Time) (*ApartmentForecast, error) {
+	af := &ApartmentForecast{Apartment: apt.Name, Months: newForecastMonths(begin, months)}
+	month := func(date time.Time) *ForecastMonth {
+		i := (date.Year()-begin.Year())*12 + int(date.Month()) - int(begin.Month())
+		if i < 0 || i >= months {
+			return nil
+		}
+		return af.Months[i]
+	}
+
+	rents, err := store.GetExistingRents(*apt)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rents {
+		if m := month(r.DateBegin); m != nil {
+			m.Income += r.Value
+		}
+	}
+
+	// forecasted are the types whose expenses are already expected, so due dates do not estimate them again
+	forecasted := make(map[models.RecordType]bool)
+	contract, err := financing.FindContract(store, apt.Name)
+	if err != nil {
+		return nil, err
+	}
+	if contract != nil {
+		status, err := financing.StoredStatus(store, contract, now)
+		if err != nil {
+			return nil, err
+		}
+		// a contract without a schedule, as one without a term, leaves the installments to the other sources
+		if len(status.Schedule) > 0 {
+			for _, inst := range status.Schedule[status.PaidInstallments:] {
+				if m := month(inst.Date); m != nil && !math.IsNaN(inst.Payment) && !math.IsInf(inst.Payment, 0) {
+					m.Expenses[models.RecordFinancingInstallment] += inst.Payment
+				}
+			}
+			forecasted[models.RecordFinancingInstallment] = true
+		}
+	}
+
+	for _, r := range recurring {
+		if r.Apartment.Name != apt.Name || forecasted[r.Type] {
+			continue
+		}
+		for _, m := range af.Months {
+			if r.IsActiveAt(r.DueDate(m.Month)) {
+				m.Expenses[r.Type] += r.Value
+			}
+		}
+		forecasted[r.Type] = true
+	}
+
+	for _, d := range dues {
+		if d.Apartment.Name != apt.Name || forecasted[d.Type] {
+			continue
+		}
+		spent, err := storage.SpentByMonth(store, *apt, d.Type)
+		if err != nil {
+			return nil, err
+		}
+		estimate, ok := recentAverage(spent, begin)
+		if !ok {
+			continue
+		}
+		for _, m := range af.Months {
+			m.Expenses[d.Type] += estimate
+		}
+		forecasted[d.Type] = true
+	}
+	return af, nil
+}
+
+// recentAverage is the average of the months paid among the ones before begin, false when none was paid recently
+func recentAverage(spent map[time.Time]float64, begin time.Time) (float64, bool) {
+	since := begin.AddDate(0, -dueEstimateMonths, 0)
+	var total float64
+	var paid int
+	for m, v := range spent {
+		if !m.Before(since) && m.Before(begin) && v > 0 {
+			total += v
+			paid++
+		}
+	}
+	if paid == 0 {
+		return 0, false
+	}
+	return total / float64(paid), true
+}
+
+// Format lists the income, expenses and balance of each month, of each apartment and then of all of them, marking
+// the months whose expenses exceed the income
+func (f *Forecast) Format() string {
+	lines := []string{"Previsao de caixa (mês: receitas - despesas = saldo)"}
+	for _, af := range f.Apartments {
+		lines = append(lines, "", af.Apartment)
+		lines = append(lines, formatForecastMonths(af.Months)...)
+	}
+	if len(f.Apartments) > 1 {
+		lines = append(lines, "", "Consolidado")
+		lines = append(lines, formatForecastMonths(f.Consolidated)...)
+	}
+
+	var deficits []string
+	for _, m := range f.Consolidated {
+		if m.Deficit() {
+			deficits = append(deficits, m.Month.Format("01/2006"))
+		}
+	}
+	if len(deficits) > 0 {
+		lines = append(lines, "", fmt.Sprintf("Despesas acima das receitas no consolidado em %v", strings.Join(deficits, ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatForecastMonths(months []*ForecastMonth) []string {
+	var lines []string
+	for _, m := range months {
+		line := fmt.Sprintf("%v: R$%.2f - R$%.2f = R$%.2f", m.Month.Format("01/2006"), m.Income, m.TotalExpenses(), m.Balance())
+		if m.Deficit() {
+			line += " (despesas acima das receitas)"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,187 @@
+package report
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// forecastStore holds the records read by the forecast, the other methods of the store are not expected to be called
+type forecastStore struct {
+	storage.Store
+	rents        map[string][]*models.Rent
+	condos       map[string][]*models.Condo
+	bills        map[string][]*models.EnergyBill
+	installments map[string][]*models.FinancingInstallment
+	recurring    []*models.RecurringExpense
+	dues         []*models.PaymentDue
+	contracts    []*models.FinancingContract
+}
+
+func (s *forecastStore) GetExistingRents(a models.Apartment) ([]*models.Rent, error) {
+	return s.rents[a.Name], nil
+}
+
+func (s *forecastStore) GetPayedCondos(a models.Apartment) ([]*models.Condo, error) {
+	return s.condos[a.Name], nil
+}
+
+func (s *forecastStore) GetPayedBills(a models.Apartment) ([]*models.EnergyBill, error) {
+	return s.bills[a.Name], nil
+}
+
+func (s *forecastStore) GetPayedFinancialInstallments(a models.Apartment) ([]*models.FinancingInstallment, error) {
+	return s.installments[a.Name], nil
+}
+
+func (s *forecastStore) GetPayedAmortizations(models.Apartment) ([]*models.Amortization, error) {
+	return nil, nil
+}
+
+func (s *forecastStore) GetRecurringExpenses() ([]*models.RecurringExpense, error) {
+	return s.recurring, nil
+}
+
+func (s *forecastStore) GetPaymentDues() ([]*models.PaymentDue, error) {
+	return s.dues, nil
+}
+
+func (s *forecastStore) GetFinancingContracts() ([]*models.FinancingContract, error) {
+	return s.contracts, nil
+}
+
+var (
+	centro = &models.Apartment{Name: "Centro"}
+	praia  = &models.Apartment{Name: "Praia"}
+)
+
+func checkForecastMonths(t *testing.T, name string, months []*ForecastMonth, n int) {
+	t.Helper()
+	if len(months) != n {
+		t.Fatalf("%v: %d months, want %d", name, len(months), n)
+	}
+	for _, m := range months {
+		for rt, v := range m.Expenses {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				t.Errorf("%v: %v of %v is %v", name, rt, m.Month.Format("01/2006"), v)
+			}
+		}
+	}
+}
+
+func TestBuildForecast(t *testing.T) {
+	store := &forecastStore{
+		rents: map[string][]*models.Rent{
+			"Centro": {
+				{DateBegin: date(2024, time.June, 10), DateEnd: date(2024, time.June, 14), Value: 1000},
+				{DateBegin: date(2024, time.August, 1), DateEnd: date(2024, time.August, 3), Value: 500},
+				// checked in before the forecast
+				{DateBegin: date(2024, time.May, 2), DateEnd: date(2024, time.May, 4), Value: 800},
+			},
+			"Praia": {{DateBegin: date(2024, time.June, 20), DateEnd: date(2024, time.June, 25), Value: 2000}},
+		},
+		condos: map[string][]*models.Condo{
+			"Centro": {
+				{Date: date(2023, time.January, 10), Value: 300},
+				{Date: date(2024, time.March, 10), Value: 500},
+				{Date: date(2024, time.April, 10), Value: 700},
+			},
+		},
+		bills: map[string][]*models.EnergyBill{
+			// paid long ago, too old to estimate the next ones
+			"Centro": {{Date: date(2022, time.May, 10), Value: 150}},
+		},
+		recurring: []*models.RecurringExpense{
+			{Apartment: *praia, Type: models.RecordCleaning, Value: 200, Day: 5, Start: date(2024, time.January, 1)},
+		},
+		dues: []*models.PaymentDue{
+			{Apartment: *centro, Type: models.RecordCondo, Day: 10},
+			{Apartment: *centro, Type: models.RecordEnergyBill, Day: 15},
+		},
+	}
+
+	f, err := BuildForecast(store, []*models.Apartment{centro, praia}, time.Date(2024, time.May, 20, 9, 0, 0, 0, time.Local), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Apartments) != 2 {
+		t.Fatalf("%d apartments, want 2", len(f.Apartments))
+	}
+	checkForecastMonths(t, "Centro", f.Apartments[0].Months, 3)
+	checkForecastMonths(t, "Consolidado", f.Consolidated, 3)
+
+	c := f.Apartments[0].Months
+	if !c[0].Month.Equal(date(2024, time.June, 1)) {
+		t.Errorf("forecast begins at %v, want 06/2024", c[0].Month)
+	}
+	if c[0].Income != 1000 || c[1].Income != 0 || c[2].Income != 500 {
+		t.Errorf("income of Centro %v, %v, %v, want 1000, 0, 500", c[0].Income, c[1].Income, c[2].Income)
+	}
+	for _, m := range c {
+		// the average of March and April, the condo of 2023 being too old
+		if got := m.Expenses[models.RecordCondo]; !near(got, 600) {
+			t.Errorf("condo of %v estimated as %.2f, want 600", m.Month.Format("01/2006"), got)
+		}
+		if got, ok := m.Expenses[models.RecordEnergyBill]; ok {
+			t.Errorf("energy bill of %v estimated as %.2f from a bill of 2022", m.Month.Format("01/2006"), got)
+		}
+	}
+
+	if got := f.Consolidated[0].Income; got != 3000 {
+		t.Errorf("consolidated income of 06/2024 %v, want 3000", got)
+	}
+	if got := f.Consolidated[0].TotalExpenses(); !near(got, 800) {
+		t.Errorf("consolidated expenses of 06/2024 %.2f, want 800", got)
+	}
+}
+
+func TestBuildForecastFinancing(t *testing.T) {
+	contract := &models.FinancingContract{
+		Apartment:  *centro,
+		Principal:  12000,
+		AnnualRate: 12,
+		Term:       12,
+		System:     models.SystemSAC,
+		Start:      date(2024, time.January, 10),
+	}
+	store := &forecastStore{
+		installments: map[string][]*models.FinancingInstallment{
+			"Centro": {
+				{Date: date(2024, time.January, 10), Value: 1100},
+				{Date: date(2024, time.February, 10), Value: 1090},
+			},
+		},
+		recurring: []*models.RecurringExpense{
+			{Apartment: *centro, Type: models.RecordFinancingInstallment, Value: 5000, Day: 10, Start: date(2024, time.January, 1)},
+		},
+		contracts: []*models.FinancingContract{contract},
+	}
+
+	f, err := BuildForecast(store, []*models.Apartment{centro}, date(2024, time.February, 20), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkForecastMonths(t, "Centro", f.Apartments[0].Months, 3)
+	for _, m := range f.Apartments[0].Months {
+		// SAC amortizes 1000 a month, plus the interest
+		if got := m.Expenses[models.RecordFinancingInstallment]; got < 1000 || got > 1100 {
+			t.Errorf("installment of %v is %.2f, want the one of the schedule", m.Month.Format("01/2006"), got)
+		}
+	}
+
+	// a contract without a term has no schedule, leaving the installment to the recurring expense
+	contract.Term = 0
+	f, err = BuildForecast(store, []*models.Apartment{centro}, date(2024, time.February, 20), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkForecastMonths(t, "Centro without term", f.Apartments[0].Months, 3)
+	for _, m := range f.Apartments[0].Months {
+		if got := m.Expenses[models.RecordFinancingInstallment]; got != 5000 {
+			t.Errorf("installment of %v is %.2f, want the recurring 5000", m.Month.Format("01/2006"), got)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package report
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Period is a month or a year of records, from Begin until the day before End
+type Period struct {
+	Begin time.Time
+	End   time.Time
+	Label string
+}
+
+// ParsePeriod reads a month as 03/2024 or a year as 2024, an empty period being the month before now
+func ParsePeriod(s string, now time.Time) (*Period, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+		return monthPeriod(month), nil
+	}
+	if month, err := time.Parse("01/2006", s); err == nil {
+		return monthPeriod(month), nil
+	}
+	if year, err := strconv.Atoi(s); err == nil && year > 1900 {
+		begin := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		return &Period{Begin: begin, End: begin.AddDate(1, 0, 0), Label: s}, nil
+	}
+	return nil, fmt.Errorf("%v nao é um período válido, informe um mês como 03/2024 ou um ano como 2024", s)
+}
+
+func monthPeriod(month time.Time) *Period {
+	return &Period{Begin: month, End: month.AddDate(0, 1, 0), Label: month.Format("01/2006")}
+}
+
+// LastMonths is the period of the n whole months before the month of now, which is still being recorded
+func LastMonths(n int, now time.Time) *Period {
+	end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return &Period{Begin: end.AddDate(0, -n, 0), End: end, Label: fmt.Sprintf("últimos %d meses", n)}
+}
@@ -0,0 +1,214 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gustavolopess/hoteleiro/internal/financing"
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// operatingExpenses are the expenses of running the apartment, the financing ones being its cost of capital
+var operatingExpenses = []models.RecordType{
+	models.RecordEnergyBill,
+	models.RecordCondo,
+	models.RecordCleaning,
+	models.RecordMiscellaneousExpense,
+}
+
+// Profitability is the return of an apartment in a period. Returns are yearly, the period being scaled to a year so
+// apartments and periods of different lengths compare
+type Profitability struct {
+	Apartment         string
+	Period            *Period
+	Income            float64
+	OperatingExpenses float64
+	// Interest and Amortization split the installments paid in the period by the schedule of the financing, the
+	// fees and insurances paid besides the scheduled installment counting as interest. Without a contract the
+	// installments cannot be split and all of them count as interest
+	Interest     float64
+	Amortization float64
+	Financed     bool
+	// PurchasePrice comes from the registry of the apartment, DownPayment is the part of it not financed. Without a
+	// contract the down payment is only known when no installments were paid, the price being paid in cash
+	PurchasePrice      float64
+	DownPayment        float64
+	UnknownDownPayment bool
+	// ExtraAmortizations are every amortization paid until the end of the period, which are invested as the down
+	// payment is
+	ExtraAmortizations float64
+}
+
+// NetOperatingIncome is the income left after the operating expenses
+func (p *Profitability) NetOperatingIncome() float64 {
+	return p.Income - p.OperatingExpenses
+}
+
+// CashFlow is what is left to the partners after the installments of the financing
+func (p *Profitability) CashFlow() float64 {
+	return p.NetOperatingIncome() - p.Interest - p.Amortization
+}
+
+// Invested is the cash the partners put in the apartment
+func (p *Profitability) Invested() float64 {
+	return p.DownPayment + p.ExtraAmortizations
+}
+
+// CapRate is the yearly net operating income over the purchase price, zero without a price
+func (p *Profitability) CapRate() float64 {
+	if p.PurchasePrice <= 0 {
+		return 0
+	}
+	return p.yearly(p.NetOperatingIncome()) / p.PurchasePrice
+}
+
+// CashOnCash is the yearly cash flow over the cash invested, zero without it
+func (p *Profitability) CashOnCash() float64 {
+	if p.UnknownDownPayment || p.Invested() <= 0 {
+		return 0
+	}
+	return p.yearly(p.CashFlow()) / p.Invested()
+}
+
+// Payback is the years the cash flow of the period takes to return the cash invested, false when it never does
+func (p *Profitability) Payback() (float64, bool) {
+	cashFlow := p.yearly(p.CashFlow())
+	if p.UnknownDownPayment || cashFlow <= 0 || p.Invested() <= 0 {
+		return 0, false
+	}
+	return p.Invested() / cashFlow, true
+}
+
+func (p *Profitability) yearly(value float64) float64 {
+	months := (p.Period.End.Year()-p.Period.Begin.Year())*12 + int(p.Period.End.Month()) - int(p.Period.Begin.Month())
+	if months <= 0 {
+		return value
+	}
+	return value * 12 / float64(months)
+}
+
+// BuildProfitability gathers the income, expenses and financing of the apartment in the period
+func BuildProfitability(store storage.Store, apartment *models.Apartment, period *Period) (*Profitability, error) {
+	statement, err := BuildStatement(store, *apartment, period)
+	if err != nil {
+		return nil, err
+	}
+	p := &Profitability{
+		Apartment:     apartment.Name,
+		Period:        period,
+		Income:        statement.Income(),
+		PurchasePrice: apartment.PurchasePrice,
+		DownPayment:   apartment.PurchasePrice,
+	}
+	for _, t := range operatingExpenses {
+		p.OperatingExpenses += statement.CategoryTotal(t)
+	}
+	amortizations, err := store.GetPayedAmortizations(*apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range amortizations {
+		if a.Date.Before(period.End) {
+			p.ExtraAmortizations += a.Value
+		}
+	}
+
+	contract, err := financing.FindContract(store, apartment.Name)
+	if err != nil {
+		return nil, err
+	}
+	if contract == nil {
+		p.Interest = statement.CategoryTotal(models.RecordFinancingInstallment)
+		installments, err := store.GetPayedFinancialInstallments(*apartment)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range installments {
+			if i.Date.Before(period.End) {
+				p.UnknownDownPayment = true
+				break
+			}
+		}
+		return p, nil
+	}
+
+	status, err := financing.StoredStatus(store, contract, period.End)
+	if err != nil {
+		return nil, err
+	}
+	p.Financed = true
+	p.DownPayment = apartment.PurchasePrice - contract.Principal
+	if p.DownPayment < 0 {
+		p.DownPayment = 0
+	}
+	for _, payment := range status.Payments {
+		if payment.Date.Before(period.Begin) || !payment.Date.Before(period.End) {
+			continue
+		}
+		if payment.Installment == nil {
+			p.Interest += payment.Value
+			continue
+		}
+		p.Amortization += payment.Installment.Amortization
+		p.Interest += payment.Value - payment.Installment.Amortization
+	}
+	return p, nil
+}
+
+// Format describes the return of the apartment, one figure per line
+func (p *Profitability) Format() string {
+	lines := []string{
+		fmt.Sprintf("%v - %v", p.Apartment, p.Period.Label),
+		fmt.Sprintf("Receitas: R$%.2f", p.Income),
+		fmt.Sprintf("Despesas operacionais: R$%.2f", p.OperatingExpenses),
+		fmt.Sprintf("Resultado operacional: R$%.2f", p.NetOperatingIncome()),
+	}
+	if p.Financed {
+		lines = append(lines, fmt.Sprintf("Financiamento: R$%.2f de juros e R$%.2f de amortizaçao", p.Interest, p.Amortization))
+	} else if p.Interest > 0 {
+		lines = append(lines, fmt.Sprintf("Financiamento: R$%.2f em parcelas, sem contrato para separar os juros", p.Interest))
+	}
+	lines = append(lines, fmt.Sprintf("Fluxo de caixa: R$%.2f", p.CashFlow()))
+	if !p.UnknownDownPayment {
+		lines = append(lines, fmt.Sprintf("Investido: R$%.2f (entrada de R$%.2f e R$%.2f em amortizaçoes)", p.Invested(), p.DownPayment, p.ExtraAmortizations))
+	}
+	if p.PurchasePrice > 0 {
+		lines = append(lines, fmt.Sprintf("Cap rate: %.2f%% ao ano", p.CapRate()*100))
+	} else {
+		lines = append(lines, "Cap rate: informe o preço de compra no cadastro do imóvel")
+	}
+	if p.UnknownDownPayment {
+		lines = append(lines, "Cash-on-cash e payback: cadastre o financiamento (/financiamento) para saber a entrada paga")
+		return strings.Join(lines, "\n")
+	}
+	if p.Invested() > 0 {
+		lines = append(lines, fmt.Sprintf("Retorno sobre o investido (cash-on-cash): %.2f%% ao ano", p.CashOnCash()*100))
+	}
+	if years, ok := p.Payback(); ok {
+		lines = append(lines, fmt.Sprintf("Payback: %.1f anos", years))
+	} else {
+		lines = append(lines, "Payback: sem fluxo de caixa positivo para retornar o investido")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CompareProfitability ranks the apartments by their cash-on-cash return, one per line
+func CompareProfitability(profits []*Profitability) string {
+	ranked := append([]*Profitability(nil), profits...)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].CashOnCash() > ranked[j].CashOnCash() })
+
+	lines := []string{"Comparativo (cash-on-cash, cap rate, payback)"}
+	for i, p := range ranked {
+		cashOnCash, payback := "-", "-"
+		if !p.UnknownDownPayment {
+			cashOnCash = fmt.Sprintf("%.2f%%", p.CashOnCash()*100)
+		}
+		if years, ok := p.Payback(); ok {
+			payback = fmt.Sprintf("%.1f anos", years)
+		}
+		lines = append(lines, fmt.Sprintf("%d. %v: %v, %.2f%%, %v", i+1, p.Apartment, cashOnCash, p.CapRate()*100, payback))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,37 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLastMonthsEndsBeforeTheCurrentMonth(t *testing.T) {
+	p := LastMonths(12, time.Date(2024, time.April, 15, 10, 0, 0, 0, time.Local))
+	if !p.Begin.Equal(date(2023, time.April, 1)) || !p.End.Equal(date(2024, time.April, 1)) {
+		t.Errorf("period from %v to %v, want 01/04/2023 to 01/04/2024", p.Begin, p.End)
+	}
+}
+
+func TestProfitabilityWithoutDownPayment(t *testing.T) {
+	p := &Profitability{
+		Period:        LastMonths(12, date(2024, time.April, 15)),
+		Income:        30000,
+		Interest:      12000,
+		PurchasePrice: 400000,
+		DownPayment:   400000,
+	}
+	if p.CashOnCash() <= 0 {
+		t.Errorf("cash-on-cash %v with the price paid in cash, want it positive", p.CashOnCash())
+	}
+
+	p.UnknownDownPayment = true
+	if p.CashOnCash() != 0 {
+		t.Errorf("cash-on-cash %v without the down payment, want 0", p.CashOnCash())
+	}
+	if _, ok := p.Payback(); ok {
+		t.Error("payback without the down payment")
+	}
+	if p.CapRate() != 30000.0/400000 {
+		t.Errorf("cap rate %v, want %v", p.CapRate(), 30000.0/400000)
+	}
+}
@@ -0,0 +1,293 @@
+package report
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/pdf"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+var (
+	incomeColor  = color.RGBA{R: 0x2e, G: 0x7d, B: 0x32, A: 0xff}
+	expenseColor = color.RGBA{R: 0xc6, G: 0x28, B: 0x28, A: 0xff}
+)
+
+// StatementEntry is an expense of the statement
+type StatementEntry struct {
+	Date        time.Time
+	Description string
+	Payer       string
+	Value       float64
+}
+
+// Transfer is a payment one partner owes another to settle the statement
+type Transfer struct {
+	From  string
+	To    string
+	Value float64
+}
+
+// PartnerBalance is what a partner received and paid in the period
+type PartnerBalance struct {
+	Partner  string
+	Received float64
+	Paid     float64
+}
+
+// Statement is the income and the expenses of an apartment in a period, settled between the partners in equal shares
+type Statement struct {
+	Apartment string
+	Period    *Period
+	Rents     []*models.Rent
+	Expenses  map[models.RecordType][]*StatementEntry
+	Partners  []*PartnerBalance
+	Transfers []*Transfer
+}
+
+func (s *Statement) Income() float64 {
+	var total float64
+	for _, r := range s.Rents {
+		total += r.Value
+	}
+	return total
+}
+
+func (s *Statement) CategoryTotal(t models.RecordType) float64 {
+	var total float64
+	for _, e := range s.Expenses[t] {
+		total += e.Value
+	}
+	return total
+}
+
+func (s *Statement) TotalExpenses() float64 {
+	var total float64
+	for t := range s.Expenses {
+		total += s.CategoryTotal(t)
+	}
+	return total
+}
+
+// BuildStatement gathers the records of the apartment in the period, rents count in the period they begin
+func BuildStatement(store storage.Store, apartment models.Apartment, period *Period) (*Statement, error) {
+	s := &Statement{Apartment: apartment.Name, Period: period, Expenses: make(map[models.RecordType][]*StatementEntry)}
+	inPeriod := func(date time.Time) bool {
+		return !date.Before(period.Begin) && date.Before(period.End)
+	}
+	addExpense := func(t models.RecordType, e *StatementEntry) {
+		if inPeriod(e.Date) {
+			s.Expenses[t] = append(s.Expenses[t], e)
+		}
+	}
+
+	rents, err := store.GetExistingRents(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rents {
+		if inPeriod(r.DateBegin) {
+			s.Rents = append(s.Rents, r)
+		}
+	}
+
+	bills, err := store.GetPayedBills(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bills {
+		addExpense(models.RecordEnergyBill, &StatementEntry{Date: b.Date, Payer: b.Payer, Value: b.Value})
+	}
+
+	condos, err := store.GetPayedCondos(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range condos {
+		addExpense(models.RecordCondo, &StatementEntry{Date: c.Date, Payer: c.Payer, Value: c.Value})
+	}
+
+	cleanings, err := store.GetPayedCleanings(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cleanings {
+		addExpense(models.RecordCleaning, &StatementEntry{Date: c.Date, Payer: c.Payer, Value: c.Value})
+	}
+
+	expenses, err := store.GetMiscellaneousExpenses(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range expenses {
+		addExpense(models.RecordMiscellaneousExpense, &StatementEntry{Date: e.Date, Description: e.Description, Payer: e.Payer, Value: e.Value})
+	}
+
+	installments, err := store.GetPayedFinancialInstallments(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, fi := range installments {
+		addExpense(models.RecordFinancingInstallment, &StatementEntry{Date: fi.Date, Payer: fi.Payer, Value: fi.Value})
+	}
+
+	amortizations, err := store.GetPayedAmortizations(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range amortizations {
+		addExpense(models.RecordAmortization, &StatementEntry{Date: a.Date, Payer: a.Payer, Value: a.Value})
+	}
+
+	users, err := store.GetUsers()
+	if err != nil {
+		return nil, err
+	}
+	s.settle(models.PartnerNames(users))
+	return s, nil
+}
+
+// settle splits the balance equally among the partners, even the ones who received or paid nothing in the period,
+// and finds the transfers which leave each one with their share. Receivers and payers out of the partners are
+// settled as partners too, while the records without one are left out of the settlement
+func (s *Statement) settle(partners []string) {
+	byPartner := make(map[string]*PartnerBalance)
+	partner := func(name string) *PartnerBalance {
+		if _, ok := byPartner[name]; !ok {
+			byPartner[name] = &PartnerBalance{Partner: name}
+			s.Partners = append(s.Partners, byPartner[name])
+		}
+		return byPartner[name]
+	}
+	for _, p := range partners {
+		partner(p)
+	}
+	var result float64
+	for _, r := range s.Rents {
+		if len(r.Receiver) > 0 {
+			partner(r.Receiver).Received += r.Value
+			result += r.Value
+		}
+	}
+	for _, entries := range s.Expenses {
+		for _, e := range entries {
+			if len(e.Payer) > 0 {
+				partner(e.Payer).Paid += e.Value
+				result -= e.Value
+			}
+		}
+	}
+	sort.Slice(s.Partners, func(i, j int) bool { return s.Partners[i].Partner < s.Partners[j].Partner })
+	if len(s.Partners) < 2 {
+		return
+	}
+
+	share := result / float64(len(s.Partners))
+	// positive excess is held beyond the share and must be handed to the partners below theirs
+	type excess struct {
+		partner string
+		value   float64
+	}
+	var debtors, creditors []*excess
+	for _, p := range s.Partners {
+		e := p.Received - p.Paid - share
+		if e > 0.005 {
+			debtors = append(debtors, &excess{p.Partner, e})
+		} else if e < -0.005 {
+			creditors = append(creditors, &excess{p.Partner, -e})
+		}
+	}
+	for len(debtors) > 0 && len(creditors) > 0 {
+		value := math.Min(debtors[0].value, creditors[0].value)
+		s.Transfers = append(s.Transfers, &Transfer{From: debtors[0].partner, To: creditors[0].partner, Value: value})
+		debtors[0].value -= value
+		creditors[0].value -= value
+		if debtors[0].value < 0.005 {
+			debtors = debtors[1:]
+		}
+		if creditors[0].value < 0.005 {
+			creditors = creditors[1:]
+		}
+	}
+}
+
+// PDF writes the statement with its income, the expenses of each category, the totals, the settlement between the
+// partners and a chart of the income against each category
+func (s *Statement) PDF() []byte {
+	doc := pdf.New()
+	doc.Heading(fmt.Sprintf("Demonstrativo de %v - %v", s.Apartment, s.Period.Label))
+	doc.Line(fmt.Sprintf("De %v a %v", s.Period.Begin.Format("02/01/2006"), s.Period.End.AddDate(0, 0, -1).Format("02/01/2006")))
+	doc.Blank()
+
+	doc.Heading("Receitas")
+	doc.Line(fmt.Sprintf("%-10s %-10s %-35s %-12s %14s", "Entrada", "Saída", "Inquilino", "Recebedor", "Valor"))
+	for _, r := range s.Rents {
+		doc.Line(fmt.Sprintf("%-10s %-10s %-35s %-12s %14s", r.DateBegin.Format("02/01/2006"), r.DateEnd.Format("02/01/2006"),
+			truncate(r.Renter, 35), truncate(r.Receiver, 12), money(r.Value)))
+	}
+	doc.Line(fmt.Sprintf("%-70s %14s", "Total", money(s.Income())))
+	doc.Blank()
+
+	doc.Heading("Despesas")
+	for _, t := range models.RecordTypes {
+		entries := s.Expenses[t]
+		if len(entries) == 0 {
+			continue
+		}
+		doc.Line(string(t))
+		for _, e := range entries {
+			doc.Line(fmt.Sprintf("  %-10s %-44s %-12s %14s", e.Date.Format("02/01/2006"), truncate(e.Description, 44),
+				truncate(e.Payer, 12), money(e.Value)))
+		}
+		doc.Line(fmt.Sprintf("  %-68s %14s", "Subtotal", money(s.CategoryTotal(t))))
+	}
+	doc.Line(fmt.Sprintf("%-70s %14s", "Total", money(s.TotalExpenses())))
+	doc.Blank()
+
+	doc.Heading("Resultado")
+	doc.Line(fmt.Sprintf("%-70s %14s", "Receitas", money(s.Income())))
+	doc.Line(fmt.Sprintf("%-70s %14s", "Despesas", money(s.TotalExpenses())))
+	doc.Line(fmt.Sprintf("%-70s %14s", "Saldo", money(s.Income()-s.TotalExpenses())))
+	doc.Blank()
+
+	doc.Heading("Acerto entre sócios")
+	doc.Line(fmt.Sprintf("%-24s %14s %14s", "Sócio", "Recebeu", "Pagou"))
+	for _, p := range s.Partners {
+		doc.Line(fmt.Sprintf("%-24s %14s %14s", truncate(p.Partner, 24), money(p.Received), money(p.Paid)))
+	}
+	for _, t := range s.Transfers {
+		doc.Line(fmt.Sprintf("%v paga %v a %v", t.From, money(t.Value), t.To))
+	}
+	if len(s.Transfers) == 0 {
+		doc.Line("Nenhum acerto necessário")
+	}
+	doc.Blank()
+
+	doc.Heading("Receitas e despesas")
+	bars := []pdf.Bar{{Label: "Receitas", Value: s.Income(), Color: incomeColor}}
+	for _, t := range models.RecordTypes {
+		if len(s.Expenses[t]) > 0 {
+			bars = append(bars, pdf.Bar{Label: string(t), Value: s.CategoryTotal(t), Color: expenseColor})
+		}
+	}
+	doc.BarChart(bars)
+
+	return doc.Bytes()
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("R$%.2f", v)
+}
+
+// truncate cuts the text to fit its column
+func truncate(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+	return string(runes[:width-1]) + "."
+}
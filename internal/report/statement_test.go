@@ -0,0 +1,102 @@
+package report
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// escaped matches the characters escaped in the PDF strings
+var escaped = regexp.MustCompile(`\\([0-7]{3}|.)`)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func testStatement() *Statement {
+	period, _ := ParsePeriod("03/2024", date(2024, time.April, 10))
+	return &Statement{
+		Apartment: "Centro",
+		Period:    period,
+		Rents: []*models.Rent{
+			{DateBegin: date(2024, time.March, 2), DateEnd: date(2024, time.March, 6), Value: 1200, Renter: "Maria Aparecida de Souza Albuquerque Lima", Receiver: "Ana"},
+			{DateBegin: date(2024, time.March, 15), DateEnd: date(2024, time.March, 18), Value: 900, Renter: "Joao Lima", Receiver: "Ana"},
+		},
+		Expenses: map[models.RecordType][]*StatementEntry{
+			models.RecordCleaning: {{Date: date(2024, time.March, 6), Payer: "Ana", Value: 150}},
+			models.RecordCondo:    {{Date: date(2024, time.March, 10), Payer: "Bruno", Value: 600}},
+			models.RecordMiscellaneousExpense: {
+				{Date: date(2024, time.March, 20), Description: "Taxa de serviço Airbnb HMABCD1234", Value: 63},
+			},
+		},
+	}
+}
+
+func TestStatementPDF(t *testing.T) {
+	s := testStatement()
+	s.settle([]string{"Ana", "Bruno", "Carla"})
+	got := s.PDF()
+
+	golden := filepath.Join("testdata", "statement.golden")
+	if *update {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("PDF differs from %v, run the test with -update after checking the change", golden)
+	}
+
+	for _, line := range strings.Split(string(got), "\n") {
+		if strings.HasPrefix(line, "BT /F1") {
+			text := escaped.ReplaceAllString(line[strings.Index(line, "(")+1:strings.LastIndex(line, ")")], "?")
+			if width := len(text); width > 85 {
+				t.Errorf("line wider than 85 columns: %q", text)
+			}
+		}
+	}
+}
+
+func TestSettle(t *testing.T) {
+	s := testStatement()
+	s.settle([]string{"Ana", "Bruno", "Carla"})
+
+	if len(s.Partners) != 3 {
+		t.Fatalf("partners %v, want Ana, Bruno and Carla", s.Partners)
+	}
+	for _, p := range s.Partners {
+		if len(p.Partner) == 0 {
+			t.Errorf("partner without a name: %+v", p)
+		}
+	}
+
+	// 2100 received by Ana and 750 paid by Ana and Bruno, the expense without a payer left out: 450 for each
+	want := map[string]float64{"Ana": 1500, "Bruno": -1050, "Carla": -450}
+	received := make(map[string]float64)
+	for _, tr := range s.Transfers {
+		received[tr.From] += tr.Value
+		received[tr.To] -= tr.Value
+	}
+	for partner, value := range want {
+		if got := received[partner]; !near(got, value) {
+			t.Errorf("%v hands %.2f, want %.2f (transfers %+v)", partner, got, value, s.Transfers)
+		}
+	}
+}
+
+func near(a, b float64) bool {
+	return a-b < 0.01 && b-a < 0.01
+}
@@ -0,0 +1,151 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// ApartmentSummary is the income and the expenses of an apartment in a period
+type ApartmentSummary struct {
+	Apartment string
+	Income    float64
+	Expenses  map[models.RecordType]float64
+}
+
+func (s *ApartmentSummary) TotalExpenses() float64 {
+	var total float64
+	for _, v := range s.Expenses {
+		total += v
+	}
+	return total
+}
+
+func (s *ApartmentSummary) Balance() float64 {
+	return s.Income - s.TotalExpenses()
+}
+
+// Summarize sums the records of every apartment dated from begin until the day before end, rents count in the period
+// they begin, archived apartments included
+func Summarize(store storage.Store, begin, end time.Time) ([]*ApartmentSummary, error) {
+	apartments, err := store.GetApartments()
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []*ApartmentSummary
+	for _, apt := range apartments {
+		summary, err := summarizeApartment(store, *apt, begin, end)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+func summarizeApartment(store storage.Store, apt models.Apartment, begin, end time.Time) (*ApartmentSummary, error) {
+	summary := &ApartmentSummary{Apartment: apt.Name, Expenses: make(map[models.RecordType]float64)}
+	inPeriod := func(date time.Time) bool {
+		return !date.Before(begin) && date.Before(end)
+	}
+
+	rents, err := store.GetExistingRents(apt)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rents {
+		if inPeriod(r.DateBegin) {
+			summary.Income += r.Value
+		}
+	}
+
+	condos, err := store.GetPayedCondos(apt)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range condos {
+		if inPeriod(c.Date) {
+			summary.Expenses[models.RecordCondo] += c.Value
+		}
+	}
+
+	bills, err := store.GetPayedBills(apt)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bills {
+		if inPeriod(b.Date) {
+			summary.Expenses[models.RecordEnergyBill] += b.Value
+		}
+	}
+
+	cleanings, err := store.GetPayedCleanings(apt)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cleanings {
+		if inPeriod(c.Date) {
+			summary.Expenses[models.RecordCleaning] += c.Value
+		}
+	}
+
+	expenses, err := store.GetMiscellaneousExpenses(apt)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range expenses {
+		if inPeriod(e.Date) {
+			summary.Expenses[models.RecordMiscellaneousExpense] += e.Value
+		}
+	}
+
+	installments, err := store.GetPayedFinancialInstallments(apt)
+	if err != nil {
+		return nil, err
+	}
+	for _, fi := range installments {
+		if inPeriod(fi.Date) {
+			summary.Expenses[models.RecordFinancingInstallment] += fi.Value
+		}
+	}
+
+	amortizations, err := store.GetPayedAmortizations(apt)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range amortizations {
+		if inPeriod(a.Date) {
+			summary.Expenses[models.RecordAmortization] += a.Value
+		}
+	}
+
+	return summary, nil
+}
+
+// FormatSummaries writes the summaries as a chat message
+func FormatSummaries(summaries []*ApartmentSummary, begin, end time.Time) string {
+	lines := []string{fmt.Sprintf("Resumo de %v a %v", begin.Format("02/01/2006"), end.AddDate(0, 0, -1).Format("02/01/2006"))}
+	if len(summaries) == 0 {
+		return lines[0] + "\nNenhum imóvel cadastrado"
+	}
+
+	var income, expenses float64
+	for _, s := range summaries {
+		lines = append(lines, "", s.Apartment, fmt.Sprintf("Receitas: R$%.2f", s.Income))
+		for _, t := range models.RecordTypes {
+			if v, ok := s.Expenses[t]; ok {
+				lines = append(lines, fmt.Sprintf("%v: R$%.2f", t, v))
+			}
+		}
+		lines = append(lines, fmt.Sprintf("Saldo: R$%.2f", s.Balance()))
+		income += s.Income
+		expenses += s.TotalExpenses()
+	}
+	lines = append(lines, "", fmt.Sprintf("Total: receitas R$%.2f, despesas R$%.2f, saldo R$%.2f", income, expenses, income-expenses))
+
+	return strings.Join(lines, "\n")
+}
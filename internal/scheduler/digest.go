@@ -0,0 +1,200 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/format"
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+const (
+	digestCallbackPrefix  = "faxina:"
+	scheduleCleaningLabel = "Agendar faxina"
+)
+
+// Digest sends every morning the check-ins and check-outs of the day to the allowed group chats, or to the admins
+// and partners when there is no group, offering to schedule the cleaning of each apartment left. The day of the last
+// digest is kept in the store, so a restart doesn't send it again
+type Digest struct {
+	store    storage.Store
+	notifier Notifier
+	hour     int
+
+	// lastSent is read from the store on the first run, the scheduler runs the jobs one at a time
+	lastSent *time.Time
+}
+
+func NewDigest(store storage.Store, notifier Notifier, hour int) *Digest {
+	return &Digest{
+		store:    store,
+		notifier: notifier,
+		hour:     hour,
+	}
+}
+
+func (d *Digest) Name() string {
+	return "check-in and check-out digest"
+}
+
+// Run sends the digest of the day once its hour has come
+func (d *Digest) Run(now time.Time) error {
+	if d.lastSent == nil {
+		run, err := d.store.GetJobRun(d.Name())
+		if err != nil {
+			return err
+		}
+		d.lastSent = &run.LastRun
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if now.Hour() < d.hour || !d.lastSent.Before(today) {
+		return nil
+	}
+
+	apartments, err := d.store.GetApartments()
+	if err != nil {
+		return err
+	}
+
+	var checkIns, checkOuts []string
+	keyboard := tgbotapi.NewInlineKeyboardMarkup()
+	for _, apt := range apartments {
+		if apt.Archived {
+			continue
+		}
+		rents, err := d.store.GetExistingRents(*apt)
+		if err != nil {
+			return err
+		}
+		for _, r := range rents {
+			if isSameDay(r.DateBegin, today) {
+				checkIns = append(checkIns, fmt.Sprintf("- %v: %v até %v", apt.Name, r.Renter, r.DateEnd.Format("02/01")))
+			}
+			if isSameDay(r.DateEnd, today) {
+				checkOuts = append(checkOuts, fmt.Sprintf("- %v: %v", apt.Name, r.Renter))
+				label := fmt.Sprintf("%s: %s", scheduleCleaningLabel, apt.Name)
+				data := format.CallbackData(digestCallbackPrefix, strconv.FormatInt(apt.Id, 10), today.Format("2006-01-02"))
+				keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(label, data),
+				))
+			}
+		}
+	}
+
+	if err := d.store.SetJobRun(&models.JobRun{Job: d.Name(), LastRun: now}); err != nil {
+		return err
+	}
+	d.lastSent = &now
+
+	if len(checkIns) == 0 && len(checkOuts) == 0 {
+		return nil
+	}
+
+	lines := []string{fmt.Sprintf("Movimento de hoje, %v", today.Format("02/01/2006"))}
+	if len(checkIns) > 0 {
+		lines = append(lines, "Entradas:")
+		lines = append(lines, checkIns...)
+	}
+	if len(checkOuts) > 0 {
+		lines = append(lines, "Saídas:")
+		lines = append(lines, checkOuts...)
+	}
+	var markup interface{}
+	if len(keyboard.InlineKeyboard) > 0 {
+		markup = keyboard
+	}
+
+	chats, err := d.recipients()
+	if err != nil {
+		return err
+	}
+	for _, chatId := range chats {
+		if err := d.notifier.Notify(chatId, strings.Join(lines, "\n"), markup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recipients are the allowed group chats or, without any, the private chats of the admins and partners
+func (d *Digest) recipients() ([]int64, error) {
+	chats, err := d.store.GetAllowedChats()
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	for _, c := range chats {
+		ids = append(ids, c.Id)
+	}
+	if len(ids) > 0 {
+		return ids, nil
+	}
+
+	users, err := d.store.GetUsers()
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		if u.Role == models.RoleAdmin || u.Role == models.RolePartner {
+			ids = append(ids, u.Id)
+		}
+	}
+	return ids, nil
+}
+
+// Handles reports whether the keyboard answer asks to schedule a cleaning offered by this job
+func (d *Digest) Handles(answer string) bool {
+	return strings.HasPrefix(answer, digestCallbackPrefix)
+}
+
+// Answer returns the apartment and date of the cleaning to be scheduled, or the reply to the chat when it can't be
+// scheduled
+func (d *Digest) Answer(answer string) (string, time.Time, string) {
+	parts, ok := format.ParseCallbackData(answer, digestCallbackPrefix, 2)
+	if !ok {
+		return "", time.Time{}, "Resposta inválida"
+	}
+	apartmentId, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return "", time.Time{}, "Resposta inválida"
+	}
+	date, err := time.ParseInLocation("2006-01-02", parts[1], time.Local)
+	if err != nil {
+		return "", time.Time{}, "Resposta inválida"
+	}
+
+	apartments, err := d.store.GetApartments()
+	if err != nil {
+		return "", time.Time{}, fmt.Sprintf("Falha ao buscar os imóveis - %v", err.Error())
+	}
+	apartment := ""
+	for _, a := range apartments {
+		if a.Id == apartmentId {
+			apartment = a.Name
+		}
+	}
+	if len(apartment) == 0 {
+		return "", time.Time{}, "Esse imóvel nao existe mais"
+	}
+
+	cleanings, err := d.store.GetScheduledCleanings()
+	if err != nil {
+		return "", time.Time{}, fmt.Sprintf("Falha ao buscar as faxinas agendadas - %v", err.Error())
+	}
+	for _, c := range cleanings {
+		if c.Apartment.Name == apartment && isSameDay(c.Date, date) {
+			return "", time.Time{}, "Essa faxina já foi agendada"
+		}
+	}
+	return apartment, date, ""
+}
+
+func isSameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.Month() == b.Month() && a.Day() == b.Day()
+}
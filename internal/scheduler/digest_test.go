@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+func TestDigestAfterRestart(t *testing.T) {
+	apartment := models.Apartment{Name: "Centro", Id: 42}
+	store := &fakeStore{
+		apartments: []*models.Apartment{&apartment},
+		rents:      []*models.Rent{{DateBegin: day(2024, 5, 1), DateEnd: day(2024, 5, 3), Renter: "Ana"}},
+		chats:      []*models.AllowedChat{{Id: 7}},
+	}
+	notifier := &fakeNotifier{}
+	morning := time.Date(2024, 5, 3, 8, 30, 0, 0, time.Local)
+
+	if err := NewDigest(store, notifier, 8).Run(morning); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.sent) != 1 {
+		t.Fatalf("sent %d digests, want 1", len(notifier.sent))
+	}
+	schedule := *notifier.sent[0].markup.(tgbotapi.InlineKeyboardMarkup).InlineKeyboard[0][0].CallbackData
+
+	restarted := NewDigest(store, notifier, 8)
+	if err := restarted.Run(morning.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.sent) != 1 {
+		t.Errorf("sent %d digests after a restart, want 1", len(notifier.sent))
+	}
+
+	name, date, reply := restarted.Answer(schedule)
+	if len(reply) > 0 || name != "Centro" || !isSameDay(date, day(2024, 5, 3)) {
+		t.Fatalf("answer = %q, %v, %q, want the cleaning of Centro on 03/05/2024", name, date, reply)
+	}
+
+	store.cleanings = []*models.ScheduledCleaning{{Apartment: apartment, Date: day(2024, 5, 3)}}
+	if _, _, reply := restarted.Answer(schedule); reply != "Essa faxina já foi agendada" {
+		t.Errorf("reply = %q once the cleaning is scheduled", reply)
+	}
+}
@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// hasRecordInMonth reports whether a record of the type was added to the apartment in the month of the given date
+func hasRecordInMonth(store storage.Store, apartment models.Apartment, recordType models.RecordType, month time.Time) (bool, error) {
+	var dates []time.Time
+	switch recordType {
+	case models.RecordCondo:
+		condos, err := store.GetPayedCondos(apartment)
+		if err != nil {
+			return false, err
+		}
+		for _, c := range condos {
+			dates = append(dates, c.Date)
+		}
+	case models.RecordEnergyBill:
+		bills, err := store.GetPayedBills(apartment)
+		if err != nil {
+			return false, err
+		}
+		for _, b := range bills {
+			dates = append(dates, b.Date)
+		}
+	case models.RecordFinancingInstallment:
+		installments, err := store.GetPayedFinancialInstallments(apartment)
+		if err != nil {
+			return false, err
+		}
+		for _, fi := range installments {
+			dates = append(dates, fi.Date)
+		}
+	}
+
+	for _, d := range dates {
+		if d.Year() == month.Year() && d.Month() == month.Month() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// apartmentIds returns the ids of the apartments by their names, the ids identify the apartments in the answers to
+// the messages sent by the jobs
+func apartmentIds(store storage.Store) (map[string]int64, error) {
+	apartments, err := store.GetApartments()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]int64)
+	for _, a := range apartments {
+		ids[a.Name] = a.Id
+	}
+	return ids, nil
+}
+
+// isMonthAfter compares only the months of the dates, which may be in different locations
+func isMonthAfter(date, month time.Time) bool {
+	return date.Year()*12+int(date.Month()) > month.Year()*12+int(month.Month())
+}
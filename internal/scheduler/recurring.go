@@ -0,0 +1,256 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/format"
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+	storeErrors "github.com/gustavolopess/hoteleiro/internal/storage/errors"
+)
+
+const (
+	recurringCallbackPrefix = "recorrente:"
+	registerAnswer          = "Registrar"
+	ignoreAnswer            = "Ignorar"
+)
+
+// occurrence is a recurring expense due in a month
+type occurrence struct {
+	expense *models.RecurringExpense
+	dueDate time.Time
+}
+
+func (o *occurrence) key() string {
+	return fmt.Sprintf("%s|%s|%s", o.expense.Apartment.Name, o.expense.Type, o.dueDate.Format("2006-01"))
+}
+
+func (o *occurrence) month() time.Time {
+	return time.Date(o.dueDate.Year(), o.dueDate.Month(), 1, 0, 0, 0, 0, o.dueDate.Location())
+}
+
+func (o *occurrence) toString() string {
+	return fmt.Sprintf("%v de %v do dia %v, R$%.2f pago por %v", o.expense.Type, o.expense.Apartment.Name, o.dueDate.Format("02/01/2006"), o.expense.Value, o.expense.Payer)
+}
+
+// RecurringExpenses registers the records of the recurring expenses once they are due, or asks in the chat of the
+// recurring expense for a confirmation before registering them. The last month handled is kept along with the
+// recurring expense, so the months missed while the bot was down are caught up and answers survive restarts
+type RecurringExpenses struct {
+	store    storage.Store
+	notifier Notifier
+
+	mu sync.Mutex
+	// asked are the occurrences whose confirmation was already sent since the bot started
+	asked map[string]bool
+	// answering serializes the answers, so tapping both buttons doesn't handle the month twice
+	answering sync.Mutex
+}
+
+func NewRecurringExpenses(store storage.Store, notifier Notifier) *RecurringExpenses {
+	return &RecurringExpenses{
+		store:    store,
+		notifier: notifier,
+		asked:    make(map[string]bool),
+	}
+}
+
+func (r *RecurringExpenses) Name() string {
+	return "recurring expenses"
+}
+
+// Run handles the due occurrences of every month since the last one handled. Confirmations are asked one month at a
+// time, so the months are answered in order
+func (r *RecurringExpenses) Run(now time.Time) error {
+	expenses, err := r.store.GetRecurringExpenses()
+	if err != nil {
+		return err
+	}
+
+	ids, err := apartmentIds(r.store)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range expenses {
+		if e.Confirm && e.ChatId == 0 {
+			log.Printf("recurring expense %v has no chat to ask for confirmation", e.ToString())
+			continue
+		}
+		if err := r.catchUp(e, ids[e.Apartment.Name], now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *RecurringExpenses) catchUp(e *models.RecurringExpense, apartmentId int64, now time.Time) error {
+	for month := e.FirstPendingMonth(); ; month = month.AddDate(0, 1, 0) {
+		o := &occurrence{expense: e, dueDate: e.DueDate(month)}
+		if o.dueDate.After(now) {
+			return nil
+		}
+
+		if e.IsActiveAt(o.dueDate) {
+			registered, err := hasRecordInMonth(r.store, e.Apartment, e.Type, o.dueDate)
+			if err != nil {
+				return err
+			}
+			if !registered && e.Confirm {
+				return r.askConfirmation(o, apartmentId)
+			}
+			if !registered {
+				if err := r.registerAndNotify(o); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := r.handled(o); err != nil {
+			return err
+		}
+	}
+}
+
+// handled saves the month of the occurrence as the last one handled by its recurring expense
+func (r *RecurringExpenses) handled(o *occurrence) error {
+	o.expense.LastMonth = o.month()
+	return r.store.AddRecurringExpense(o.expense)
+}
+
+// askConfirmation sends the confirmation once per run of the bot, the answer identifies the recurring expense by its
+// apartment and type
+func (r *RecurringExpenses) askConfirmation(o *occurrence, apartmentId int64) error {
+	r.mu.Lock()
+	asked := r.asked[o.key()]
+	r.asked[o.key()] = true
+	r.mu.Unlock()
+	if asked {
+		return nil
+	}
+
+	data := func(answer string) string {
+		return format.CallbackData(recurringCallbackPrefix, answer, strconv.FormatInt(apartmentId, 10),
+			string(o.expense.Type), o.dueDate.Format("2006-01"))
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(registerAnswer, data(registerAnswer)),
+		tgbotapi.NewInlineKeyboardButtonData(ignoreAnswer, data(ignoreAnswer)),
+	))
+	err := r.notifier.Notify(o.expense.ChatId, fmt.Sprintf("Despesa recorrente vencida: %v. Registrar?", o.toString()), keyboard)
+	if err != nil {
+		r.mu.Lock()
+		delete(r.asked, o.key())
+		r.mu.Unlock()
+	}
+	return err
+}
+
+func (r *RecurringExpenses) registerAndNotify(o *occurrence) error {
+	if err := register(r.store, o); err != nil {
+		return err
+	}
+	if o.expense.ChatId == 0 {
+		return nil
+	}
+	return r.notifier.Notify(o.expense.ChatId, fmt.Sprintf("Despesa recorrente registrada: %v", o.toString()), nil)
+}
+
+// Handles reports whether the keyboard answer is a confirmation asked by this job
+func (r *RecurringExpenses) Handles(answer string) bool {
+	return strings.HasPrefix(answer, recurringCallbackPrefix)
+}
+
+// Answer registers or ignores the occurrence whose confirmation was answered, returning the reply to the chat
+func (r *RecurringExpenses) Answer(answer string) string {
+	parts, ok := format.ParseCallbackData(answer, recurringCallbackPrefix, 4)
+	if !ok {
+		return "Resposta inválida"
+	}
+	apartmentId, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "Resposta inválida"
+	}
+	month, err := time.Parse("2006-01", parts[3])
+	if err != nil {
+		return "Resposta inválida"
+	}
+
+	r.answering.Lock()
+	defer r.answering.Unlock()
+
+	o, err := r.findOccurrence(apartmentId, models.RecordType(parts[2]), month)
+	if err != nil {
+		return fmt.Sprintf("Falha ao buscar a despesa recorrente - %v", err.Error())
+	}
+	if o == nil {
+		return "Essa despesa recorrente nao existe mais"
+	}
+	if !o.expense.LastMonth.IsZero() && !isMonthAfter(o.dueDate, o.expense.LastMonth) {
+		return "Essa confirmaçao já foi respondida"
+	}
+
+	if parts[0] == registerAnswer {
+		// the record may have been added by a previous answer whose month failed to be saved
+		registered, err := hasRecordInMonth(r.store, o.expense.Apartment, o.expense.Type, o.dueDate)
+		if err == nil && !registered {
+			err = register(r.store, o)
+		}
+		if err != nil {
+			return fmt.Sprintf("Falha ao registrar a despesa %v - %v", o.toString(), err.Error())
+		}
+	}
+	if err := r.handled(o); err != nil {
+		return fmt.Sprintf("Falha ao salvar a resposta da despesa %v - %v", o.toString(), err.Error())
+	}
+
+	if parts[0] != registerAnswer {
+		return fmt.Sprintf("Despesa ignorada: %v", o.toString())
+	}
+	return fmt.Sprintf("Despesa recorrente registrada: %v", o.toString())
+}
+
+// findOccurrence returns the occurrence of the recurring expense of the type in the apartment in the month, nil if the
+// recurring expense was removed
+func (r *RecurringExpenses) findOccurrence(apartmentId int64, recordType models.RecordType, month time.Time) (*occurrence, error) {
+	expenses, err := r.store.GetRecurringExpenses()
+	if err != nil {
+		return nil, err
+	}
+	ids, err := apartmentIds(r.store)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range expenses {
+		if id, ok := ids[e.Apartment.Name]; ok && id == apartmentId && e.Type == recordType {
+			return &occurrence{expense: e, dueDate: e.DueDate(month)}, nil
+		}
+	}
+	return nil, nil
+}
+
+// register adds the record of the occurrence, a record already added in the month is not an error
+func register(store storage.Store, o *occurrence) error {
+	e := o.expense
+	switch e.Type {
+	case models.RecordCondo:
+		err := store.AddCondo(&models.Condo{Date: o.dueDate, Value: e.Value, Payer: e.Payer, Apartment: e.Apartment})
+		if errors.Is(err, storeErrors.ErrCondoAlreadyPayed) {
+			return nil
+		}
+		return err
+	case models.RecordFinancingInstallment:
+		return store.AddFinancingInstallment(&models.FinancingInstallment{Date: o.dueDate, Value: e.Value, Payer: e.Payer, Apartment: e.Apartment})
+	}
+	return storeErrors.ErrRecurringExpenseInvalidType
+}
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func newInstallmentStore(confirm bool) *fakeStore {
+	apartment := models.Apartment{Name: "Centro", Id: 42}
+	return &fakeStore{
+		apartments: []*models.Apartment{&apartment},
+		recurring: []*models.RecurringExpense{{
+			Apartment: apartment,
+			Type:      models.RecordFinancingInstallment,
+			Value:     1500,
+			Day:       10,
+			Payer:     "Gustavo",
+			Start:     day(2024, 1, 1),
+			Confirm:   confirm,
+			ChatId:    7,
+			LastMonth: day(2024, 1, 1),
+		}},
+	}
+}
+
+func TestRecurringExpensesCatchUpMissedMonths(t *testing.T) {
+	store := newInstallmentStore(false)
+	job := NewRecurringExpenses(store, &fakeNotifier{})
+
+	if err := job.Run(day(2024, 4, 5)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(store.installments) != 2 {
+		t.Fatalf("got %d installments, want the ones of february and march", len(store.installments))
+	}
+	if got := store.recurring[0].LastMonth; !got.Equal(day(2024, 3, 1)) {
+		t.Errorf("last month = %v, want march", got)
+	}
+
+	// the months handled are not registered again by a new run of the bot
+	if err := NewRecurringExpenses(store, &fakeNotifier{}).Run(day(2024, 4, 6)); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.installments) != 2 {
+		t.Errorf("got %d installments after a restart, want 2", len(store.installments))
+	}
+}
+
+func TestRecurringExpensesAnswerAfterRestart(t *testing.T) {
+	store := newInstallmentStore(true)
+	notifier := &fakeNotifier{}
+	if err := NewRecurringExpenses(store, notifier).Run(day(2024, 3, 15)); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.sent) != 1 {
+		t.Fatalf("sent %d confirmations, want only the one of february", len(notifier.sent))
+	}
+	buttons := notifier.sent[0].markup.(tgbotapi.InlineKeyboardMarkup).InlineKeyboard[0]
+	ignore := *buttons[1].CallbackData
+
+	restarted := NewRecurringExpenses(store, &fakeNotifier{})
+	if reply := restarted.Answer(ignore); !strings.HasPrefix(reply, "Despesa ignorada") {
+		t.Fatalf("reply = %q, want the month ignored", reply)
+	}
+	if reply := restarted.Answer(ignore); reply != "Essa confirmaçao já foi respondida" {
+		t.Errorf("reply to the second answer = %q", reply)
+	}
+	if len(store.installments) != 0 {
+		t.Errorf("got %d installments, want none for an ignored month", len(store.installments))
+	}
+
+	// march is asked once february is answered
+	notifier = &fakeNotifier{}
+	if err := NewRecurringExpenses(store, notifier).Run(day(2024, 3, 16)); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.sent) != 1 {
+		t.Fatalf("sent %d confirmations, want the one of march", len(notifier.sent))
+	}
+	register := *notifier.sent[0].markup.(tgbotapi.InlineKeyboardMarkup).InlineKeyboard[0][0].CallbackData
+	restarted.Answer(register)
+	if len(store.installments) != 1 || !store.installments[0].Date.Equal(day(2024, 3, 10)) {
+		t.Errorf("installments = %v, want the one of march", store.installments)
+	}
+}
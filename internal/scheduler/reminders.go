@@ -0,0 +1,231 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/format"
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+const (
+	reminderCallbackPrefix = "lembrete:"
+	snoozeAnswer           = "Adiar"
+	paidAnswer             = "Já paguei"
+	// the answers tell whether the reminder was sent before the due date or after the month ended
+	upcomingReminder = "a"
+	overdueReminder  = "v"
+)
+
+// reminder is a message sent about a payment due in a month
+type reminder struct {
+	due     *models.PaymentDue
+	dueDate time.Time
+	overdue bool
+}
+
+func (r *reminder) text() string {
+	if r.overdue {
+		return fmt.Sprintf("O pagamento de %v de %v com vencimento em %v nao foi registrado", r.due.Type, r.due.Apartment.Name, r.dueDate.Format("02/01/2006"))
+	}
+	return fmt.Sprintf("Lembrete: %v de %v vence em %v", r.due.Type, r.due.Apartment.Name, r.dueDate.Format("02/01/2006"))
+}
+
+// DueReminders reminds the chat of each payment due some days before the due date, and again when a month ends
+// without the payment registered. Reminders can be snoozed or answered with the payment, which starts its entry.
+// The months reminded and the snoozes are kept along with the payment due, so they survive restarts
+type DueReminders struct {
+	store      storage.Store
+	notifier   Notifier
+	daysBefore int
+	snooze     time.Duration
+
+	mu sync.Mutex
+	// lastRun is the time of the scheduler, from which snoozes are counted
+	lastRun time.Time
+}
+
+func NewDueReminders(store storage.Store, notifier Notifier, daysBefore int, snooze time.Duration) *DueReminders {
+	return &DueReminders{
+		store:      store,
+		notifier:   notifier,
+		daysBefore: daysBefore,
+		snooze:     snooze,
+	}
+}
+
+func (d *DueReminders) Name() string {
+	return "due reminders"
+}
+
+// Run reminds the payments due in the coming days and the ones unpaid in the months since each due day was set
+func (d *DueReminders) Run(now time.Time) error {
+	dues, err := d.store.GetPaymentDues()
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.lastRun = now
+	d.mu.Unlock()
+
+	ids, err := apartmentIds(d.store)
+	if err != nil {
+		return err
+	}
+
+	for _, due := range dues {
+		if now.Before(due.SnoozedUntil) {
+			continue
+		}
+		if err := d.remindOverdue(due, ids[due.Apartment.Name], now); err != nil {
+			return err
+		}
+		if err := d.remindUpcoming(due, ids[due.Apartment.Name], now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// remindOverdue reminds the months which ended without the payment registered
+func (d *DueReminders) remindOverdue(due *models.PaymentDue, apartmentId int64, now time.Time) error {
+	for month := due.FirstPendingMonth(); isMonthAfter(now, month); month = month.AddDate(0, 1, 0) {
+		r := &reminder{due: due, dueDate: due.DueDate(month), overdue: true}
+		if err := d.sendUnlessPaid(r, apartmentId); err != nil {
+			return err
+		}
+		due.LastMonth = month
+		if err := d.store.SetPaymentDue(due); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remindUpcoming reminds the payment of the current month in the days before it is due
+func (d *DueReminders) remindUpcoming(due *models.PaymentDue, apartmentId int64, now time.Time) error {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	dueDate := due.DueDate(today)
+	if today.After(dueDate) || today.Before(dueDate.AddDate(0, 0, -d.daysBefore)) {
+		return nil
+	}
+	if isMonthAfter(due.Start, dueDate) || (!due.RemindedMonth.IsZero() && !isMonthAfter(dueDate, due.RemindedMonth)) {
+		return nil
+	}
+
+	if err := d.sendUnlessPaid(&reminder{due: due, dueDate: dueDate}, apartmentId); err != nil {
+		return err
+	}
+	due.RemindedMonth = time.Date(dueDate.Year(), dueDate.Month(), 1, 0, 0, 0, 0, dueDate.Location())
+	return d.store.SetPaymentDue(due)
+}
+
+// sendUnlessPaid sends the reminder, whose answer identifies the payment due by its apartment and type
+func (d *DueReminders) sendUnlessPaid(r *reminder, apartmentId int64) error {
+	paid, err := hasRecordInMonth(d.store, r.due.Apartment, r.due.Type, r.dueDate)
+	if err != nil || paid {
+		return err
+	}
+
+	kind := upcomingReminder
+	if r.overdue {
+		kind = overdueReminder
+	}
+	data := func(answer string) string {
+		return format.CallbackData(reminderCallbackPrefix, answer, strconv.FormatInt(apartmentId, 10), string(r.due.Type),
+			r.dueDate.Format("2006-01"), kind)
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(snoozeAnswer, data(snoozeAnswer)),
+		tgbotapi.NewInlineKeyboardButtonData(paidAnswer, data(paidAnswer)),
+	))
+	return d.notifier.Notify(r.due.ChatId, r.text(), keyboard)
+}
+
+// Handles reports whether the keyboard answer is about a reminder sent by this job
+func (d *DueReminders) Handles(answer string) bool {
+	return strings.HasPrefix(answer, reminderCallbackPrefix)
+}
+
+// Answer snoozes the reminder or, when it was paid, returns the payment due whose entry must be started
+func (d *DueReminders) Answer(answer string) (string, *models.PaymentDue) {
+	parts, ok := format.ParseCallbackData(answer, reminderCallbackPrefix, 5)
+	if !ok {
+		return "Resposta inválida", nil
+	}
+	apartmentId, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "Resposta inválida", nil
+	}
+	month, err := time.Parse("2006-01", parts[3])
+	if err != nil {
+		return "Resposta inválida", nil
+	}
+
+	due, err := d.findDue(apartmentId, models.RecordType(parts[2]))
+	if err != nil {
+		return fmt.Sprintf("Falha ao buscar o vencimento - %v", err.Error()), nil
+	}
+	if due == nil {
+		return "Esse vencimento nao existe mais", nil
+	}
+	paid, err := hasRecordInMonth(d.store, due.Apartment, due.Type, month)
+	if err != nil {
+		return fmt.Sprintf("Falha ao buscar os pagamentos - %v", err.Error()), nil
+	}
+	if paid {
+		return "Esse pagamento já foi registrado", nil
+	}
+
+	if parts[0] == paidAnswer {
+		return "", due
+	}
+
+	// the reminder is sent again once the snooze ends, as its month goes back to not reminded
+	previousMonth := month.AddDate(0, -1, 0)
+	if parts[4] == overdueReminder && isMonthAfter(due.LastMonth, previousMonth) {
+		due.LastMonth = previousMonth
+	}
+	if parts[4] == upcomingReminder && isMonthAfter(due.RemindedMonth, previousMonth) {
+		due.RemindedMonth = previousMonth
+	}
+	d.mu.Lock()
+	snoozedFrom := d.lastRun
+	d.mu.Unlock()
+	// answers may come before the first run after a restart
+	if snoozedFrom.IsZero() {
+		snoozedFrom = time.Now()
+	}
+	due.SnoozedUntil = snoozedFrom.Add(d.snooze)
+	if err := d.store.SetPaymentDue(due); err != nil {
+		return fmt.Sprintf("Falha ao adiar o lembrete - %v", err.Error()), nil
+	}
+	return fmt.Sprintf("Vou lembrar novamente em %v", due.SnoozedUntil.Format("02/01/2006 15:04")), nil
+}
+
+// findDue returns the payment due of the type in the apartment, nil if it was removed
+func (d *DueReminders) findDue(apartmentId int64, recordType models.RecordType) (*models.PaymentDue, error) {
+	dues, err := d.store.GetPaymentDues()
+	if err != nil {
+		return nil, err
+	}
+	ids, err := apartmentIds(d.store)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, due := range dues {
+		if id, ok := ids[due.Apartment.Name]; ok && id == apartmentId && due.Type == recordType {
+			return due, nil
+		}
+	}
+	return nil, nil
+}
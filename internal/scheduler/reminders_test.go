@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+func newDueStore() *fakeStore {
+	apartment := models.Apartment{Name: "Centro", Id: 42}
+	return &fakeStore{
+		apartments: []*models.Apartment{&apartment},
+		dues: []*models.PaymentDue{{
+			Apartment: apartment,
+			Type:      models.RecordFinancingInstallment,
+			Day:       10,
+			ChatId:    7,
+			Start:     day(2024, 3, 15),
+		}},
+	}
+}
+
+func TestDueRemindersOverdueSinceTheDueWasSet(t *testing.T) {
+	store := newDueStore()
+	store.installments = []*models.FinancingInstallment{{Date: day(2024, 4, 10), Value: 1500}}
+	notifier := &fakeNotifier{}
+
+	if err := NewDueReminders(store, notifier, 3, time.Hour).Run(day(2024, 5, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.sent) != 1 || !strings.Contains(notifier.sent[0].text, "10/03/2024") {
+		t.Fatalf("sent %v, want only the overdue reminder of march", notifier.sent)
+	}
+
+	// a new run of the bot doesn't send the reminders again
+	notifier = &fakeNotifier{}
+	if err := NewDueReminders(store, notifier, 3, time.Hour).Run(day(2024, 5, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.sent) != 0 {
+		t.Errorf("sent %d reminders after a restart, want none", len(notifier.sent))
+	}
+}
+
+func TestDueRemindersSnoozeAfterRestart(t *testing.T) {
+	store := newDueStore()
+	notifier := &fakeNotifier{}
+	now := time.Date(2024, 4, 8, 9, 0, 0, 0, time.Local)
+	if err := NewDueReminders(store, notifier, 3, time.Hour).Run(now); err != nil {
+		t.Fatal(err)
+	}
+	// the overdue reminder of march and the one before the due date of april
+	if len(notifier.sent) != 2 {
+		t.Fatalf("sent %d reminders, want 2", len(notifier.sent))
+	}
+	snooze := *notifier.sent[1].markup.(tgbotapi.InlineKeyboardMarkup).InlineKeyboard[0][0].CallbackData
+
+	restarted := NewDueReminders(store, &fakeNotifier{}, 3, time.Hour)
+	if err := restarted.Run(now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if reply, _ := restarted.Answer(snooze); !strings.HasPrefix(reply, "Vou lembrar novamente") {
+		t.Fatalf("reply = %q, want the reminder snoozed", reply)
+	}
+
+	notifier = &fakeNotifier{}
+	snoozed := NewDueReminders(store, notifier, 3, time.Hour)
+	if err := snoozed.Run(now.Add(30 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.sent) != 0 {
+		t.Fatalf("sent %d reminders while snoozed, want none", len(notifier.sent))
+	}
+	if err := snoozed.Run(now.Add(2 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.sent) != 1 || !strings.Contains(notifier.sent[0].text, "vence em 10/04/2024") {
+		t.Errorf("sent %v, want the reminder of april again", notifier.sent)
+	}
+}
@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/report"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// Reports delivers the report of every period ended to the subscribed chats. The last period delivered is saved
+// with the subscription after each delivery, so a restart neither repeats nor skips a period
+type Reports struct {
+	store    storage.Store
+	notifier Notifier
+}
+
+func NewReports(store storage.Store, notifier Notifier) *Reports {
+	return &Reports{
+		store:    store,
+		notifier: notifier,
+	}
+}
+
+func (r *Reports) Name() string {
+	return "report subscriptions"
+}
+
+// Run delivers, in order, every period ended after the last one delivered to each subscription. A subscription
+// failing to be delivered, as when its chat blocked the bot, is retried on the next run without holding the others
+func (r *Reports) Run(now time.Time) error {
+	subscriptions, err := r.store.GetReportSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subscriptions {
+		current := sub.Frequency.CurrentPeriod(now)
+		for period := sub.Frequency.NextPeriod(sub.LastPeriod); period.Before(current); period = sub.Frequency.NextPeriod(period) {
+			if err := r.deliver(sub, period); err != nil {
+				log.Printf("failed to deliver the %v report of %v to chat %d: %v", sub.Frequency, period.Format("02/01/2006"), sub.ChatId, err)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *Reports) deliver(sub *models.ReportSubscription, period time.Time) error {
+	end := sub.Frequency.NextPeriod(period)
+	summaries, err := report.Summarize(r.store, period, end)
+	if err != nil {
+		return err
+	}
+	if err := r.notifier.Notify(sub.ChatId, report.FormatSummaries(summaries, period, end), nil); err != nil {
+		return err
+	}
+
+	sub.LastPeriod = period
+	return r.store.SetReportSubscription(sub)
+}
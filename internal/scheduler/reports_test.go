@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+func TestReportsCatchUpMissedPeriods(t *testing.T) {
+	store := &fakeStore{subscriptions: []*models.ReportSubscription{
+		{ChatId: 1, Frequency: models.ReportMonthly, LastPeriod: day(2024, 1, 1)},
+		{ChatId: 2, Frequency: models.ReportMonthly, LastPeriod: day(2024, 1, 1)},
+	}}
+	// the first chat blocked the bot, which doesn't hold the reports of the second one
+	notifier := &fakeNotifier{failing: map[int64]bool{1: true}}
+	now := time.Date(2024, 4, 2, 9, 0, 0, 0, time.Local)
+	jobs := NewScheduler(time.Hour, NewReports(store, notifier)).WithClock(func() time.Time { return now })
+
+	jobs.RunOnce()
+
+	want := []string{
+		"Resumo de 01/02/2024 a 29/02/2024\nNenhum imóvel cadastrado",
+		"Resumo de 01/03/2024 a 31/03/2024\nNenhum imóvel cadastrado",
+	}
+	if len(notifier.sent) != len(want) {
+		t.Fatalf("sent %d reports, want %d", len(notifier.sent), len(want))
+	}
+	for i, n := range notifier.sent {
+		if n.chatId != 2 || n.text != want[i] {
+			t.Errorf("report %d = %d %q, want %q to chat 2", i, n.chatId, n.text, want[i])
+		}
+	}
+	if got := store.subscriptions[1].LastPeriod; !got.Equal(day(2024, 3, 1)) {
+		t.Errorf("last period = %v, want march", got)
+	}
+	if got := store.subscriptions[0].LastPeriod; !got.Equal(day(2024, 1, 1)) {
+		t.Errorf("last period of the failing chat = %v, want january", got)
+	}
+
+	// the periods saved are not sent again
+	now = now.Add(24 * time.Hour)
+	jobs.RunOnce()
+	if len(notifier.sent) != len(want) {
+		t.Errorf("sent %d reports after the periods were saved, want %d", len(notifier.sent), len(want))
+	}
+}
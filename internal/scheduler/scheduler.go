@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Notifier sends a message to a chat out of any conversation, the markup is an optional inline keyboard
+type Notifier interface {
+	Notify(chatId int64, text string, markup interface{}) error
+}
+
+// Job is some work done periodically by the scheduler
+type Job interface {
+	Name() string
+	Run(now time.Time) error
+}
+
+// Scheduler runs its jobs at every interval, the clock can be replaced to run the jobs at a chosen time
+type Scheduler struct {
+	jobs     []Job
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewScheduler(interval time.Duration, jobs ...Job) *Scheduler {
+	return &Scheduler{
+		jobs:     jobs,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// WithClock replaces the clock which tells the jobs what time it is
+func (s *Scheduler) WithClock(now func() time.Time) *Scheduler {
+	s.now = now
+	return s
+}
+
+// RunOnce runs every job once, a failing job does not prevent the others from running
+func (s *Scheduler) RunOnce() {
+	now := s.now()
+	for _, j := range s.jobs {
+		if err := j.Run(now); err != nil {
+			log.Printf("scheduled job %s failed: %v", j.Name(), err)
+		}
+	}
+}
+
+// Start runs the jobs right away and then at every interval, until the context is done
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.RunOnce()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RunOnce()
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// fakeStore keeps in memory the data the jobs read and write, the other methods of the store are not implemented
+type fakeStore struct {
+	storage.Store
+	apartments    []*models.Apartment
+	recurring     []*models.RecurringExpense
+	installments  []*models.FinancingInstallment
+	dues          []*models.PaymentDue
+	rents         []*models.Rent
+	cleanings     []*models.ScheduledCleaning
+	chats         []*models.AllowedChat
+	runs          map[string]time.Time
+	subscriptions []*models.ReportSubscription
+}
+
+func (s *fakeStore) GetApartments() ([]*models.Apartment, error) {
+	return s.apartments, nil
+}
+
+func (s *fakeStore) GetRecurringExpenses() ([]*models.RecurringExpense, error) {
+	var expenses []*models.RecurringExpense
+	for _, e := range s.recurring {
+		copied := *e
+		expenses = append(expenses, &copied)
+	}
+	return expenses, nil
+}
+
+func (s *fakeStore) AddRecurringExpense(r *models.RecurringExpense) error {
+	for i, e := range s.recurring {
+		if e.Apartment.Name == r.Apartment.Name && e.Type == r.Type {
+			copied := *r
+			s.recurring[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (s *fakeStore) GetPayedFinancialInstallments(apartment models.Apartment) ([]*models.FinancingInstallment, error) {
+	return s.installments, nil
+}
+
+func (s *fakeStore) AddFinancingInstallment(f *models.FinancingInstallment) error {
+	s.installments = append(s.installments, f)
+	return nil
+}
+
+func (s *fakeStore) GetPaymentDues() ([]*models.PaymentDue, error) {
+	var dues []*models.PaymentDue
+	for _, d := range s.dues {
+		copied := *d
+		dues = append(dues, &copied)
+	}
+	return dues, nil
+}
+
+func (s *fakeStore) SetPaymentDue(p *models.PaymentDue) error {
+	for i, d := range s.dues {
+		if d.Apartment.Name == p.Apartment.Name && d.Type == p.Type {
+			copied := *p
+			s.dues[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (s *fakeStore) GetExistingRents(apartment models.Apartment) ([]*models.Rent, error) {
+	return s.rents, nil
+}
+
+func (s *fakeStore) GetScheduledCleanings() ([]*models.ScheduledCleaning, error) {
+	return s.cleanings, nil
+}
+
+func (s *fakeStore) GetAllowedChats() ([]*models.AllowedChat, error) {
+	return s.chats, nil
+}
+
+func (s *fakeStore) GetJobRun(job string) (*models.JobRun, error) {
+	return &models.JobRun{Job: job, LastRun: s.runs[job]}, nil
+}
+
+func (s *fakeStore) SetJobRun(r *models.JobRun) error {
+	if s.runs == nil {
+		s.runs = make(map[string]time.Time)
+	}
+	s.runs[r.Job] = r.LastRun
+	return nil
+}
+
+func (s *fakeStore) GetReportSubscriptions() ([]*models.ReportSubscription, error) {
+	var subscriptions []*models.ReportSubscription
+	for _, sub := range s.subscriptions {
+		copied := *sub
+		subscriptions = append(subscriptions, &copied)
+	}
+	return subscriptions, nil
+}
+
+func (s *fakeStore) SetReportSubscription(r *models.ReportSubscription) error {
+	for i, sub := range s.subscriptions {
+		if sub.ChatId == r.ChatId && sub.Frequency == r.Frequency {
+			copied := *r
+			s.subscriptions[i] = &copied
+		}
+	}
+	return nil
+}
+
+// notification is a message sent by a job
+type notification struct {
+	chatId int64
+	text   string
+	markup interface{}
+}
+
+type fakeNotifier struct {
+	sent []notification
+	// failing chats refuse every message, as a chat which blocked the bot
+	failing map[int64]bool
+}
+
+func (n *fakeNotifier) Notify(chatId int64, text string, markup interface{}) error {
+	if n.failing[chatId] {
+		return errors.New("chat blocked the bot")
+	}
+	n.sent = append(n.sent, notification{chatId, text, markup})
+	return nil
+}
@@ -0,0 +1,7 @@
+package blob
+
+// Store keeps files, such as receipts, addressed by a key
+type Store interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error)
+}
@@ -0,0 +1,23 @@
+package blob
+
+import (
+	"github.com/gustavolopess/hoteleiro/internal/storage/s3_client"
+)
+
+type s3Store struct {
+	client *s3_client.S3Client
+	prefix string
+}
+
+// NewS3Store keeps the files in the bot bucket, under the given prefix
+func NewS3Store(client *s3_client.S3Client, prefix string) Store {
+	return &s3Store{client: client, prefix: prefix}
+}
+
+func (s *s3Store) Put(key string, data []byte) error {
+	return s.client.UploadItem(s.prefix+key, data)
+}
+
+func (s *s3Store) Get(key string) ([]byte, error) {
+	return s.client.DownloadItem(s.prefix + key)
+}
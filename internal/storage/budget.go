@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"log"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+)
+
+// BudgetAlerts is told when an expense added takes what was spent in its month past a threshold of its budget
+type BudgetAlerts func(alert *models.BudgetAlert)
+
+// SpentByMonth sums the records of the type paid in the apartment by the first day of their month
+func SpentByMonth(s Store, apartment models.Apartment, t models.RecordType) (map[time.Time]float64, error) {
+	var dates []time.Time
+	var values []float64
+	switch t {
+	case models.RecordCleaning:
+		cleanings, err := s.GetPayedCleanings(apartment)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range cleanings {
+			dates, values = append(dates, c.Date), append(values, c.Value)
+		}
+	case models.RecordCondo:
+		condos, err := s.GetPayedCondos(apartment)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range condos {
+			dates, values = append(dates, c.Date), append(values, c.Value)
+		}
+	case models.RecordEnergyBill:
+		bills, err := s.GetPayedBills(apartment)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range bills {
+			dates, values = append(dates, b.Date), append(values, b.Value)
+		}
+	case models.RecordFinancingInstallment:
+		installments, err := s.GetPayedFinancialInstallments(apartment)
+		if err != nil {
+			return nil, err
+		}
+		for _, fi := range installments {
+			dates, values = append(dates, fi.Date), append(values, fi.Value)
+		}
+	case models.RecordMiscellaneousExpense:
+		expenses, err := s.GetMiscellaneousExpenses(apartment)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range expenses {
+			dates, values = append(dates, e.Date), append(values, e.Value)
+		}
+	}
+
+	spent := make(map[time.Time]float64)
+	for i, d := range dates {
+		spent[monthOf(d)] += values[i]
+	}
+	return spent, nil
+}
+
+func monthOf(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// checkBudget alerts the highest threshold of the budget of the type which the value just added in the month of the
+// date crossed. The record is already stored, so failures are only logged
+func (s *store) checkBudget(apartment models.Apartment, t models.RecordType, date time.Time, value float64) {
+	if s.alerts == nil || value <= 0 {
+		return
+	}
+	budgets, err := s.GetBudgets()
+	if err != nil {
+		log.Printf("failed to check the budget of %v of %v: %v", t, apartment.Name, err)
+		return
+	}
+	b := findBudget(budgets, apartment.Name, t)
+	if b == nil {
+		return
+	}
+	spentByMonth, err := SpentByMonth(s, apartment, t)
+	if err != nil {
+		log.Printf("failed to check the budget of %v of %v: %v", t, apartment.Name, err)
+		return
+	}
+	s.alertThreshold(b, monthOf(date), spentByMonth[monthOf(date)], value)
+}
+
+// checkBatchBudgets checks the expenses of the batch together, by apartment and month, as they were stored at once.
+// The budgets are read once and the expenses of an apartment only when it has a budget for them
+func (s *store) checkBatchBudgets(b *models.Batch) {
+	if s.alerts == nil || len(b.MiscellaneousExpenses) == 0 {
+		return
+	}
+	type key struct {
+		apartment string
+		month     time.Time
+	}
+	var keys []key
+	added := make(map[key]float64)
+	for _, e := range b.MiscellaneousExpenses {
+		k := key{e.Apartment.Name, monthOf(e.Date)}
+		if _, ok := added[k]; !ok {
+			keys = append(keys, k)
+		}
+		added[k] += e.Value
+	}
+
+	budgets, err := s.GetBudgets()
+	if err != nil {
+		log.Printf("failed to check the budgets of the batch: %v", err)
+		return
+	}
+	spentByApartment := make(map[string]map[time.Time]float64)
+	for _, k := range keys {
+		budget := findBudget(budgets, k.apartment, models.RecordMiscellaneousExpense)
+		if budget == nil || added[k] <= 0 {
+			continue
+		}
+		if _, ok := spentByApartment[k.apartment]; !ok {
+			spent, err := SpentByMonth(s, models.Apartment{Name: k.apartment}, models.RecordMiscellaneousExpense)
+			if err != nil {
+				log.Printf("failed to check the budget of %v of %v: %v", models.RecordMiscellaneousExpense, k.apartment, err)
+				continue
+			}
+			spentByApartment[k.apartment] = spent
+		}
+		s.alertThreshold(budget, k.month, spentByApartment[k.apartment][k.month], added[k])
+	}
+}
+
+func findBudget(budgets []*models.Budget, apartment string, t models.RecordType) *models.Budget {
+	for _, b := range budgets {
+		if b.Apartment.Name == apartment && b.Type == t {
+			return b
+		}
+	}
+	return nil
+}
+
+// alertThreshold alerts the highest threshold of the budget crossed by the value added to what was spent in the month
+func (s *store) alertThreshold(b *models.Budget, month time.Time, spent, added float64) {
+	for _, threshold := range models.BudgetThresholds {
+		limit := b.Value * threshold
+		if spent >= limit && spent-added < limit {
+			s.alerts(&models.BudgetAlert{Budget: b, Month: month, Spent: spent, Threshold: threshold})
+			return
+		}
+	}
+}
@@ -8,3 +8,17 @@ var ErrCondoAlreadyPayed = errors.New("o condomínio do mês informado já foi p
 var ErrBillAlreadyPayed = errors.New("a conta de energia já foi paga nesse mês")
 var ErrCleaningAlreadyHappened = errors.New("uma faxina já foi cadastrada nesse mesmo dia")
 var ErrMiscellaneousExpenseAlreadyCreated = errors.New("essa despesa já foi adicionada previamente")
+var ErrApartmentAlreadyExists = errors.New("já existe um imóvel com esse nome")
+var ErrApartmentInvalidName = errors.New("o nome do imóvel nao pode ser vazio, ter mais de 64 caracteres, começar com [ ou conter ! e aspas")
+var ErrApartmentNotFound = errors.New("imóvel nao encontrado")
+var ErrRecurringExpenseInvalidType = errors.New("somente condomínio e parcela do financiamento podem ser recorrentes")
+var ErrInvalidDueDay = errors.New("o dia do vencimento deve estar entre 1 e 31")
+var ErrRecurringExpenseReversedDates = errors.New("a data de início deve anteceder a data de fim da recorrência")
+var ErrPaymentDueInvalidType = errors.New("somente condomínio, conta de luz e parcela do financiamento têm vencimento")
+var ErrPaymentDueHasRecurringExpense = errors.New("esse pagamento é uma despesa recorrente, registrada no seu vencimento")
+var ErrFinancingInvalidTerms = errors.New("o valor financiado e o prazo devem ser positivos e a taxa de juros nao pode ser negativa")
+var ErrFinancingInvalidSystem = errors.New("o sistema de amortizaçao deve ser SAC ou Price")
+var ErrFinancingInvalidIndex = errors.New("o índice de correçao deve ser TR ou IPCA")
+var ErrBudgetInvalidType = errors.New("somente faxina, conta de luz, condomínio e despesas podem ter orçamento")
+var ErrBudgetInvalidValue = errors.New("o orçamento deve ser positivo")
+var ErrAttachmentRecordNotFound = errors.New("nenhum registro encontrado com a data e o valor do comprovante")
@@ -5,14 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gustavolopess/hoteleiro/internal/format"
 	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage/errors"
 	"github.com/gustavolopess/hoteleiro/internal/storage/s3_client"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -21,27 +24,154 @@ import (
 )
 
 const rentCell = "A3"
-const rentDatesCells = "A3:E"
+const rentDatesCells = "A3:H"
 
-const billCell = "F3"
-const readBillCells = "F3:H"
+const billCell = "I3"
+const readBillCells = "I3:L"
 
-const condoCell = "I3"
-const readCondosCells = "I3:K"
+const condoCell = "M3"
+const readCondosCells = "M3:P"
 
-const cleaningCell = "L3"
-const readCleaningCells = "L3:N"
+const cleaningCell = "Q3"
+const readCleaningCells = "Q3:T"
 
-const miscellaneousExpenseCell = "O3"
-const readMiscellaneousExpenseCells = "O3:R"
+const miscellaneousExpenseCell = "U3"
+const readMiscellaneousExpenseCells = "U3:Z"
 
-const amortizationCell = "S3"
-const readAmortizationCells = "S3:U"
+const amortizationCell = "AA3"
+const readAmortizationCells = "AA3:AE"
 
-const financingInstallmentCell = "V3"
-const readFinancialInstallmentCells = "V3:X"
+const financingInstallmentCell = "AF3"
+const readFinancialInstallmentCells = "AF3:AI"
+
+const addressLabelCell = "AK1"
+
+// receiptHeader names the last column of the expense tables, holding the keys of the receipts of the record one per
+// line
+const receiptHeader = "Comprovante"
+
+// optionHeader names the column of the amortizations holding what they reduced in the financing
+const optionHeader = "Opçao"
+
+// categoryHeader names the column of the miscellaneous expenses holding their category
+const categoryHeader = "Categoria"
+
+// bookedHeader, cleaningFeeHeader and receivedHeader name the columns of the rents holding when they were booked, the
+// part of the value paid for the cleaning and when the value was received
+const bookedHeader = "Data da reserva"
+const cleaningFeeHeader = "Taxa de limpeza"
+const receivedHeader = "Data do recebimento"
+
+// templateSheet is copied when a new apartment is added, if it exists
+const templateSheet = "[Modelo]"
+
+// tableLayout describes a table of the apartment sheet: its title is in row 1, headers in row 2 and data from row 3.
+// Columns are offsets from the first one
+type tableLayout struct {
+	title        string
+	firstCell    string
+	headers      []string
+	dateColumns  []int
+	valueColumns []int
+}
+
+var apartmentTables = []tableLayout{
+	{"Aluguéis", rentCell, []string{"Entrada", "Saída", "Valor", "Inquilino", "Recebedor", bookedHeader, cleaningFeeHeader, receivedHeader}, []int{0, 1, 5, 7}, []int{2, 6}},
+	{"Conta de luz", billCell, []string{"Data", "Valor", "Pagador", receiptHeader}, []int{0}, []int{1}},
+	{"Condomínio", condoCell, []string{"Data", "Valor", "Pagador", receiptHeader}, []int{0}, []int{1}},
+	{"Faxinas", cleaningCell, []string{"Data", "Valor", "Pagador", receiptHeader}, []int{0}, []int{1}},
+	{"Despesas diversas", miscellaneousExpenseCell, []string{"Data", "Valor", "Descriçao", "Pagador", categoryHeader, receiptHeader}, []int{0}, []int{1}},
+	{"Amortizaçoes", amortizationCell, []string{"Data", "Valor", "Pagador", optionHeader, receiptHeader}, []int{0}, []int{1}},
+	{"Parcelas do financiamento", financingInstallmentCell, []string{"Data", "Valor", "Pagador", receiptHeader}, []int{0}, []int{1}},
+}
+
+const statementRulesSheet = "[Regras extrato]"
+const readStatementRulesCells = "A2:C"
+
+const accessSheet = "[Acessos]"
+const usersCell = "A2"
+const readUsersCells = "A2:C"
+const allowedChatsCell = "E2"
+const readAllowedChatsCells = "E2:F"
+
+var accessHeaders = []interface{}{"Usuário", "Nome", "Papel", "", "Grupo", "Nome"}
+
+const apartmentsSheet = "[Imóveis]"
+const apartmentsCell = "A2"
+const readApartmentsCells = "A2:G"
+const archivedStatus = "arquivado"
+const activeStatus = "ativo"
+
+var apartmentsHeaders = []interface{}{"Nome", "Endereço", "Data de aquisiçao", "Preço de compra", "Banco do financiamento", "Anúncios", "Status"}
+
+const recurringExpensesSheet = "[Recorrentes]"
+const recurringExpensesCell = "A2"
+const readRecurringExpensesCells = "A2:L"
+const confirmMode = "confirmar"
+const automaticMode = "automatico"
+
+// remindMode rows are payment dues, which are only reminded and never registered
+const remindMode = "lembrete"
+
+var recurringExpensesHeaders = []interface{}{"Imóvel", "Tipo", "Valor", "Dia", "Pagador", "Início", "Fim", "Modo", "Chat", "Último mês",
+	"Lembrete enviado", "Adiado até"}
+
+const jobRunsSheet = "[Execuções]"
+const jobRunsCell = "A2"
+const readJobRunsCells = "A2:B"
+
+var jobRunsHeaders = []interface{}{"Tarefa", "Última execuçao"}
+
+const scheduledCleaningsSheet = "[Faxinas agendadas]"
+const scheduledCleaningsCell = "A2"
+const readScheduledCleaningsCells = "A2:E"
+
+var scheduledCleaningsHeaders = []interface{}{"Imóvel", "Data", "Faxineira", "Nome", "Pagador"}
+
+const reportSubscriptionsSheet = "[Assinaturas]"
+const reportSubscriptionsCell = "A2"
+const readReportSubscriptionsCells = "A2:C"
+
+var reportSubscriptionsHeaders = []interface{}{"Chat", "Frequência", "Último período enviado"}
+
+const userPreferencesSheet = "[Preferências]"
+const userPreferencesCell = "A2"
+const readUserPreferencesCells = "A2:B"
+
+var userPreferencesHeaders = []interface{}{"Usuário", "Imóvel"}
+
+const recordDefaultsSheet = "[Padrões]"
+const recordDefaultsCell = "A2"
+const readRecordDefaultsCells = "A2:E"
+
+var recordDefaultsHeaders = []interface{}{"Usuário", "Imóvel", "Tipo", "Valor", "Pagador"}
+
+const calendarBookingsSheet = "[Calendários]"
+const calendarBookingsCell = "A2"
+const readCalendarBookingsCells = "A2:E"
+
+var calendarBookingsHeaders = []interface{}{"Imóvel", "Origem", "UID", "Entrada", "Saída"}
+
+const financingContractsSheet = "[Financiamentos]"
+const financingContractsCell = "A2"
+const readFinancingContractsCells = "A2:G"
+
+var financingContractsHeaders = []interface{}{"Imóvel", "Valor financiado", "Juros ao ano (%)", "Prazo (meses)", "Sistema", "Primeira parcela", "Índice"}
+
+const indexValuesSheet = "[Índices]"
+const indexValuesCell = "A2"
+const readIndexValuesCells = "A2:C"
+
+var indexValuesHeaders = []interface{}{"Índice", "Mês", "Variaçao (%)"}
+
+const budgetsSheet = "[Orçamentos]"
+const budgetsCell = "A2"
+const readBudgetsCells = "A2:D"
+
+var budgetsHeaders = []interface{}{"Imóvel", "Tipo", "Valor mensal", "Chat"}
 
 const dateLayout = "02/01/2006"
+const dateTimeLayout = "02/01/2006 15:04"
 
 // Retrieve a token, saves the token, then returns the generated client.
 func getClient(config *oauth2.Config) *http.Client {
@@ -110,7 +240,15 @@ func NewSheetsClient(ctx context.Context, sheetsId string, credentialsJson []byt
 		log.Fatalf("Unable to retrieve Sheets client: %v", err)
 	}
 
-	return &SheetsClient{srv, sheetsId}
+	sheetsClient := &SheetsClient{srv, sheetsId}
+	if err := sheetsClient.migrateApartmentSheets(); err != nil {
+		log.Fatalf("Unable to migrate the apartment sheets: %v", err)
+	}
+	if err := sheetsClient.migrateHeaders(); err != nil {
+		log.Fatalf("Unable to migrate the headers of the sheets: %v", err)
+	}
+
+	return sheetsClient
 }
 
 // AddCleaning adds a new cleaning fee to the Cleaning table in the apartment sheet
@@ -125,12 +263,15 @@ func (s *SheetsClient) AddCleaning(c *models.Cleaning) error {
 		return existingCleanings[i].Date.Before(existingCleanings[j].Date)
 	})
 
-	var dataToWrite [][]interface{}
-	for _, cleaning := range existingCleanings {
-		dataToWrite = append(dataToWrite, []interface{}{cleaning.Date.Format(dateLayout), cleaning.Value, cleaning.Payer})
-	}
+	return s.upsertDataInRange(c.Apartment, cleaningCell, cleaningRows(existingCleanings))
+}
 
-	return s.upsertDataInRange(c.Apartment, cleaningCell, dataToWrite)
+func cleaningRows(cleanings []*models.Cleaning) [][]interface{} {
+	var rows [][]interface{}
+	for _, c := range cleanings {
+		rows = append(rows, []interface{}{c.Date.Format(dateLayout), c.Value, c.Payer, receiptsValue(c.Receipts)})
+	}
+	return rows
 }
 
 // AddCondo adds a new condo payment to the Condo table in the apartment Sheet
@@ -145,383 +286,2445 @@ func (s *SheetsClient) AddCondo(c *models.Condo) error {
 		return existingCondos[i].Date.Before(existingCondos[j].Date)
 	})
 
-	var dataToWrite [][]interface{}
-	for _, condo := range existingCondos {
-		dataToWrite = append(dataToWrite, []interface{}{condo.Date.Format(dateLayout), condo.Value, condo.Payer})
-	}
+	return s.upsertDataInRange(c.Apartment, condoCell, condoRows(existingCondos))
+}
 
-	return s.upsertDataInRange(c.Apartment, condoCell, dataToWrite)
+func condoRows(condos []*models.Condo) [][]interface{} {
+	var rows [][]interface{}
+	for _, c := range condos {
+		rows = append(rows, []interface{}{c.Date.Format(dateLayout), c.Value, c.Payer, receiptsValue(c.Receipts)})
+	}
+	return rows
 }
 
-// AddApartment adds a new sheet on spreadsheet, which represents an apartment
+// AddApartment adds a new sheet on spreadsheet, which represents an apartment. The sheet is a copy of the
+// template sheet when there is one, otherwise its tables are laid out from scratch
 func (s *SheetsClient) AddApartment(a *models.Apartment) error {
-	return nil
-}
+	sheetData, err := s.Spreadsheets.Get(s.sheetsId).Do()
+	if err != nil {
+		return err
+	}
 
-// AddBill appends data to the Bill table in the apartment sheet
-func (s *SheetsClient) AddBill(e *models.EnergyBill) error {
-	existingBills, err := s.GetPayedBills(e.Apartment)
+	var templateSheetId *int64
+	for _, sheet := range sheetData.Sheets {
+		if sheet.Properties.Title == templateSheet {
+			templateSheetId = &sheet.Properties.SheetId
+		}
+	}
+
+	var request *sheets.Request
+	if templateSheetId != nil {
+		request = &sheets.Request{DuplicateSheet: &sheets.DuplicateSheetRequest{
+			SourceSheetId:    *templateSheetId,
+			NewSheetName:     a.Name,
+			InsertSheetIndex: int64(len(sheetData.Sheets)),
+		}}
+	} else {
+		request = &sheets.Request{AddSheet: &sheets.AddSheetRequest{
+			Properties: &sheets.SheetProperties{Title: a.Name},
+		}}
+	}
+
+	resp, err := s.Spreadsheets.BatchUpdate(s.sheetsId, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{request},
+	}).Do()
 	if err != nil {
 		return err
 	}
 
-	existingBills = append(existingBills, e)
-	sort.Slice(existingBills, func(i, j int) bool {
-		return existingBills[i].Date.Before(existingBills[j].Date)
-	})
+	var sheetId int64
+	if templateSheetId == nil {
+		sheetId = resp.Replies[0].AddSheet.Properties.SheetId
+	} else {
+		sheetId = resp.Replies[0].DuplicateSheet.Properties.SheetId
+	}
+	log.Printf("sheet of apartment %s created", a.Name)
+
+	if err := s.provisionApartmentSheet(a, sheetId, templateSheetId == nil); err != nil {
+		// a half provisioned sheet would hold the name and be listed as an apartment, so it is removed
+		if _, deleteErr := s.Spreadsheets.BatchUpdate(s.sheetsId, &sheets.BatchUpdateSpreadsheetRequest{
+			Requests: []*sheets.Request{{DeleteSheet: &sheets.DeleteSheetRequest{SheetId: sheetId}}},
+		}).Do(); deleteErr != nil {
+			log.Printf("failed to remove the sheet of apartment %s: %v", a.Name, deleteErr)
+		}
+		return err
+	}
+	return nil
+}
 
-	var dataToWrite [][]interface{}
-	for _, b := range existingBills {
-		dataToWrite = append(dataToWrite, []interface{}{b.Date.Format(dateLayout), b.Value, b.Payer})
+// provisionApartmentSheet lays out the tables of a new sheet, when it is not a copy of the template, and registers
+// the apartment
+func (s *SheetsClient) provisionApartmentSheet(a *models.Apartment, sheetId int64, layout bool) error {
+	if layout {
+		if err := s.layoutApartmentSheet(a, sheetId); err != nil {
+			return err
+		}
+	}
+
+	if err := s.upsertDataInRange(*a, addressLabelCell, [][]interface{}{{"Endereço", a.Address}}); err != nil {
+		return err
 	}
 
-	return s.upsertDataInRange(e.Apartment, billCell, dataToWrite)
+	return s.UpdateApartment(a)
 }
 
-// AddRent appends data to the rent table in the apartment sheet
-func (s *SheetsClient) AddRent(r *models.Rent) error {
-	existingRents, err := s.GetExistingRents(r.Apartment)
+// UpdateApartment writes the apartment to the registry of apartments, replacing its previous data
+func (s *SheetsClient) UpdateApartment(a *models.Apartment) error {
+	registry, err := s.readApartmentsRegistry()
 	if err != nil {
 		return err
 	}
 
-	existingRents = append(existingRents, r)
-	sort.Slice(existingRents, func(i, j int) bool {
-		return existingRents[i].DateBegin.Before(existingRents[j].DateBegin)
+	var apartments []*models.Apartment
+	for _, apt := range registry {
+		if apt.Name != a.Name {
+			apartments = append(apartments, apt)
+		}
+	}
+	apartments = append(apartments, a)
+	sort.Slice(apartments, func(i, j int) bool {
+		return apartments[i].Name < apartments[j].Name
 	})
 
 	var dataToWrite [][]interface{}
-	for _, rent := range existingRents {
+	for _, apt := range apartments {
+		acquisitionDate := ""
+		if !apt.AcquisitionDate.IsZero() {
+			acquisitionDate = apt.AcquisitionDate.Format(dateLayout)
+		}
+		status := activeStatus
+		if apt.Archived {
+			status = archivedStatus
+		}
 		dataToWrite = append(dataToWrite, []interface{}{
-			rent.DateBegin.Format(dateLayout), rent.DateEnd.Format(dateLayout), rent.Value, rent.Renter, rent.Receiver,
+			apt.Name, apt.Address, acquisitionDate, apt.PurchasePrice, apt.FinancingBank, strings.Join(apt.ListingURLs, " "), status,
 		})
 	}
 
-	return s.upsertDataInRange(r.Apartment, rentCell, dataToWrite)
+	if err := s.ensureSheet(apartmentsSheet, apartmentsHeaders); err != nil {
+		return err
+	}
+
+	return s.replaceDataInSheetRange(apartmentsSheet, readApartmentsCells, apartmentsCell, dataToWrite)
 }
 
-func (s *SheetsClient) AddMiscellaneousExpense(m *models.MiscellaneousExpense) error {
-	existingExpenses, err := s.GetMiscellaneousExpenses(m.Apartment)
+// RenameApartment renames the apartment sheet and replaces the old name in every cell of the other sheets
+// (registry, rules, etc.) which holds exactly the old name, all in a single request
+func (s *SheetsClient) RenameApartment(oldName, newName string) error {
+	sheetIds, err := s.sheetIds()
 	if err != nil {
 		return err
 	}
 
-	existingExpenses = append(existingExpenses, m)
-	sort.Slice(existingExpenses, func(i, j int) bool {
-		return existingExpenses[i].Date.Before(existingExpenses[j].Date)
-	})
+	apartmentSheetId, ok := sheetIds[oldName]
+	if !ok {
+		return fmt.Errorf("sheet %s not found", oldName)
+	}
 
-	var dataToWrite [][]interface{}
-	for _, e := range existingExpenses {
-		dataToWrite = append(dataToWrite, []interface{}{e.Date.Format(dateLayout), e.Value, e.Description, e.Payer})
+	requests := []*sheets.Request{{UpdateSheetProperties: &sheets.UpdateSheetPropertiesRequest{
+		Properties: &sheets.SheetProperties{SheetId: apartmentSheetId, Title: newName},
+		Fields:     "title",
+	}}}
+	for title, id := range sheetIds {
+		if !strings.HasPrefix(title, "[") {
+			continue
+		}
+		requests = append(requests, &sheets.Request{FindReplace: &sheets.FindReplaceRequest{
+			Find:            oldName,
+			Replacement:     newName,
+			MatchCase:       true,
+			MatchEntireCell: true,
+			SheetId:         id,
+			ForceSendFields: []string{"SheetId"},
+		}})
+	}
+
+	_, err = s.Spreadsheets.BatchUpdate(s.sheetsId, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: requests,
+	}).Do()
+	if err != nil {
+		return err
 	}
 
-	return s.upsertDataInRange(m.Apartment, miscellaneousExpenseCell, dataToWrite)
+	log.Printf("apartment %s renamed to %s", oldName, newName)
+	return nil
 }
 
-func (s *SheetsClient) GetMiscellaneousExpenses(apartment models.Apartment) ([]*models.MiscellaneousExpense, error) {
-	data, err := s.readDataFromRange(apartment, readMiscellaneousExpenseCells)
+// GetApartments returns every apartment sheet along with its registry data, apartments missing in the registry
+// are considered active and without any data
+func (s *SheetsClient) GetApartments() ([]*models.Apartment, error) {
+	// it runs on every apartment keyboard, so the sheets listed here also tell whether the registry exists
+	properties, err := s.sheetProperties()
 	if err != nil {
 		return nil, err
 	}
 
-	expenses := make([]*models.MiscellaneousExpense, 0)
-	for _, row := range data {
-		date, err := time.Parse(dateLayout, row[0].(string))
+	var registry []*models.Apartment
+	for _, p := range properties {
+		if p.Title != apartmentsSheet {
+			continue
+		}
+		apartmentsData, err := s.readDataFromSheetRange(apartmentsSheet, readApartmentsCells)
 		if err != nil {
-			log.Println("failed to parse date", err.Error(), row)
 			return nil, err
 		}
-
-		value, err := format.BrlToFloat64(row[1].(string))
-		if err != nil {
-			log.Println("failed to parse value of expense", row)
+		if registry, err = parseApartmentsRegistry(apartmentsData); err != nil {
 			return nil, err
 		}
-
-		expenses = append(expenses, &models.MiscellaneousExpense{
-			Date:        date,
-			Value:       value,
-			Description: row[2].(string),
-			Payer:       row[3].(string),
-			Apartment:   apartment,
-		})
 	}
 
-	return expenses, nil
-}
-
-func (s *SheetsClient) AddAmortization(a *models.Amortization) error {
-	payedAmortizations, err := s.GetPayedAmortizations(a.Apartment)
-	if err != nil {
-		return err
+	byName := make(map[string]*models.Apartment)
+	for _, a := range registry {
+		byName[a.Name] = a
 	}
 
-	payedAmortizations = append(payedAmortizations, a)
-	sort.Slice(payedAmortizations, func(i, j int) bool {
-		return payedAmortizations[i].Date.Before(payedAmortizations[j].Date)
-	})
-
-	var dataToWrite [][]interface{}
-	for _, pa := range payedAmortizations {
-		dataToWrite = append(dataToWrite, []interface{}{pa.Date.Format(dateLayout), pa.Value, pa.Payer})
+	apartmentSheets := filterApartmentSheets(properties)
+	apartments := make([]*models.Apartment, 0, len(apartmentSheets))
+	for _, sheet := range apartmentSheets {
+		a, ok := byName[sheet.Title]
+		if !ok {
+			a = &models.Apartment{Name: sheet.Title}
+		}
+		a.Id = sheet.SheetId
+		apartments = append(apartments, a)
 	}
 
-	return s.upsertDataInRange(a.Apartment, amortizationCell, dataToWrite)
+	return apartments, nil
 }
 
-func (s *SheetsClient) GetPayedAmortizations(apartment models.Apartment) ([]*models.Amortization, error) {
-	payedAmortizationsData, err := s.readDataFromRange(apartment, readAmortizationCells)
+func (s *SheetsClient) readApartmentsRegistry() ([]*models.Apartment, error) {
+	apartmentsData, err := s.readDataFromOptionalSheet(apartmentsSheet, readApartmentsCells)
 	if err != nil {
 		return nil, err
 	}
 
-	payedAmortizations := make([]*models.Amortization, 0)
-	for _, am := range payedAmortizationsData {
-		date, err := format.DDMMYYYYstringToTimeObj(am[0].(string))
-		if err != nil {
-			log.Println("failed to parse date", err.Error(), am)
-			return nil, err
+	return parseApartmentsRegistry(apartmentsData)
+}
+
+func parseApartmentsRegistry(apartmentsData [][]interface{}) ([]*models.Apartment, error) {
+	var err error
+	apartments := make([]*models.Apartment, 0)
+	for _, row := range apartmentsData {
+		// trailing empty cells are not returned by the API
+		cells := make([]string, len(apartmentsHeaders))
+		for i := range cells {
+			if i < len(row) {
+				cells[i] = row[i].(string)
+			}
+		}
+		if len(cells[0]) == 0 {
+			continue
 		}
 
-		value, err := format.BrlToFloat64(am[1].(string))
-		if err != nil {
-			log.Println("failed to parse value of financial installment", am)
-			return nil, err
+		a := &models.Apartment{
+			Name:          cells[0],
+			Address:       cells[1],
+			FinancingBank: cells[4],
+			ListingURLs:   strings.Fields(cells[5]),
+			Archived:      cells[6] == archivedStatus,
 		}
 
-		payedAmortizations = append(payedAmortizations, &models.Amortization{
-			Date:      date,
-			Value:     value,
-			Payer:     am[2].(string),
-			Apartment: apartment,
-		})
+		if len(cells[2]) > 0 {
+			a.AcquisitionDate, err = time.Parse(dateLayout, cells[2])
+			if err != nil {
+				log.Println("failed to parse acquisition date of apartment", err.Error(), row)
+				return nil, err
+			}
+		}
+
+		if len(cells[3]) > 0 {
+			a.PurchasePrice, err = format.BrlToFloat64(cells[3])
+			if err != nil {
+				log.Println("failed to parse purchase price of apartment", err.Error(), row)
+				return nil, err
+			}
+		}
+
+		apartments = append(apartments, a)
 	}
 
-	return payedAmortizations, nil
+	return apartments, nil
 }
 
-func (s *SheetsClient) AddFinancingInstallment(f *models.FinancingInstallment) error {
-	payedFinancialInstallments, err := s.GetPayedFinancialInstallments(f.Apartment)
+// layoutApartmentSheet writes the titles and headers of every table and formats its date and value columns
+func (s *SheetsClient) layoutApartmentSheet(a *models.Apartment, sheetId int64) error {
+	titles := make([]interface{}, columnIndex(addressLabelCell))
+	headers := make([]interface{}, columnIndex(addressLabelCell))
+	var requests []*sheets.Request
+	for _, t := range apartmentTables {
+		first := columnIndex(t.firstCell)
+		titles[first] = t.title
+		for i, h := range t.headers {
+			headers[first+i] = h
+		}
+		for _, c := range t.dateColumns {
+			requests = append(requests, columnFormatRequest(sheetId, first+c, "DATE", "dd/mm/yyyy"))
+		}
+		for _, c := range t.valueColumns {
+			requests = append(requests, columnFormatRequest(sheetId, first+c, "CURRENCY", "R$#,##0.00"))
+		}
+	}
+	requests = append(requests, &sheets.Request{UpdateSheetProperties: &sheets.UpdateSheetPropertiesRequest{
+		Properties: &sheets.SheetProperties{
+			SheetId:        sheetId,
+			GridProperties: &sheets.GridProperties{FrozenRowCount: 2},
+		},
+		Fields: "gridProperties.frozenRowCount",
+	}})
+
+	_, err := s.Spreadsheets.BatchUpdate(s.sheetsId, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: requests,
+	}).Do()
 	if err != nil {
 		return err
 	}
 
-	payedFinancialInstallments = append(payedFinancialInstallments, f)
-	sort.Slice(payedFinancialInstallments, func(i, j int) bool {
-		return payedFinancialInstallments[i].Date.Before(payedFinancialInstallments[j].Date)
-	})
+	return s.upsertDataInRange(*a, "A1", [][]interface{}{titles, headers})
+}
 
-	var dataToWrite [][]interface{}
-	for _, pfi := range payedFinancialInstallments {
-		dataToWrite = append(dataToWrite, []interface{}{pfi.Date.Format(dateLayout), pfi.Value, pfi.Payer})
-	}
+// addedColumns are the headers of the columns added to the apartment tables after sheets were laid out without them,
+// which migrateApartmentSheet inserts
+var addedColumns = map[string]bool{receiptHeader: true, optionHeader: true, categoryHeader: true, bookedHeader: true,
+	cleaningFeeHeader: true, receivedHeader: true}
 
-	return s.upsertDataInRange(f.Apartment, financingInstallmentCell, dataToWrite)
+// grownSheets are the sheets which gained columns after they were created, by their current headers
+func grownSheets() map[string][]interface{} {
+	return map[string][]interface{}{
+		recurringExpensesSheet: recurringExpensesHeaders,
+	}
 }
 
-func (s *SheetsClient) GetPayedFinancialInstallments(apartment models.Apartment) ([]*models.FinancingInstallment, error) {
-	payedFinancialInstallmentsData, err := s.readDataFromRange(apartment, readFinancialInstallmentCells)
+// migrateHeaders rewrites the headers of the existing sheets which gained columns, as headers are only written when
+// a sheet is created
+func (s *SheetsClient) migrateHeaders() error {
+	ids, err := s.sheetIds()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	payedFinancialInstallments := make([]*models.FinancingInstallment, 0)
-	for _, fi := range payedFinancialInstallmentsData {
-		date, err := format.DDMMYYYYstringToTimeObj(fi[0].(string))
-		if err != nil {
-			log.Println("failed to parse date", err.Error(), fi)
-			return nil, err
-		}
-
-		value, err := format.BrlToFloat64(fi[1].(string))
-		if err != nil {
-			log.Println("failed to parse value of financial installment", fi)
-			return nil, err
+	var data []*sheets.ValueRange
+	for sheet, headers := range grownSheets() {
+		if _, ok := ids[sheet]; ok {
+			data = append(data, &sheets.ValueRange{Range: a1Notation(sheet, "A1"), Values: [][]interface{}{headers}})
 		}
-
-		payedFinancialInstallments = append(payedFinancialInstallments, &models.FinancingInstallment{
-			Date:      date,
-			Value:     value,
-			Payer:     fi[2].(string),
-			Apartment: apartment,
-		})
+	}
+	if len(data) == 0 {
+		return nil
 	}
 
-	return payedFinancialInstallments, nil
+	_, err = s.Spreadsheets.Values.BatchUpdate(s.sheetsId, &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: "USER_ENTERED",
+		Data:             data,
+	}).Do()
+	return err
 }
 
-func (s *SheetsClient) GetExistingRents(apartment models.Apartment) ([]*models.Rent, error) {
-	existingRentsData, err := s.readDataFromRange(apartment, rentDatesCells)
+// migrateApartmentSheets brings the sheets laid out by older versions to the current layout of the apartment tables.
+// The template is migrated too, so new apartments copy the current layout
+func (s *SheetsClient) migrateApartmentSheets() error {
+	apartmentSheets, err := s.apartmentSheets()
 	if err != nil {
-		return nil, err
+		return err
+	}
+	ids, err := s.sheetIds()
+	if err != nil {
+		return err
+	}
+	if id, ok := ids[templateSheet]; ok {
+		apartmentSheets = append(apartmentSheets, &sheets.SheetProperties{SheetId: id, Title: templateSheet})
 	}
 
-	existingRents := make([]*models.Rent, 0)
-
-	for _, rent := range existingRentsData {
-		dateBegin, err := time.Parse(dateLayout, rent[0].(string))
-		if err != nil {
-			log.Println("failed to parse dateBegin", err.Error(), rent)
-			return nil, err
-		}
-
-		dateEnd, err := time.Parse(dateLayout, rent[1].(string))
-		if err != nil {
-			log.Println("failed to parse dateEnd", err.Error(), rent)
-			return nil, err
+	for _, sheet := range apartmentSheets {
+		if err := s.migrateApartmentSheet(sheet); err != nil {
+			return fmt.Errorf("%s: %v", sheet.Title, err)
 		}
+	}
+	return nil
+}
 
-		value, err := format.BrlToFloat64(rent[2].(string))
-		if err != nil {
-			log.Println("failed to parse value of rent", rent)
-			return nil, err
+// migrateApartmentSheet inserts the added columns missing from the tables of the sheet, telling them by the header in
+// the second row
+func (s *SheetsClient) migrateApartmentSheet(sheet *sheets.SheetProperties) error {
+	headerRows, err := s.readDataFromSheetRange(sheet.Title, "2:2")
+	if err != nil {
+		return err
+	}
+	var headers []string
+	if len(headerRows) > 0 {
+		for _, h := range headerRows[0] {
+			headers = append(headers, h.(string))
 		}
+	}
+	if len(missingColumns(headers)) == 0 {
+		return nil
+	}
 
-		existingRents = append(existingRents, &models.Rent{
-			DateBegin: dateBegin,
-			DateEnd:   dateEnd,
-			Value:     value,
-			Renter:    rent[3].(string),
-			Receiver:  rent[4].(string),
-			Apartment: apartment,
+	// the requests are applied in order, so each insertion counts the columns inserted before it
+	var requests []*sheets.Request
+	var headerCells []*sheets.ValueRange
+	categorized := false
+	for _, column := range missingColumns(headers) {
+		categorized = categorized || column.header == categoryHeader
+		requests = append(requests, &sheets.Request{InsertDimension: &sheets.InsertDimensionRequest{
+			Range: &sheets.DimensionRange{
+				SheetId:    sheet.SheetId,
+				Dimension:  "COLUMNS",
+				StartIndex: int64(column.index),
+				EndIndex:   int64(column.index + 1),
+			},
+		}})
+		headerCells = append(headerCells, &sheets.ValueRange{
+			Range:  a1Notation(sheet.Title, columnName(column.index)+"2"),
+			Values: [][]interface{}{{column.header}},
 		})
 	}
 
-	sort.Slice(existingRents, func(a, b int) bool {
-		return existingRents[a].DateBegin.Before(existingRents[b].DateBegin)
-	})
+	if _, err := s.Spreadsheets.BatchUpdate(s.sheetsId, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: requests,
+	}).Do(); err != nil {
+		return err
+	}
+	if _, err := s.Spreadsheets.Values.BatchUpdate(s.sheetsId, &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: "USER_ENTERED",
+		Data:             headerCells,
+	}).Do(); err != nil {
+		return err
+	}
+	log.Printf("sheet %s migrated to the current layout", sheet.Title)
 
-	return existingRents, nil
+	if categorized {
+		if err := s.categorizeServiceFees(models.Apartment{Name: sheet.Title}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (s *SheetsClient) GetPayedCondos(apartment models.Apartment) ([]*models.Condo, error) {
-	payedCondosData, err := s.readDataFromRange(apartment, readCondosCells)
+// serviceFeePrefix starts the description of the service fees registered before the expenses had a category
+const serviceFeePrefix = "taxa de serviço"
+
+// categorizeServiceFees sets the category of the service fees registered before the category column existed, which
+// were only told apart by their description
+func (s *SheetsClient) categorizeServiceFees(apartment models.Apartment) error {
+	expenses, err := s.GetMiscellaneousExpenses(apartment)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	changed := false
+	for _, e := range expenses {
+		if e.Category == models.CategoryNone && strings.HasPrefix(strings.ToLower(e.Description), serviceFeePrefix) {
+			e.Category = models.CategoryServiceFee
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return s.upsertDataInRange(apartment, miscellaneousExpenseCell, miscellaneousExpenseRows(expenses))
+}
+
+type missingColumn struct {
+	index  int
+	header string
+}
+
+// missingColumns simulates inserting the added columns missing from the headers, from left to right, returning the
+// index each one is inserted at
+func missingColumns(headers []string) []missingColumn {
+	headers = append([]string(nil), headers...)
+	var missing []missingColumn
+	for _, t := range apartmentTables {
+		first := columnIndex(t.firstCell)
+		for i, h := range t.headers {
+			column := first + i
+			if !addedColumns[h] || (column < len(headers) && headers[column] == h) {
+				continue
+			}
+			missing = append(missing, missingColumn{column, h})
+			if column < len(headers) {
+				headers = append(headers[:column], append([]string{h}, headers[column:]...)...)
+			}
+		}
+	}
+	return missing
+}
+
+// columnName returns the letters of the column of a zero based index, e.g. "Y" for 24
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// columnFormatRequest formats the cells of a column below the header rows
+func columnFormatRequest(sheetId int64, column int, formatType string, pattern string) *sheets.Request {
+	return &sheets.Request{RepeatCell: &sheets.RepeatCellRequest{
+		Range: &sheets.GridRange{
+			SheetId:          sheetId,
+			StartRowIndex:    2,
+			StartColumnIndex: int64(column),
+			EndColumnIndex:   int64(column + 1),
+		},
+		Cell: &sheets.CellData{
+			UserEnteredFormat: &sheets.CellFormat{
+				NumberFormat: &sheets.NumberFormat{Type: formatType, Pattern: pattern},
+			},
+		},
+		Fields: "userEnteredFormat.numberFormat",
+	}}
+}
+
+// columnIndex returns the zero based index of the column of a cell in A1 notation, e.g. 24 for "Y3"
+func columnIndex(cell string) int {
+	index := 0
+	for _, c := range cell {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		index = index*26 + int(c-'A'+1)
+	}
+	return index - 1
+}
+
+// AddBill appends data to the Bill table in the apartment sheet
+func (s *SheetsClient) AddBill(e *models.EnergyBill) error {
+	existingBills, err := s.GetPayedBills(e.Apartment)
+	if err != nil {
+		return err
+	}
+
+	existingBills = append(existingBills, e)
+	sort.Slice(existingBills, func(i, j int) bool {
+		return existingBills[i].Date.Before(existingBills[j].Date)
+	})
+
+	return s.upsertDataInRange(e.Apartment, billCell, billRows(existingBills))
+}
+
+func billRows(bills []*models.EnergyBill) [][]interface{} {
+	var rows [][]interface{}
+	for _, b := range bills {
+		rows = append(rows, []interface{}{b.Date.Format(dateLayout), b.Value, b.Payer, receiptsValue(b.Receipts)})
+	}
+	return rows
+}
+
+// AddRent appends data to the rent table in the apartment sheet
+func (s *SheetsClient) AddRent(r *models.Rent) error {
+	existingRents, err := s.GetExistingRents(r.Apartment)
+	if err != nil {
+		return err
+	}
+
+	existingRents = append(existingRents, r)
+	sort.Slice(existingRents, func(i, j int) bool {
+		return existingRents[i].DateBegin.Before(existingRents[j].DateBegin)
+	})
+
+	return s.upsertDataInRange(r.Apartment, rentCell, rentRows(existingRents))
+}
+
+func rentRows(rents []*models.Rent) [][]interface{} {
+	var rows [][]interface{}
+	for _, rent := range rents {
+		rows = append(rows, []interface{}{
+			rent.DateBegin.Format(dateLayout), rent.DateEnd.Format(dateLayout), rent.Value, rent.Renter, rent.Receiver,
+			optionalDate(rent.BookedAt), optionalValue(rent.CleaningFee), optionalDate(rent.ReceivedAt),
+		})
+	}
+	return rows
+}
+
+func (s *SheetsClient) AddMiscellaneousExpense(m *models.MiscellaneousExpense) error {
+	existingExpenses, err := s.GetMiscellaneousExpenses(m.Apartment)
+	if err != nil {
+		return err
+	}
+
+	existingExpenses = append(existingExpenses, m)
+	sort.Slice(existingExpenses, func(i, j int) bool {
+		return existingExpenses[i].Date.Before(existingExpenses[j].Date)
+	})
+
+	return s.upsertDataInRange(m.Apartment, miscellaneousExpenseCell, miscellaneousExpenseRows(existingExpenses))
+}
+
+func miscellaneousExpenseRows(expenses []*models.MiscellaneousExpense) [][]interface{} {
+	var rows [][]interface{}
+	for _, e := range expenses {
+		rows = append(rows, []interface{}{e.Date.Format(dateLayout), e.Value, e.Description, e.Payer, string(e.Category), receiptsValue(e.Receipts)})
+	}
+	return rows
+}
+
+// AddBatch merges the records of the batch with the existing ones and writes all tables in a single request
+func (s *SheetsClient) AddBatch(b *models.Batch) error {
+	var ranges []*sheets.ValueRange
+
+	rentsByApartment := make(map[string][]*models.Rent)
+	var apartments []models.Apartment
+	for _, r := range b.Rents {
+		if _, ok := rentsByApartment[r.Apartment.Name]; !ok {
+			apartments = append(apartments, r.Apartment)
+		}
+		rentsByApartment[r.Apartment.Name] = append(rentsByApartment[r.Apartment.Name], r)
+	}
+	for _, apt := range apartments {
+		rents, err := s.GetExistingRents(apt)
+		if err != nil {
+			return err
+		}
+		rents = append(rents, rentsByApartment[apt.Name]...)
+		sort.Slice(rents, func(i, j int) bool {
+			return rents[i].DateBegin.Before(rents[j].DateBegin)
+		})
+		ranges = append(ranges, newValueRange(apt, rentCell, rentRows(rents)))
+	}
+
+	expensesByApartment := make(map[string][]*models.MiscellaneousExpense)
+	apartments = nil
+	for _, e := range b.MiscellaneousExpenses {
+		if _, ok := expensesByApartment[e.Apartment.Name]; !ok {
+			apartments = append(apartments, e.Apartment)
+		}
+		expensesByApartment[e.Apartment.Name] = append(expensesByApartment[e.Apartment.Name], e)
+	}
+	for _, apt := range apartments {
+		expenses, err := s.GetMiscellaneousExpenses(apt)
+		if err != nil {
+			return err
+		}
+		expenses = append(expenses, expensesByApartment[apt.Name]...)
+		sort.Slice(expenses, func(i, j int) bool {
+			return expenses[i].Date.Before(expenses[j].Date)
+		})
+		ranges = append(ranges, newValueRange(apt, miscellaneousExpenseCell, miscellaneousExpenseRows(expenses)))
+	}
+
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	resp, err := s.Spreadsheets.Values.BatchUpdate(s.sheetsId, &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: "USER_ENTERED",
+		Data:             ranges,
+	}).Do()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("%d cells updated in batch", resp.TotalUpdatedCells)
+	return nil
+}
+
+func (s *SheetsClient) GetMiscellaneousExpenses(apartment models.Apartment) ([]*models.MiscellaneousExpense, error) {
+	data, err := s.readDataFromRange(apartment, readMiscellaneousExpenseCells)
+	if err != nil {
+		return nil, err
+	}
+
+	expenses := make([]*models.MiscellaneousExpense, 0)
+	for _, row := range data {
+		date, err := time.Parse(dateLayout, row[0].(string))
+		if err != nil {
+			log.Println("failed to parse date", err.Error(), row)
+			return nil, err
+		}
+
+		value, err := format.BrlToFloat64(row[1].(string))
+		if err != nil {
+			log.Println("failed to parse value of expense", row)
+			return nil, err
+		}
+
+		var category models.ExpenseCategory
+		if len(row) > 4 {
+			var ok bool
+			if category, ok = models.ParseExpenseCategory(row[4].(string)); !ok {
+				log.Println("ignoring unknown category of expense", row)
+			}
+		}
+
+		expenses = append(expenses, &models.MiscellaneousExpense{
+			Date:        date,
+			Value:       value,
+			Description: row[2].(string),
+			Payer:       row[3].(string),
+			Category:    category,
+			Receipts:    receiptsCell(row, 5),
+			Apartment:   apartment,
+		})
+	}
+
+	return expenses, nil
+}
+
+func (s *SheetsClient) AddAmortization(a *models.Amortization) error {
+	payedAmortizations, err := s.GetPayedAmortizations(a.Apartment)
+	if err != nil {
+		return err
+	}
+
+	payedAmortizations = append(payedAmortizations, a)
+	sort.Slice(payedAmortizations, func(i, j int) bool {
+		return payedAmortizations[i].Date.Before(payedAmortizations[j].Date)
+	})
+
+	return s.upsertDataInRange(a.Apartment, amortizationCell, amortizationRows(payedAmortizations))
+}
+
+func amortizationRows(amortizations []*models.Amortization) [][]interface{} {
+	var rows [][]interface{}
+	for _, a := range amortizations {
+		rows = append(rows, []interface{}{a.Date.Format(dateLayout), a.Value, a.Payer, string(a.Option), receiptsValue(a.Receipts)})
+	}
+	return rows
+}
+
+func (s *SheetsClient) GetPayedAmortizations(apartment models.Apartment) ([]*models.Amortization, error) {
+	payedAmortizationsData, err := s.readDataFromRange(apartment, readAmortizationCells)
+	if err != nil {
+		return nil, err
+	}
+
+	payedAmortizations := make([]*models.Amortization, 0)
+	for _, am := range payedAmortizationsData {
+		date, err := format.DDMMYYYYstringToTimeObj(am[0].(string))
+		if err != nil {
+			log.Println("failed to parse date", err.Error(), am)
+			return nil, err
+		}
+
+		value, err := format.BrlToFloat64(am[1].(string))
+		if err != nil {
+			log.Println("failed to parse value of financial installment", am)
+			return nil, err
+		}
+
+		var option models.AmortizationOption
+		if len(am) > 3 && len(am[3].(string)) > 0 {
+			var ok bool
+			if option, ok = models.ParseAmortizationOption(am[3].(string)); !ok {
+				log.Println("ignoring unknown option of amortization", am)
+			}
+		}
+
+		payedAmortizations = append(payedAmortizations, &models.Amortization{
+			Date:      date,
+			Value:     value,
+			Payer:     am[2].(string),
+			Option:    option,
+			Receipts:  receiptsCell(am, 4),
+			Apartment: apartment,
+		})
+	}
+
+	return payedAmortizations, nil
+}
+
+func (s *SheetsClient) AddFinancingInstallment(f *models.FinancingInstallment) error {
+	payedFinancialInstallments, err := s.GetPayedFinancialInstallments(f.Apartment)
+	if err != nil {
+		return err
+	}
+
+	payedFinancialInstallments = append(payedFinancialInstallments, f)
+	sort.Slice(payedFinancialInstallments, func(i, j int) bool {
+		return payedFinancialInstallments[i].Date.Before(payedFinancialInstallments[j].Date)
+	})
+
+	return s.upsertDataInRange(f.Apartment, financingInstallmentCell, financingInstallmentRows(payedFinancialInstallments))
+}
+
+func financingInstallmentRows(installments []*models.FinancingInstallment) [][]interface{} {
+	var rows [][]interface{}
+	for _, f := range installments {
+		rows = append(rows, []interface{}{f.Date.Format(dateLayout), f.Value, f.Payer, receiptsValue(f.Receipts)})
+	}
+	return rows
+}
+
+func (s *SheetsClient) GetPayedFinancialInstallments(apartment models.Apartment) ([]*models.FinancingInstallment, error) {
+	payedFinancialInstallmentsData, err := s.readDataFromRange(apartment, readFinancialInstallmentCells)
+	if err != nil {
+		return nil, err
+	}
+
+	payedFinancialInstallments := make([]*models.FinancingInstallment, 0)
+	for _, fi := range payedFinancialInstallmentsData {
+		date, err := format.DDMMYYYYstringToTimeObj(fi[0].(string))
+		if err != nil {
+			log.Println("failed to parse date", err.Error(), fi)
+			return nil, err
+		}
+
+		value, err := format.BrlToFloat64(fi[1].(string))
+		if err != nil {
+			log.Println("failed to parse value of financial installment", fi)
+			return nil, err
+		}
+
+		payedFinancialInstallments = append(payedFinancialInstallments, &models.FinancingInstallment{
+			Date:      date,
+			Value:     value,
+			Payer:     fi[2].(string),
+			Receipts:  receiptsCell(fi, 3),
+			Apartment: apartment,
+		})
+	}
+
+	return payedFinancialInstallments, nil
+}
+
+func (s *SheetsClient) GetExistingRents(apartment models.Apartment) ([]*models.Rent, error) {
+	existingRentsData, err := s.readDataFromRange(apartment, rentDatesCells)
+	if err != nil {
+		return nil, err
+	}
+
+	existingRents := make([]*models.Rent, 0)
+
+	for _, rent := range existingRentsData {
+		dateBegin, err := time.Parse(dateLayout, rent[0].(string))
+		if err != nil {
+			log.Println("failed to parse dateBegin", err.Error(), rent)
+			return nil, err
+		}
+
+		dateEnd, err := time.Parse(dateLayout, rent[1].(string))
+		if err != nil {
+			log.Println("failed to parse dateEnd", err.Error(), rent)
+			return nil, err
+		}
+
+		value, err := format.BrlToFloat64(rent[2].(string))
+		if err != nil {
+			log.Println("failed to parse value of rent", rent)
+			return nil, err
+		}
+
+		// the booking columns are optional and trailing empty cells are not returned by the API
+		var bookedAt, receivedAt time.Time
+		var cleaningFee float64
+		if len(rent) > 5 && len(rent[5].(string)) > 0 {
+			if bookedAt, err = time.Parse(dateLayout, rent[5].(string)); err != nil {
+				log.Println("failed to parse booking date of rent", err.Error(), rent)
+				return nil, err
+			}
+		}
+		if len(rent) > 6 && len(rent[6].(string)) > 0 {
+			if cleaningFee, err = format.BrlToFloat64(rent[6].(string)); err != nil {
+				log.Println("failed to parse cleaning fee of rent", err.Error(), rent)
+				return nil, err
+			}
+		}
+		if len(rent) > 7 && len(rent[7].(string)) > 0 {
+			if receivedAt, err = time.Parse(dateLayout, rent[7].(string)); err != nil {
+				log.Println("failed to parse receipt date of rent", err.Error(), rent)
+				return nil, err
+			}
+		}
+
+		existingRents = append(existingRents, &models.Rent{
+			DateBegin:   dateBegin,
+			DateEnd:     dateEnd,
+			Value:       value,
+			Renter:      rent[3].(string),
+			Receiver:    rent[4].(string),
+			BookedAt:    bookedAt,
+			CleaningFee: cleaningFee,
+			ReceivedAt:  receivedAt,
+			Apartment:   apartment,
+		})
+	}
+
+	sort.Slice(existingRents, func(a, b int) bool {
+		return existingRents[a].DateBegin.Before(existingRents[b].DateBegin)
+	})
+
+	return existingRents, nil
+}
+
+func (s *SheetsClient) GetPayedCondos(apartment models.Apartment) ([]*models.Condo, error) {
+	payedCondosData, err := s.readDataFromRange(apartment, readCondosCells)
+	if err != nil {
+		return nil, err
+	}
+
+	existingCondos := make([]*models.Condo, 0)
+	for _, condo := range payedCondosData {
+		date, err := time.Parse(dateLayout, condo[0].(string))
+		if err != nil {
+			log.Println("failed to parse date of condo bill", err.Error(), condo)
+			return nil, err
+		}
+
+		value, err := format.BrlToFloat64(condo[1].(string))
+		if err != nil {
+			log.Println("failed to parse value of condo", err.Error(), condo)
+		}
+
+		existingCondos = append(existingCondos, &models.Condo{
+			Value:     value,
+			Date:      date,
+			Payer:     condo[2].(string),
+			Receipts:  receiptsCell(condo, 3),
+			Apartment: apartment,
+		})
+	}
+
+	return existingCondos, nil
+}
+
+func (s *SheetsClient) GetPayedBills(apartment models.Apartment) ([]*models.EnergyBill, error) {
+	payedBillsData, err := s.readDataFromRange(apartment, readBillCells)
+	if err != nil {
+		return nil, err
+	}
+
+	existingBills := make([]*models.EnergyBill, 0)
+	for _, bill := range payedBillsData {
+		date, err := time.Parse(dateLayout, bill[0].(string))
+		if err != nil {
+			log.Println("failed to parse date of bill", err.Error(), bill)
+			return nil, err
+		}
+
+		value, err := format.BrlToFloat64(bill[1].(string))
+		if err != nil {
+			log.Println("failed to parse value of bill", err.Error(), bill)
+		}
+
+		existingBills = append(existingBills, &models.EnergyBill{
+			Value:     value,
+			Date:      date,
+			Payer:     bill[2].(string),
+			Receipts:  receiptsCell(bill, 3),
+			Apartment: apartment,
+		})
+	}
+
+	return existingBills, nil
+}
+
+func (s *SheetsClient) GetPayedCleanings(apartment models.Apartment) ([]*models.Cleaning, error) {
+	payedCleaningsData, err := s.readDataFromRange(apartment, readCleaningCells)
+	if err != nil {
+		return nil, err
+	}
+
+	existingCleanings := make([]*models.Cleaning, 0)
+	for _, cleaning := range payedCleaningsData {
+		date, err := time.Parse(dateLayout, cleaning[0].(string))
+		if err != nil {
+			log.Println("failed to parse date of cleaning", err.Error(), cleaning)
+			return nil, err
+		}
+
+		value, err := format.BrlToFloat64(cleaning[1].(string))
+		if err != nil {
+			log.Println("failed to parse value of cleaning", err.Error(), cleaning)
+		}
+
+		existingCleanings = append(existingCleanings, &models.Cleaning{
+			Value:     value,
+			Date:      date,
+			Payer:     cleaning[2].(string),
+			Receipts:  receiptsCell(cleaning, 3),
+			Apartment: apartment,
+		})
+	}
+
+	return existingCleanings, nil
+}
+
+// AddAttachment links the blob key of a receipt to the record of its type, date and value, in the receipt column of
+// the record table
+func (s *SheetsClient) AddAttachment(a *models.Attachment) error {
+	switch a.RecordType {
+	case models.RecordCleaning:
+		records, err := s.GetPayedCleanings(a.Apartment)
+		if err != nil {
+			return err
+		}
+		if err := attachReceipt(records, a, func(r *models.Cleaning) (time.Time, float64, *[]string) { return r.Date, r.Value, &r.Receipts }); err != nil {
+			return err
+		}
+		return s.upsertDataInRange(a.Apartment, cleaningCell, cleaningRows(records))
+	case models.RecordCondo:
+		records, err := s.GetPayedCondos(a.Apartment)
+		if err != nil {
+			return err
+		}
+		if err := attachReceipt(records, a, func(r *models.Condo) (time.Time, float64, *[]string) { return r.Date, r.Value, &r.Receipts }); err != nil {
+			return err
+		}
+		return s.upsertDataInRange(a.Apartment, condoCell, condoRows(records))
+	case models.RecordEnergyBill:
+		records, err := s.GetPayedBills(a.Apartment)
+		if err != nil {
+			return err
+		}
+		if err := attachReceipt(records, a, func(r *models.EnergyBill) (time.Time, float64, *[]string) { return r.Date, r.Value, &r.Receipts }); err != nil {
+			return err
+		}
+		return s.upsertDataInRange(a.Apartment, billCell, billRows(records))
+	case models.RecordMiscellaneousExpense:
+		records, err := s.GetMiscellaneousExpenses(a.Apartment)
+		if err != nil {
+			return err
+		}
+		if err := attachReceipt(records, a, func(r *models.MiscellaneousExpense) (time.Time, float64, *[]string) {
+			return r.Date, r.Value, &r.Receipts
+		}); err != nil {
+			return err
+		}
+		return s.upsertDataInRange(a.Apartment, miscellaneousExpenseCell, miscellaneousExpenseRows(records))
+	case models.RecordAmortization:
+		records, err := s.GetPayedAmortizations(a.Apartment)
+		if err != nil {
+			return err
+		}
+		if err := attachReceipt(records, a, func(r *models.Amortization) (time.Time, float64, *[]string) { return r.Date, r.Value, &r.Receipts }); err != nil {
+			return err
+		}
+		return s.upsertDataInRange(a.Apartment, amortizationCell, amortizationRows(records))
+	case models.RecordFinancingInstallment:
+		records, err := s.GetPayedFinancialInstallments(a.Apartment)
+		if err != nil {
+			return err
+		}
+		if err := attachReceipt(records, a, func(r *models.FinancingInstallment) (time.Time, float64, *[]string) {
+			return r.Date, r.Value, &r.Receipts
+		}); err != nil {
+			return err
+		}
+		return s.upsertDataInRange(a.Apartment, financingInstallmentCell, financingInstallmentRows(records))
+	}
+	return fmt.Errorf("registros do tipo %v nao têm comprovante", a.RecordType)
+}
+
+// attachReceipt appends the key of the attachment to the receipts of the record of its date and value
+func attachReceipt[T any](records []T, a *models.Attachment, fields func(T) (time.Time, float64, *[]string)) error {
+	for _, r := range records {
+		date, value, receipts := fields(r)
+		if date.Equal(a.Date) && math.Abs(value-a.Value) < 0.005 {
+			*receipts = append(*receipts, a.Key)
+			return nil
+		}
+	}
+	return errors.ErrAttachmentRecordNotFound
+}
+
+// GetAttachments returns the receipts linked to the expense records of the apartment
+func (s *SheetsClient) GetAttachments(apartment models.Apartment) ([]*models.Attachment, error) {
+	var attachments []*models.Attachment
+	add := func(t models.RecordType, date time.Time, value float64, receipts []string) {
+		for _, key := range receipts {
+			attachments = append(attachments, &models.Attachment{RecordType: t, Date: date, Value: value, Key: key, Apartment: apartment})
+		}
+	}
+
+	cleanings, err := s.GetPayedCleanings(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range cleanings {
+		add(models.RecordCleaning, r.Date, r.Value, r.Receipts)
+	}
+
+	condos, err := s.GetPayedCondos(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range condos {
+		add(models.RecordCondo, r.Date, r.Value, r.Receipts)
+	}
+
+	bills, err := s.GetPayedBills(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range bills {
+		add(models.RecordEnergyBill, r.Date, r.Value, r.Receipts)
+	}
+
+	expenses, err := s.GetMiscellaneousExpenses(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range expenses {
+		add(models.RecordMiscellaneousExpense, r.Date, r.Value, r.Receipts)
+	}
+
+	amortizations, err := s.GetPayedAmortizations(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range amortizations {
+		add(models.RecordAmortization, r.Date, r.Value, r.Receipts)
+	}
+
+	installments, err := s.GetPayedFinancialInstallments(apartment)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range installments {
+		add(models.RecordFinancingInstallment, r.Date, r.Value, r.Receipts)
+	}
+
+	return attachments, nil
+}
+
+// receiptsCell reads the receipt column of a record row, which the API omits when empty
+func receiptsCell(row []interface{}, column int) []string {
+	if len(row) <= column {
+		return nil
+	}
+	var receipts []string
+	for _, key := range strings.Split(row[column].(string), "\n") {
+		if key = strings.TrimSpace(key); len(key) > 0 {
+			receipts = append(receipts, key)
+		}
+	}
+	return receipts
+}
+
+func receiptsValue(receipts []string) string {
+	return strings.Join(receipts, "\n")
+}
+
+// GetStatementRules reads the rules which relate bank statement lines to records, one per row of the statement rules sheet
+func (s *SheetsClient) GetStatementRules() ([]*models.StatementRule, error) {
+	data, err := s.readDataFromSheetRange(statementRulesSheet, readStatementRulesCells)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]*models.StatementRule, 0)
+	for _, row := range data {
+		if len(row) < 3 {
+			log.Println("ignoring incomplete statement rule", row)
+			continue
+		}
+
+		recordType, ok := models.ParseRecordType(row[1].(string))
+		if !ok {
+			log.Println("ignoring statement rule with unknown record type", row)
+			continue
+		}
+
+		rules = append(rules, &models.StatementRule{
+			Pattern:    row[0].(string),
+			RecordType: recordType,
+			Apartment:  models.Apartment{Name: row[2].(string)},
+		})
+	}
+
+	return rules, nil
+}
+
+// AddUser allows a Telegram user, replacing the role of the user if it was already allowed
+func (s *SheetsClient) AddUser(u *models.User) error {
+	users, err := s.GetUsers()
+	if err != nil {
+		return err
+	}
+
+	users = append(removeUser(users, u.Id), u)
+	return s.writeUsers(users)
+}
+
+func (s *SheetsClient) RemoveUser(id int64) error {
+	users, err := s.GetUsers()
+	if err != nil {
+		return err
+	}
+
+	return s.writeUsers(removeUser(users, id))
+}
+
+func (s *SheetsClient) GetUsers() ([]*models.User, error) {
+	usersData, err := s.readDataFromOptionalSheet(accessSheet, readUsersCells)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*models.User, 0)
+	for _, u := range usersData {
+		if len(u) < 3 {
+			log.Println("ignoring incomplete user", u)
+			continue
+		}
+
+		id, err := strconv.ParseInt(u[0].(string), 10, 64)
+		if err != nil {
+			log.Println("failed to parse id of user", err.Error(), u)
+			return nil, err
+		}
+
+		role, ok := models.ParseRole(u[2].(string))
+		if !ok {
+			log.Println("ignoring user with unknown role", u)
+			continue
+		}
+
+		users = append(users, &models.User{
+			Id:   id,
+			Name: u[1].(string),
+			Role: role,
+		})
+	}
+
+	return users, nil
+}
+
+func (s *SheetsClient) writeUsers(users []*models.User) error {
+	var dataToWrite [][]interface{}
+	for _, u := range users {
+		dataToWrite = append(dataToWrite, []interface{}{textCell(strconv.FormatInt(u.Id, 10)), u.Name, string(u.Role)})
+	}
+
+	if err := s.ensureSheet(accessSheet, accessHeaders); err != nil {
+		return err
+	}
+
+	return s.replaceDataInSheetRange(accessSheet, readUsersCells, usersCell, dataToWrite)
+}
+
+func removeUser(users []*models.User, id int64) []*models.User {
+	var kept []*models.User
+	for _, u := range users {
+		if u.Id != id {
+			kept = append(kept, u)
+		}
+	}
+	return kept
+}
+
+func (s *SheetsClient) AddAllowedChat(c *models.AllowedChat) error {
+	chats, err := s.GetAllowedChats()
+	if err != nil {
+		return err
+	}
+
+	chats = append(removeAllowedChat(chats, c.Id), c)
+	return s.writeAllowedChats(chats)
+}
+
+func (s *SheetsClient) RemoveAllowedChat(id int64) error {
+	chats, err := s.GetAllowedChats()
+	if err != nil {
+		return err
+	}
+
+	return s.writeAllowedChats(removeAllowedChat(chats, id))
+}
+
+func (s *SheetsClient) GetAllowedChats() ([]*models.AllowedChat, error) {
+	chatsData, err := s.readDataFromOptionalSheet(accessSheet, readAllowedChatsCells)
+	if err != nil {
+		return nil, err
+	}
+
+	chats := make([]*models.AllowedChat, 0)
+	for _, c := range chatsData {
+		if len(c) < 2 {
+			log.Println("ignoring incomplete chat", c)
+			continue
+		}
+
+		id, err := strconv.ParseInt(c[0].(string), 10, 64)
+		if err != nil {
+			log.Println("failed to parse id of chat", err.Error(), c)
+			return nil, err
+		}
+
+		chats = append(chats, &models.AllowedChat{
+			Id:   id,
+			Name: c[1].(string),
+		})
+	}
+
+	return chats, nil
+}
+
+func (s *SheetsClient) writeAllowedChats(chats []*models.AllowedChat) error {
+	var dataToWrite [][]interface{}
+	for _, c := range chats {
+		dataToWrite = append(dataToWrite, []interface{}{textCell(strconv.FormatInt(c.Id, 10)), c.Name})
+	}
+
+	if err := s.ensureSheet(accessSheet, accessHeaders); err != nil {
+		return err
+	}
+
+	return s.replaceDataInSheetRange(accessSheet, readAllowedChatsCells, allowedChatsCell, dataToWrite)
+}
+
+func removeAllowedChat(chats []*models.AllowedChat, id int64) []*models.AllowedChat {
+	var kept []*models.AllowedChat
+	for _, c := range chats {
+		if c.Id != id {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// GetAvailableApartments query the existing sheets and return its titles in an array
+func (s *SheetsClient) GetAvailableApartments() ([]string, error) {
+	apartmentSheets, err := s.apartmentSheets()
+	if err != nil {
+		return nil, err
+	}
+
+	var apartmentNames []string
+	for _, sheet := range apartmentSheets {
+		apartmentNames = append(apartmentNames, sheet.Title)
+	}
+
+	return apartmentNames, nil
+}
+
+// apartmentSheets returns the title and id of the sheets of apartments, in their order in the spreadsheet
+func (s *SheetsClient) apartmentSheets() ([]*sheets.SheetProperties, error) {
+	properties, err := s.sheetProperties()
+	if err != nil {
+		return nil, err
+	}
+
+	return filterApartmentSheets(properties), nil
+}
+
+// sheetProperties returns the title and id of every sheet of the spreadsheet
+func (s *SheetsClient) sheetProperties() ([]*sheets.SheetProperties, error) {
+	sheetData, err := s.Spreadsheets.Get(s.sheetsId).Fields("sheets.properties(sheetId,title)").Do()
+	if err != nil {
+		return nil, err
+	}
+
+	var properties []*sheets.SheetProperties
+	for _, sheet := range sheetData.Sheets {
+		properties = append(properties, sheet.Properties)
+	}
+	return properties, nil
+}
+
+// filterApartmentSheets keeps the sheets of apartments, the other ones have their titles in brackets
+func filterApartmentSheets(properties []*sheets.SheetProperties) []*sheets.SheetProperties {
+	var apartmentSheets []*sheets.SheetProperties
+	for _, p := range properties {
+		if !strings.HasPrefix(p.Title, "[") {
+			apartmentSheets = append(apartmentSheets, p)
+		}
+	}
+	return apartmentSheets
+}
+
+// a1Notation returns the range of cells of a sheet, quoting its title as it may contain spaces or brackets
+func a1Notation(sheet string, cells string) string {
+	return fmt.Sprintf("'%s'!%s", strings.ReplaceAll(sheet, "'", "''"), cells)
+}
+
+func (s *SheetsClient) upsertDataInRange(apartment models.Apartment, upsertRange string, data [][]interface{}) error {
+	return s.upsertDataInSheetRange(apartment.Name, upsertRange, data)
+}
+
+func (s *SheetsClient) upsertDataInSheetRange(sheet string, upsertRange string, data [][]interface{}) error {
+	a1Range := a1Notation(sheet, upsertRange)
+	resp, err := s.Spreadsheets.Values.Update(s.sheetsId, a1Range, &sheets.ValueRange{
+		Range:  a1Range,
+		Values: data,
+	}).ValueInputOption("USER_ENTERED").Do()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("values added to range %s", resp.UpdatedRange)
+	return nil
+}
+
+func newValueRange(apartment models.Apartment, upsertRange string, data [][]interface{}) *sheets.ValueRange {
+	a1Range := a1Notation(apartment.Name, upsertRange)
+	return &sheets.ValueRange{
+		Range:  a1Range,
+		Values: data,
+	}
+}
+
+// sheetIds returns the id of each sheet of the spreadsheet by its title
+func (s *SheetsClient) sheetIds() (map[string]int64, error) {
+	properties, err := s.sheetProperties()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]int64)
+	for _, p := range properties {
+		ids[p.Title] = p.SheetId
+	}
+	return ids, nil
+}
+
+// readDataFromOptionalSheet reads a range of a sheet which is created on its first write, before that it has no data
+func (s *SheetsClient) readDataFromOptionalSheet(sheet string, readRange string) ([][]interface{}, error) {
+	ids, err := s.sheetIds()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := ids[sheet]; !ok {
+		return nil, nil
+	}
+
+	return s.readDataFromSheetRange(sheet, readRange)
+}
+
+// ensureSheet creates the sheet, with the headers in its first row, if it doesn't exist
+func (s *SheetsClient) ensureSheet(sheet string, headers []interface{}) error {
+	ids, err := s.sheetIds()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := ids[sheet]; ok {
+		return nil
+	}
+
+	_, err = s.Spreadsheets.BatchUpdate(s.sheetsId, &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{AddSheet: &sheets.AddSheetRequest{
+			Properties: &sheets.SheetProperties{Title: sheet},
+		}}},
+	}).Do()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("sheet %s created", sheet)
+	return s.upsertDataInSheetRange(sheet, "A1", [][]interface{}{headers})
+}
+
+// textCell keeps the value as text, otherwise long ids would be formatted as numbers in scientific notation
+func textCell(value string) string {
+	return "'" + value
+}
+
+// replaceDataInSheetRange clears the whole table before writing it, so rows removed from it don't linger
+func (s *SheetsClient) replaceDataInSheetRange(sheet string, tableRange string, upsertRange string, data [][]interface{}) error {
+	_, err := s.Spreadsheets.Values.Clear(s.sheetsId, a1Notation(sheet, tableRange), &sheets.ClearValuesRequest{}).Do()
+	if err != nil {
+		return err
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	return s.upsertDataInSheetRange(sheet, upsertRange, data)
+}
+
+func (s *SheetsClient) readDataFromRange(apartment models.Apartment, readRange string) ([][]interface{}, error) {
+	return s.readDataFromSheetRange(apartment.Name, readRange)
+}
+
+func (s *SheetsClient) readDataFromSheetRange(sheet string, readRange string) ([][]interface{}, error) {
+	a1Range := a1Notation(sheet, readRange)
+	cells, err := s.Spreadsheets.Values.Get(s.sheetsId, a1Range).ValueRenderOption("FORMATTED_VALUE").Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return cells.Values, nil
+}
+
+func (s *SheetsClient) AddRecurringExpense(r *models.RecurringExpense) error {
+	expenses, dues, err := s.readRecurringExpensesSheet()
+	if err != nil {
+		return err
+	}
+
+	// a replaced recurring expense keeps the months it already handled
+	for _, e := range expenses {
+		if e.Apartment.Name == r.Apartment.Name && e.Type == r.Type && e.LastMonth.After(r.LastMonth) {
+			r.LastMonth = e.LastMonth
+		}
+	}
+
+	// the recurring expense takes over the due day of the reminders of the same payment
+	dues = removePaymentDue(dues, &models.PaymentDue{Apartment: r.Apartment, Type: r.Type})
+	return s.writeRecurringExpensesSheet(append(removeRecurringExpense(expenses, r), r), dues)
+}
+
+func (s *SheetsClient) RemoveRecurringExpense(r *models.RecurringExpense) error {
+	expenses, dues, err := s.readRecurringExpensesSheet()
+	if err != nil {
+		return err
+	}
+
+	return s.writeRecurringExpensesSheet(removeRecurringExpense(expenses, r), dues)
+}
+
+func (s *SheetsClient) GetRecurringExpenses() ([]*models.RecurringExpense, error) {
+	expenses, _, err := s.readRecurringExpensesSheet()
+	return expenses, err
+}
+
+// readRecurringExpensesSheet reads the recurring expenses and the payment dues, which are the rows only reminded
+func (s *SheetsClient) readRecurringExpensesSheet() ([]*models.RecurringExpense, []*models.PaymentDue, error) {
+	expensesData, err := s.readDataFromOptionalSheet(recurringExpensesSheet, readRecurringExpensesCells)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	expenses := make([]*models.RecurringExpense, 0)
+	dues := make([]*models.PaymentDue, 0)
+	for _, row := range expensesData {
+		// trailing empty cells are not returned by the API
+		cells := make([]string, len(recurringExpensesHeaders))
+		for i := range cells {
+			if i < len(row) {
+				cells[i] = row[i].(string)
+			}
+		}
+
+		recordType, ok := models.ParseRecordType(cells[1])
+		if len(cells[0]) == 0 || !ok {
+			log.Println("ignoring invalid recurring expense", row)
+			continue
+		}
+
+		if cells[7] == remindMode {
+			p, err := parsePaymentDue(cells, recordType)
+			if err != nil {
+				log.Println("failed to parse payment due", err.Error(), row)
+				return nil, nil, err
+			}
+			dues = append(dues, p)
+			continue
+		}
+
+		r := &models.RecurringExpense{
+			Apartment: models.Apartment{Name: cells[0]},
+			Type:      recordType,
+			Payer:     cells[4],
+			Confirm:   cells[7] == confirmMode,
+		}
+
+		if r.Value, err = format.BrlToFloat64(cells[2]); err != nil {
+			log.Println("failed to parse value of recurring expense", err.Error(), row)
+			return nil, nil, err
+		}
+		if r.Day, err = strconv.Atoi(cells[3]); err != nil {
+			log.Println("failed to parse day of recurring expense", err.Error(), row)
+			return nil, nil, err
+		}
+		if r.Start, err = time.Parse(dateLayout, cells[5]); err != nil {
+			log.Println("failed to parse start of recurring expense", err.Error(), row)
+			return nil, nil, err
+		}
+		if len(cells[6]) > 0 {
+			if r.End, err = time.Parse(dateLayout, cells[6]); err != nil {
+				log.Println("failed to parse end of recurring expense", err.Error(), row)
+				return nil, nil, err
+			}
+		}
+		if len(cells[8]) > 0 {
+			if r.ChatId, err = strconv.ParseInt(cells[8], 10, 64); err != nil {
+				log.Println("failed to parse chat of recurring expense", err.Error(), row)
+				return nil, nil, err
+			}
+		}
+		if len(cells[9]) > 0 {
+			if r.LastMonth, err = time.Parse(dateLayout, cells[9]); err != nil {
+				log.Println("failed to parse last month of recurring expense", err.Error(), row)
+				return nil, nil, err
+			}
+		}
+
+		expenses = append(expenses, r)
+	}
+
+	return expenses, dues, nil
+}
+
+// parsePaymentDue reads a row of the recurring expenses sheet in the remind mode
+func parsePaymentDue(cells []string, recordType models.RecordType) (*models.PaymentDue, error) {
+	var err error
+	p := &models.PaymentDue{Apartment: models.Apartment{Name: cells[0]}, Type: recordType}
+	if p.Day, err = strconv.Atoi(cells[3]); err != nil {
+		return nil, err
+	}
+	if p.Start, err = time.Parse(dateLayout, cells[5]); err != nil {
+		return nil, err
+	}
+	if p.ChatId, err = strconv.ParseInt(cells[8], 10, 64); err != nil {
+		return nil, err
+	}
+	if len(cells[9]) > 0 {
+		if p.LastMonth, err = time.Parse(dateLayout, cells[9]); err != nil {
+			return nil, err
+		}
+	}
+	if len(cells[10]) > 0 {
+		if p.RemindedMonth, err = time.Parse(dateLayout, cells[10]); err != nil {
+			return nil, err
+		}
+	}
+	if len(cells[11]) > 0 {
+		// the snooze is compared to the clock of the bot
+		if p.SnoozedUntil, err = time.ParseInLocation(dateTimeLayout, cells[11], time.Local); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (s *SheetsClient) writeRecurringExpensesSheet(expenses []*models.RecurringExpense, dues []*models.PaymentDue) error {
+	var dataToWrite [][]interface{}
+	for _, r := range expenses {
+		mode := automaticMode
+		if r.Confirm {
+			mode = confirmMode
+		}
+		dataToWrite = append(dataToWrite, []interface{}{
+			r.Apartment.Name, string(r.Type), r.Value, r.Day, r.Payer, r.Start.Format(dateLayout), optionalDate(r.End), mode,
+			textCell(strconv.FormatInt(r.ChatId, 10)), optionalDate(r.LastMonth), "", "",
+		})
+	}
+	for _, p := range dues {
+		snoozedUntil := ""
+		if !p.SnoozedUntil.IsZero() {
+			snoozedUntil = p.SnoozedUntil.Format(dateTimeLayout)
+		}
+		dataToWrite = append(dataToWrite, []interface{}{
+			p.Apartment.Name, string(p.Type), "", p.Day, "", p.Start.Format(dateLayout), "", remindMode,
+			textCell(strconv.FormatInt(p.ChatId, 10)), optionalDate(p.LastMonth), optionalDate(p.RemindedMonth), snoozedUntil,
+		})
+	}
+	sort.Slice(dataToWrite, func(i, j int) bool {
+		if dataToWrite[i][0] != dataToWrite[j][0] {
+			return dataToWrite[i][0].(string) < dataToWrite[j][0].(string)
+		}
+		return dataToWrite[i][1].(string) < dataToWrite[j][1].(string)
+	})
+
+	if err := s.ensureSheet(recurringExpensesSheet, recurringExpensesHeaders); err != nil {
+		return err
+	}
+
+	return s.replaceDataInSheetRange(recurringExpensesSheet, readRecurringExpensesCells, recurringExpensesCell, dataToWrite)
+}
+
+// optionalDate formats the date, leaving the cell empty when it is zero
+func optionalDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format(dateLayout)
+}
+
+// optionalValue leaves the cell empty when the value is zero
+func optionalValue(value float64) interface{} {
+	if value == 0 {
+		return ""
+	}
+	return value
+}
+
+// removeRecurringExpense drops the recurring expense of the same type in the same apartment
+func removeRecurringExpense(expenses []*models.RecurringExpense, r *models.RecurringExpense) []*models.RecurringExpense {
+	var kept []*models.RecurringExpense
+	for _, e := range expenses {
+		if e.Apartment.Name != r.Apartment.Name || e.Type != r.Type {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// SetPaymentDue replaces the payment due of the same type in the apartment, payments registered by a recurring
+// expense are due on its day
+func (s *SheetsClient) SetPaymentDue(p *models.PaymentDue) error {
+	expenses, dues, err := s.readRecurringExpensesSheet()
+	if err != nil {
+		return err
+	}
+
+	for _, e := range expenses {
+		if e.Apartment.Name == p.Apartment.Name && e.Type == p.Type {
+			return errors.ErrPaymentDueHasRecurringExpense
+		}
+	}
+
+	return s.writeRecurringExpensesSheet(expenses, append(removePaymentDue(dues, p), p))
+}
+
+func (s *SheetsClient) RemovePaymentDue(p *models.PaymentDue) error {
+	expenses, dues, err := s.readRecurringExpensesSheet()
+	if err != nil {
+		return err
+	}
+
+	return s.writeRecurringExpensesSheet(expenses, removePaymentDue(dues, p))
+}
+
+func (s *SheetsClient) GetPaymentDues() ([]*models.PaymentDue, error) {
+	_, dues, err := s.readRecurringExpensesSheet()
+	return dues, err
+}
+
+// removePaymentDue drops the payment due of the same type in the same apartment
+func removePaymentDue(dues []*models.PaymentDue, p *models.PaymentDue) []*models.PaymentDue {
+	var kept []*models.PaymentDue
+	for _, d := range dues {
+		if d.Apartment.Name != p.Apartment.Name || d.Type != p.Type {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+func (s *SheetsClient) SetBudget(b *models.Budget) error {
+	budgets, err := s.GetBudgets()
+	if err != nil {
+		return err
+	}
+
+	return s.writeBudgets(append(removeBudget(budgets, b), b))
+}
+
+func (s *SheetsClient) RemoveBudget(b *models.Budget) error {
+	budgets, err := s.GetBudgets()
+	if err != nil {
+		return err
+	}
+
+	return s.writeBudgets(removeBudget(budgets, b))
+}
+
+func (s *SheetsClient) GetBudgets() ([]*models.Budget, error) {
+	budgetsData, err := s.readDataFromOptionalSheet(budgetsSheet, readBudgetsCells)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets := make([]*models.Budget, 0)
+	for _, row := range budgetsData {
+		if len(row) < 4 {
+			log.Println("ignoring incomplete budget", row)
+			continue
+		}
+
+		recordType, ok := models.ParseRecordType(row[1].(string))
+		if !ok {
+			log.Println("ignoring budget with unknown type", row)
+			continue
+		}
+
+		value, err := format.BrlToFloat64(row[2].(string))
+		if err != nil {
+			log.Println("failed to parse value of budget", err.Error(), row)
+			return nil, err
+		}
+
+		chatId, err := strconv.ParseInt(row[3].(string), 10, 64)
+		if err != nil {
+			log.Println("failed to parse chat of budget", err.Error(), row)
+			return nil, err
+		}
+
+		budgets = append(budgets, &models.Budget{
+			Apartment: models.Apartment{Name: row[0].(string)},
+			Type:      recordType,
+			Value:     value,
+			ChatId:    chatId,
+		})
+	}
+
+	return budgets, nil
+}
+
+func (s *SheetsClient) writeBudgets(budgets []*models.Budget) error {
+	sort.Slice(budgets, func(i, j int) bool {
+		if budgets[i].Apartment.Name != budgets[j].Apartment.Name {
+			return budgets[i].Apartment.Name < budgets[j].Apartment.Name
+		}
+		return budgets[i].Type < budgets[j].Type
+	})
+
+	var dataToWrite [][]interface{}
+	for _, b := range budgets {
+		dataToWrite = append(dataToWrite, []interface{}{b.Apartment.Name, string(b.Type), b.Value, textCell(strconv.FormatInt(b.ChatId, 10))})
+	}
+
+	if err := s.ensureSheet(budgetsSheet, budgetsHeaders); err != nil {
+		return err
+	}
+
+	return s.replaceDataInSheetRange(budgetsSheet, readBudgetsCells, budgetsCell, dataToWrite)
+}
+
+// removeBudget drops the budget of the same type in the same apartment
+func removeBudget(budgets []*models.Budget, b *models.Budget) []*models.Budget {
+	var kept []*models.Budget
+	for _, kb := range budgets {
+		if kb.Apartment.Name != b.Apartment.Name || kb.Type != b.Type {
+			kept = append(kept, kb)
+		}
+	}
+	return kept
+}
+
+func (s *SheetsClient) AddScheduledCleaning(c *models.ScheduledCleaning) error {
+	cleanings, err := s.GetScheduledCleanings()
+	if err != nil {
+		return err
+	}
+
+	return s.writeScheduledCleanings(append(removeScheduledCleaning(cleanings, c), c))
+}
+
+func (s *SheetsClient) RemoveScheduledCleaning(c *models.ScheduledCleaning) error {
+	cleanings, err := s.GetScheduledCleanings()
+	if err != nil {
+		return err
+	}
+
+	return s.writeScheduledCleanings(removeScheduledCleaning(cleanings, c))
+}
+
+func (s *SheetsClient) GetScheduledCleanings() ([]*models.ScheduledCleaning, error) {
+	cleaningsData, err := s.readDataFromOptionalSheet(scheduledCleaningsSheet, readScheduledCleaningsCells)
+	if err != nil {
+		return nil, err
+	}
+
+	cleanings := make([]*models.ScheduledCleaning, 0)
+	for _, row := range cleaningsData {
+		if len(row) < 5 {
+			log.Println("ignoring incomplete scheduled cleaning", row)
+			continue
+		}
+
+		date, err := time.Parse(dateLayout, row[1].(string))
+		if err != nil {
+			log.Println("failed to parse date of scheduled cleaning", err.Error(), row)
+			return nil, err
+		}
+
+		cleanerId, err := strconv.ParseInt(row[2].(string), 10, 64)
+		if err != nil {
+			log.Println("failed to parse cleaner of scheduled cleaning", err.Error(), row)
+			return nil, err
+		}
+
+		cleanings = append(cleanings, &models.ScheduledCleaning{
+			Apartment:   models.Apartment{Name: row[0].(string)},
+			Date:        date,
+			CleanerId:   cleanerId,
+			CleanerName: row[3].(string),
+			Payer:       row[4].(string),
+		})
+	}
+
+	return cleanings, nil
+}
+
+func (s *SheetsClient) writeScheduledCleanings(cleanings []*models.ScheduledCleaning) error {
+	sort.Slice(cleanings, func(i, j int) bool {
+		return cleanings[i].Date.Before(cleanings[j].Date)
+	})
+
+	var dataToWrite [][]interface{}
+	for _, c := range cleanings {
+		dataToWrite = append(dataToWrite, []interface{}{
+			c.Apartment.Name, c.Date.Format(dateLayout), textCell(strconv.FormatInt(c.CleanerId, 10)), c.CleanerName, c.Payer,
+		})
+	}
+
+	if err := s.ensureSheet(scheduledCleaningsSheet, scheduledCleaningsHeaders); err != nil {
+		return err
+	}
+
+	return s.replaceDataInSheetRange(scheduledCleaningsSheet, readScheduledCleaningsCells, scheduledCleaningsCell, dataToWrite)
+}
+
+// removeScheduledCleaning drops the cleaning scheduled to the same apartment at the same date
+func removeScheduledCleaning(cleanings []*models.ScheduledCleaning, c *models.ScheduledCleaning) []*models.ScheduledCleaning {
+	var kept []*models.ScheduledCleaning
+	for _, sc := range cleanings {
+		if sc.Apartment.Name != c.Apartment.Name || !sc.Date.Equal(c.Date) {
+			kept = append(kept, sc)
+		}
+	}
+	return kept
+}
+
+func (s *SheetsClient) SetReportSubscription(r *models.ReportSubscription) error {
+	subscriptions, err := s.GetReportSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	return s.writeReportSubscriptions(append(removeReportSubscription(subscriptions, r), r))
+}
+
+func (s *SheetsClient) RemoveReportSubscription(r *models.ReportSubscription) error {
+	subscriptions, err := s.GetReportSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	return s.writeReportSubscriptions(removeReportSubscription(subscriptions, r))
+}
+
+func (s *SheetsClient) GetReportSubscriptions() ([]*models.ReportSubscription, error) {
+	subscriptionsData, err := s.readDataFromOptionalSheet(reportSubscriptionsSheet, readReportSubscriptionsCells)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions := make([]*models.ReportSubscription, 0)
+	for _, row := range subscriptionsData {
+		if len(row) < 3 {
+			log.Println("ignoring incomplete report subscription", row)
+			continue
+		}
+
+		chatId, err := strconv.ParseInt(row[0].(string), 10, 64)
+		if err != nil {
+			log.Println("failed to parse chat of report subscription", err.Error(), row)
+			return nil, err
+		}
+
+		frequency, ok := models.ParseReportFrequency(row[1].(string))
+		if !ok {
+			log.Println("ignoring report subscription with unknown frequency", row)
+			continue
+		}
+
+		lastPeriod, err := time.Parse(dateLayout, row[2].(string))
+		if err != nil {
+			log.Println("failed to parse last period of report subscription", err.Error(), row)
+			return nil, err
+		}
+
+		subscriptions = append(subscriptions, &models.ReportSubscription{
+			ChatId:     chatId,
+			Frequency:  frequency,
+			LastPeriod: lastPeriod,
+		})
+	}
+
+	return subscriptions, nil
+}
+
+func (s *SheetsClient) writeReportSubscriptions(subscriptions []*models.ReportSubscription) error {
+	var dataToWrite [][]interface{}
+	for _, r := range subscriptions {
+		dataToWrite = append(dataToWrite, []interface{}{
+			textCell(strconv.FormatInt(r.ChatId, 10)), string(r.Frequency), r.LastPeriod.Format(dateLayout),
+		})
+	}
+
+	if err := s.ensureSheet(reportSubscriptionsSheet, reportSubscriptionsHeaders); err != nil {
+		return err
 	}
 
-	existingCondos := make([]*models.Condo, 0)
-	for _, condo := range payedCondosData {
-		date, err := time.Parse(dateLayout, condo[0].(string))
+	return s.replaceDataInSheetRange(reportSubscriptionsSheet, readReportSubscriptionsCells, reportSubscriptionsCell, dataToWrite)
+}
+
+// removeReportSubscription drops the subscription of the same chat with the same frequency
+func removeReportSubscription(subscriptions []*models.ReportSubscription, r *models.ReportSubscription) []*models.ReportSubscription {
+	var kept []*models.ReportSubscription
+	for _, sub := range subscriptions {
+		if sub.ChatId != r.ChatId || sub.Frequency != r.Frequency {
+			kept = append(kept, sub)
+		}
+	}
+	return kept
+}
+
+// GetCalendarBookings returns the reservations read in the last sync of the calendar of the source for the apartment
+func (s *SheetsClient) GetCalendarBookings(apartment models.Apartment, source string) ([]*models.CalendarBooking, error) {
+	bookings, err := s.readCalendarBookings()
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*models.CalendarBooking
+	for _, b := range bookings {
+		if b.Apartment.Name == apartment.Name && b.Source == source {
+			found = append(found, b)
+		}
+	}
+	return found, nil
+}
+
+// SetCalendarBookings replaces the reservations of the last sync of the calendar of the source for the apartment
+func (s *SheetsClient) SetCalendarBookings(apartment models.Apartment, source string, synced []*models.CalendarBooking) error {
+	bookings, err := s.readCalendarBookings()
+	if err != nil {
+		return err
+	}
+
+	var kept []*models.CalendarBooking
+	for _, b := range bookings {
+		if b.Apartment.Name != apartment.Name || b.Source != source {
+			kept = append(kept, b)
+		}
+	}
+	kept = append(kept, synced...)
+
+	var dataToWrite [][]interface{}
+	for _, b := range kept {
+		dataToWrite = append(dataToWrite, []interface{}{
+			b.Apartment.Name, b.Source, b.UID, b.DateBegin.Format(dateLayout), b.DateEnd.Format(dateLayout),
+		})
+	}
+
+	if err := s.ensureSheet(calendarBookingsSheet, calendarBookingsHeaders); err != nil {
+		return err
+	}
+
+	return s.replaceDataInSheetRange(calendarBookingsSheet, readCalendarBookingsCells, calendarBookingsCell, dataToWrite)
+}
+
+func (s *SheetsClient) readCalendarBookings() ([]*models.CalendarBooking, error) {
+	bookingsData, err := s.readDataFromOptionalSheet(calendarBookingsSheet, readCalendarBookingsCells)
+	if err != nil {
+		return nil, err
+	}
+
+	bookings := make([]*models.CalendarBooking, 0)
+	for _, row := range bookingsData {
+		if len(row) < 5 {
+			log.Println("ignoring incomplete calendar booking", row)
+			continue
+		}
+
+		dateBegin, err := time.Parse(dateLayout, row[3].(string))
 		if err != nil {
-			log.Println("failed to parse date of condo bill", err.Error(), condo)
+			log.Println("failed to parse check-in of calendar booking", err.Error(), row)
 			return nil, err
 		}
 
-		value, err := format.BrlToFloat64(condo[1].(string))
+		dateEnd, err := time.Parse(dateLayout, row[4].(string))
 		if err != nil {
-			log.Println("failed to parse value of condo", err.Error(), condo)
+			log.Println("failed to parse check-out of calendar booking", err.Error(), row)
+			return nil, err
 		}
 
-		existingCondos = append(existingCondos, &models.Condo{
-			Value:     value,
-			Date:      date,
-			Payer:     condo[2].(string),
-			Apartment: apartment,
+		bookings = append(bookings, &models.CalendarBooking{
+			Apartment: models.Apartment{Name: row[0].(string)},
+			Source:    row[1].(string),
+			UID:       row[2].(string),
+			DateBegin: dateBegin,
+			DateEnd:   dateEnd,
 		})
 	}
 
-	return existingCondos, nil
+	return bookings, nil
 }
 
-func (s *SheetsClient) GetPayedBills(apartment models.Apartment) ([]*models.EnergyBill, error) {
-	payedBillsData, err := s.readDataFromRange(apartment, readBillCells)
+func (s *SheetsClient) SetFinancingContract(f *models.FinancingContract) error {
+	contracts, err := s.GetFinancingContracts()
+	if err != nil {
+		return err
+	}
+
+	return s.writeFinancingContracts(append(removeFinancingContract(contracts, f), f))
+}
+
+func (s *SheetsClient) RemoveFinancingContract(f *models.FinancingContract) error {
+	contracts, err := s.GetFinancingContracts()
+	if err != nil {
+		return err
+	}
+
+	return s.writeFinancingContracts(removeFinancingContract(contracts, f))
+}
+
+func (s *SheetsClient) GetFinancingContracts() ([]*models.FinancingContract, error) {
+	contractsData, err := s.readDataFromOptionalSheet(financingContractsSheet, readFinancingContractsCells)
 	if err != nil {
 		return nil, err
 	}
 
-	existingBills := make([]*models.EnergyBill, 0)
-	for _, bill := range payedBillsData {
-		date, err := time.Parse(dateLayout, bill[0].(string))
+	contracts := make([]*models.FinancingContract, 0)
+	for _, row := range contractsData {
+		// trailing empty cells are not returned by the API
+		cells := make([]string, len(financingContractsHeaders))
+		for i := range cells {
+			if i < len(row) {
+				cells[i] = row[i].(string)
+			}
+		}
+
+		system, ok := models.ParseAmortizationSystem(cells[4])
+		if len(cells[0]) == 0 || !ok {
+			log.Println("ignoring invalid financing contract", row)
+			continue
+		}
+		index, ok := models.ParseCorrectionIndex(cells[6])
+		if !ok {
+			log.Println("ignoring financing contract with unknown index", row)
+			continue
+		}
+
+		f := &models.FinancingContract{
+			Apartment: models.Apartment{Name: cells[0]},
+			System:    system,
+			Index:     index,
+		}
+		if f.Principal, err = format.BrlToFloat64(cells[1]); err != nil {
+			log.Println("failed to parse principal of financing contract", err.Error(), row)
+			return nil, err
+		}
+		if f.AnnualRate, err = format.BrlToFloat64(cells[2]); err != nil {
+			log.Println("failed to parse rate of financing contract", err.Error(), row)
+			return nil, err
+		}
+		if f.Term, err = strconv.Atoi(cells[3]); err != nil {
+			log.Println("failed to parse term of financing contract", err.Error(), row)
+			return nil, err
+		}
+		if f.Start, err = time.Parse(dateLayout, cells[5]); err != nil {
+			log.Println("failed to parse start of financing contract", err.Error(), row)
+			return nil, err
+		}
+
+		contracts = append(contracts, f)
+	}
+
+	return contracts, nil
+}
+
+func (s *SheetsClient) writeFinancingContracts(contracts []*models.FinancingContract) error {
+	sort.Slice(contracts, func(i, j int) bool {
+		return contracts[i].Apartment.Name < contracts[j].Apartment.Name
+	})
+
+	var dataToWrite [][]interface{}
+	for _, f := range contracts {
+		dataToWrite = append(dataToWrite, []interface{}{
+			f.Apartment.Name, f.Principal, f.AnnualRate, f.Term, string(f.System), f.Start.Format(dateLayout), string(f.Index),
+		})
+	}
+
+	if err := s.ensureSheet(financingContractsSheet, financingContractsHeaders); err != nil {
+		return err
+	}
+
+	return s.replaceDataInSheetRange(financingContractsSheet, readFinancingContractsCells, financingContractsCell, dataToWrite)
+}
+
+// removeFinancingContract drops the financing of the same apartment
+func removeFinancingContract(contracts []*models.FinancingContract, f *models.FinancingContract) []*models.FinancingContract {
+	var kept []*models.FinancingContract
+	for _, c := range contracts {
+		if c.Apartment.Name != f.Apartment.Name {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+func (s *SheetsClient) AddIndexValues(values []*models.IndexValue) error {
+	existing, err := s.readIndexValues()
+	if err != nil {
+		return err
+	}
+
+	type key struct {
+		index models.CorrectionIndex
+		month time.Time
+	}
+	replaced := make(map[key]bool)
+	for _, v := range values {
+		replaced[key{v.Index, v.Month}] = true
+	}
+	var kept []*models.IndexValue
+	for _, v := range existing {
+		if !replaced[key{v.Index, v.Month}] {
+			kept = append(kept, v)
+		}
+	}
+	kept = append(kept, values...)
+	sort.Slice(kept, func(i, j int) bool {
+		if kept[i].Index != kept[j].Index {
+			return kept[i].Index < kept[j].Index
+		}
+		return kept[i].Month.Before(kept[j].Month)
+	})
+
+	var dataToWrite [][]interface{}
+	for _, v := range kept {
+		dataToWrite = append(dataToWrite, []interface{}{string(v.Index), v.Month.Format(dateLayout), v.Variation})
+	}
+
+	if err := s.ensureSheet(indexValuesSheet, indexValuesHeaders); err != nil {
+		return err
+	}
+
+	return s.replaceDataInSheetRange(indexValuesSheet, readIndexValuesCells, indexValuesCell, dataToWrite)
+}
+
+func (s *SheetsClient) GetIndexValues(index models.CorrectionIndex) ([]*models.IndexValue, error) {
+	values, err := s.readIndexValues()
+	if err != nil {
+		return nil, err
+	}
+
+	var indexValues []*models.IndexValue
+	for _, v := range values {
+		if v.Index == index {
+			indexValues = append(indexValues, v)
+		}
+	}
+	return indexValues, nil
+}
+
+func (s *SheetsClient) readIndexValues() ([]*models.IndexValue, error) {
+	valuesData, err := s.readDataFromOptionalSheet(indexValuesSheet, readIndexValuesCells)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]*models.IndexValue, 0)
+	for _, row := range valuesData {
+		if len(row) < 3 {
+			log.Println("ignoring incomplete index value", row)
+			continue
+		}
+
+		index, ok := models.ParseCorrectionIndex(row[0].(string))
+		if !ok || index == models.IndexNone {
+			log.Println("ignoring value of unknown index", row)
+			continue
+		}
+
+		month, err := time.Parse(dateLayout, row[1].(string))
 		if err != nil {
-			log.Println("failed to parse date of bill", err.Error(), bill)
+			log.Println("failed to parse month of index value", err.Error(), row)
 			return nil, err
 		}
 
-		value, err := format.BrlToFloat64(bill[1].(string))
+		variation, err := format.BrlToFloat64(row[2].(string))
 		if err != nil {
-			log.Println("failed to parse value of bill", err.Error(), bill)
+			log.Println("failed to parse variation of index value", err.Error(), row)
+			return nil, err
 		}
 
-		existingBills = append(existingBills, &models.EnergyBill{
-			Value:     value,
-			Date:      date,
-			Payer:     bill[2].(string),
-			Apartment: apartment,
-		})
+		values = append(values, &models.IndexValue{Index: index, Month: month, Variation: variation})
 	}
 
-	return existingBills, nil
+	return values, nil
 }
 
-func (s *SheetsClient) GetPayedCleanings(apartment models.Apartment) ([]*models.Cleaning, error) {
-	payedCleaningsData, err := s.readDataFromRange(apartment, readCleaningCells)
+// GetUserPreference returns the preference of the user, empty if they have none yet
+func (s *SheetsClient) GetUserPreference(userId int64) (*models.UserPreference, error) {
+	preferences, err := s.readUserPreferences()
 	if err != nil {
 		return nil, err
 	}
 
-	existingCleanings := make([]*models.Cleaning, 0)
-	for _, cleaning := range payedCleaningsData {
-		date, err := time.Parse(dateLayout, cleaning[0].(string))
+	for _, p := range preferences {
+		if p.UserId == userId {
+			return p, nil
+		}
+	}
+	return &models.UserPreference{UserId: userId}, nil
+}
+
+// SetUserPreference replaces the preference of the user
+func (s *SheetsClient) SetUserPreference(p *models.UserPreference) error {
+	preferences, err := s.readUserPreferences()
+	if err != nil {
+		return err
+	}
+
+	dataToWrite := [][]interface{}{{textCell(strconv.FormatInt(p.UserId, 10)), p.Apartment}}
+	for _, other := range preferences {
+		if other.UserId != p.UserId {
+			dataToWrite = append(dataToWrite, []interface{}{textCell(strconv.FormatInt(other.UserId, 10)), other.Apartment})
+		}
+	}
+
+	if err := s.ensureSheet(userPreferencesSheet, userPreferencesHeaders); err != nil {
+		return err
+	}
+
+	return s.replaceDataInSheetRange(userPreferencesSheet, readUserPreferencesCells, userPreferencesCell, dataToWrite)
+}
+
+func (s *SheetsClient) readUserPreferences() ([]*models.UserPreference, error) {
+	preferencesData, err := s.readDataFromOptionalSheet(userPreferencesSheet, readUserPreferencesCells)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := make([]*models.UserPreference, 0)
+	for _, row := range preferencesData {
+		if len(row) < 2 {
+			continue
+		}
+
+		userId, err := strconv.ParseInt(row[0].(string), 10, 64)
 		if err != nil {
-			log.Println("failed to parse date of cleaning", err.Error(), cleaning)
+			log.Println("failed to parse user of preference", err.Error(), row)
 			return nil, err
 		}
 
-		value, err := format.BrlToFloat64(cleaning[1].(string))
+		preferences = append(preferences, &models.UserPreference{UserId: userId, Apartment: row[1].(string)})
+	}
+
+	return preferences, nil
+}
+
+// GetRecordDefault returns the default of the user for the kind of record in the apartment, nil if there is none
+func (s *SheetsClient) GetRecordDefault(userId int64, apartment string, recordType models.RecordType) (*models.RecordDefault, error) {
+	defaults, err := s.readRecordDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range defaults {
+		if d.UserId == userId && d.Apartment == apartment && d.RecordType == recordType {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+// SetRecordDefault replaces the default of the user for the kind of record in the apartment
+func (s *SheetsClient) SetRecordDefault(d *models.RecordDefault) error {
+	defaults, err := s.readRecordDefaults()
+	if err != nil {
+		return err
+	}
+
+	dataToWrite := [][]interface{}{recordDefaultRow(d)}
+	for _, other := range defaults {
+		if other.UserId != d.UserId || other.Apartment != d.Apartment || other.RecordType != d.RecordType {
+			dataToWrite = append(dataToWrite, recordDefaultRow(other))
+		}
+	}
+
+	if err := s.ensureSheet(recordDefaultsSheet, recordDefaultsHeaders); err != nil {
+		return err
+	}
+
+	return s.replaceDataInSheetRange(recordDefaultsSheet, readRecordDefaultsCells, recordDefaultsCell, dataToWrite)
+}
+
+func recordDefaultRow(d *models.RecordDefault) []interface{} {
+	return []interface{}{textCell(strconv.FormatInt(d.UserId, 10)), d.Apartment, string(d.RecordType), d.Value, d.Payer}
+}
+
+func (s *SheetsClient) readRecordDefaults() ([]*models.RecordDefault, error) {
+	defaultsData, err := s.readDataFromOptionalSheet(recordDefaultsSheet, readRecordDefaultsCells)
+	if err != nil {
+		return nil, err
+	}
+
+	defaults := make([]*models.RecordDefault, 0)
+	for _, row := range defaultsData {
+		if len(row) < 5 {
+			log.Println("ignoring incomplete record default", row)
+			continue
+		}
+
+		userId, err := strconv.ParseInt(row[0].(string), 10, 64)
 		if err != nil {
-			log.Println("failed to parse value of cleaning", err.Error(), cleaning)
+			log.Println("failed to parse user of record default", err.Error(), row)
+			return nil, err
 		}
 
-		existingCleanings = append(existingCleanings, &models.Cleaning{
-			Value:     value,
-			Date:      date,
-			Payer:     cleaning[2].(string),
-			Apartment: apartment,
+		recordType, ok := models.ParseRecordType(row[2].(string))
+		if !ok {
+			log.Println("ignoring record default with unknown type", row)
+			continue
+		}
+
+		value, err := format.BrlToFloat64(row[3].(string))
+		if err != nil {
+			log.Println("failed to parse value of record default", err.Error(), row)
+			return nil, err
+		}
+
+		defaults = append(defaults, &models.RecordDefault{
+			UserId:     userId,
+			Apartment:  row[1].(string),
+			RecordType: recordType,
+			Value:      value,
+			Payer:      row[4].(string),
 		})
 	}
 
-	return existingCleanings, nil
+	return defaults, nil
 }
 
-// GetAvailableApartments query the existing sheets and return its titles in an array
-func (s *SheetsClient) GetAvailableApartments() ([]string, error) {
-	sheetData, err := s.Spreadsheets.Get(s.sheetsId).Do()
+// GetJobRun returns when the job last did its work, with a zero time if it never did
+func (s *SheetsClient) GetJobRun(job string) (*models.JobRun, error) {
+	runs, err := s.readJobRuns()
 	if err != nil {
 		return nil, err
 	}
 
-	var apartmentNames []string
-	for _, sheet := range sheetData.Sheets {
-		if !strings.HasPrefix(sheet.Properties.Title, "[") {
-			apartmentNames = append(apartmentNames, sheet.Properties.Title)
+	for _, r := range runs {
+		if r.Job == job {
+			return r, nil
 		}
 	}
-
-	return apartmentNames, nil
+	return &models.JobRun{Job: job}, nil
 }
 
-func (s *SheetsClient) upsertDataInRange(apartment models.Apartment, upsertRange string, data [][]interface{}) error {
-	a1Range := fmt.Sprintf("%s!%s", apartment.Name, upsertRange)
-	resp, err := s.Spreadsheets.Values.Update(s.sheetsId, a1Range, &sheets.ValueRange{
-		Range:  a1Range,
-		Values: data,
-	}).ValueInputOption("USER_ENTERED").Do()
+// SetJobRun replaces the last run of the job
+func (s *SheetsClient) SetJobRun(r *models.JobRun) error {
+	runs, err := s.readJobRuns()
 	if err != nil {
 		return err
 	}
 
-	log.Printf("values added to range %s", resp.UpdatedRange)
-	return nil
+	dataToWrite := [][]interface{}{{r.Job, r.LastRun.Format(dateTimeLayout)}}
+	for _, other := range runs {
+		if other.Job != r.Job {
+			dataToWrite = append(dataToWrite, []interface{}{other.Job, other.LastRun.Format(dateTimeLayout)})
+		}
+	}
+
+	if err := s.ensureSheet(jobRunsSheet, jobRunsHeaders); err != nil {
+		return err
+	}
+
+	return s.replaceDataInSheetRange(jobRunsSheet, readJobRunsCells, jobRunsCell, dataToWrite)
 }
 
-func (s *SheetsClient) readDataFromRange(apartment models.Apartment, readRange string) ([][]interface{}, error) {
-	a1Range := fmt.Sprintf("%s!%s", apartment.Name, readRange)
-	cells, err := s.Spreadsheets.Values.Get(s.sheetsId, a1Range).ValueRenderOption("FORMATTED_VALUE").Do()
+func (s *SheetsClient) readJobRuns() ([]*models.JobRun, error) {
+	runsData, err := s.readDataFromOptionalSheet(jobRunsSheet, readJobRunsCells)
 	if err != nil {
 		return nil, err
 	}
 
-	return cells.Values, nil
+	runs := make([]*models.JobRun, 0)
+	for _, row := range runsData {
+		if len(row) < 2 {
+			continue
+		}
+
+		// the runs are compared to the clock of the bot
+		lastRun, err := time.ParseInLocation(dateTimeLayout, row[1].(string), time.Local)
+		if err != nil {
+			log.Println("failed to parse last run of job", err.Error(), row)
+			return nil, err
+		}
+
+		runs = append(runs, &models.JobRun{Job: row[0].(string), LastRun: lastRun})
+	}
+
+	return runs, nil
 }
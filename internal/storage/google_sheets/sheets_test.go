@@ -0,0 +1,68 @@
+package google_sheets
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestColumnName(t *testing.T) {
+	for _, cell := range []string{"A3", "F3", "Z3", "AA3", "AD1", "AZ1", "BA1"} {
+		index := columnIndex(cell)
+		if got := columnName(index); got+cell[len(got):] != cell {
+			t.Errorf("columnName(%d) = %q, want the column of %q", index, got, cell)
+		}
+	}
+}
+
+func TestMissingColumns(t *testing.T) {
+	var current []string
+	for _, table := range apartmentTables {
+		for len(current) < columnIndex(table.firstCell) {
+			current = append(current, "")
+		}
+		current = append(current, table.headers...)
+	}
+
+	// the original layout of the apartment sheets, before the expense tables had a receipt column
+	original := []string{
+		"Entrada", "Saída", "Valor", "Inquilino", "Recebedor",
+		"Data", "Valor", "Pagador",
+		"Data", "Valor", "Pagador",
+		"Data", "Valor", "Pagador",
+		"Data", "Valor", "Descriçao", "Pagador",
+		"Data", "Valor", "Pagador",
+		"Data", "Valor", "Pagador",
+	}
+	originalMissing := []missingColumn{{5, bookedHeader}, {6, cleaningFeeHeader}, {7, receivedHeader}, {11, receiptHeader},
+		{15, receiptHeader}, {19, receiptHeader}, {24, categoryHeader}, {25, receiptHeader}, {29, optionHeader},
+		{30, receiptHeader}, {34, receiptHeader}}
+
+	// the layout of the apartment sheets before the rents had booking columns, the expenses a category and the
+	// amortizations an option column
+	receipts := []string{
+		"Entrada", "Saída", "Valor", "Inquilino", "Recebedor",
+		"Data", "Valor", "Pagador", receiptHeader,
+		"Data", "Valor", "Pagador", receiptHeader,
+		"Data", "Valor", "Pagador", receiptHeader,
+		"Data", "Valor", "Descriçao", "Pagador", receiptHeader,
+		"Data", "Valor", "Pagador", receiptHeader,
+		"Data", "Valor", "Pagador", receiptHeader,
+	}
+
+	tests := []struct {
+		name    string
+		headers []string
+		want    []missingColumn
+	}{
+		{"current layout", current, nil},
+		{"original layout", original, originalMissing},
+		{"receipts layout", receipts, []missingColumn{{5, bookedHeader}, {6, cleaningFeeHeader}, {7, receivedHeader},
+			{24, categoryHeader}, {29, optionHeader}}},
+		{"no headers", nil, originalMissing},
+	}
+	for _, tt := range tests {
+		if got := missingColumns(tt.headers); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: missingColumns = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package s3_client
 
 import (
+	"bytes"
 	"encoding/json"
 	"log"
 	"os"
@@ -17,6 +18,7 @@ import (
 type S3Client struct {
 	*session.Session
 	*s3manager.Downloader
+	uploader *s3manager.Uploader
 }
 
 var client *S3Client
@@ -39,6 +41,7 @@ func initS3Client() *S3Client {
 	return &S3Client{
 		Session:    sess,
 		Downloader: downloader,
+		uploader:   s3manager.NewUploader(sess),
 	}
 }
 
@@ -94,3 +97,34 @@ func (c *S3Client) GetGoogleSheetsAuthToken() *oauth2.Token {
 
 	return tok
 }
+
+// UploadItem writes the data to the item of the bot bucket
+func (c *S3Client) UploadItem(item string, data []byte) error {
+	_, err := c.uploader.Upload(&s3manager.UploadInput{
+		Bucket: aws.String(config.S3Bucket),
+		Key:    aws.String(item),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Println("Uploaded", item, len(data), "bytes")
+	return nil
+}
+
+// DownloadItem reads an item of the bot bucket, whatever its size
+func (c *S3Client) DownloadItem(item string) ([]byte, error) {
+	buf := aws.NewWriteAtBuffer([]byte{})
+	numBytes, err := c.Download(buf,
+		&s3.GetObjectInput{
+			Bucket: aws.String(config.S3Bucket),
+			Key:    aws.String(item),
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("Downloaded", item, numBytes, "bytes")
+	return buf.Bytes(), nil
+}
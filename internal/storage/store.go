@@ -2,8 +2,12 @@ package storage
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"strings"
+	"time"
 
+	"github.com/gustavolopess/hoteleiro/internal/format"
 	"github.com/gustavolopess/hoteleiro/internal/models"
 	"github.com/gustavolopess/hoteleiro/internal/storage/errors"
 	"github.com/gustavolopess/hoteleiro/internal/storage/google_sheets"
@@ -18,7 +22,33 @@ type Store interface {
 	AddMiscellaneousExpense(e *models.MiscellaneousExpense) error
 	AddAmortization(a *models.Amortization) error
 	AddFinancingInstallment(f *models.FinancingInstallment) error
+	AddBatch(b *models.Batch) error
+	AddAttachment(a *models.Attachment) error
+	UpdateApartment(a *models.Apartment) error
+	RenameApartment(oldName, newName string) error
+	AddUser(u *models.User) error
+	RemoveUser(id int64) error
+	AddAllowedChat(c *models.AllowedChat) error
+	RemoveAllowedChat(id int64) error
+	AddRecurringExpense(r *models.RecurringExpense) error
+	RemoveRecurringExpense(r *models.RecurringExpense) error
+	SetPaymentDue(p *models.PaymentDue) error
+	RemovePaymentDue(p *models.PaymentDue) error
+	AddScheduledCleaning(c *models.ScheduledCleaning) error
+	RemoveScheduledCleaning(c *models.ScheduledCleaning) error
+	SetReportSubscription(r *models.ReportSubscription) error
+	RemoveReportSubscription(r *models.ReportSubscription) error
+	SetFinancingContract(f *models.FinancingContract) error
+	RemoveFinancingContract(f *models.FinancingContract) error
+	AddIndexValues(values []*models.IndexValue) error
+	SetBudget(b *models.Budget) error
+	SetCalendarBookings(apartment models.Apartment, source string, bookings []*models.CalendarBooking) error
+	RemoveBudget(b *models.Budget) error
+	SetUserPreference(p *models.UserPreference) error
+	SetRecordDefault(d *models.RecordDefault) error
+	SetJobRun(r *models.JobRun) error
 	GetAvailableApartments() ([]string, error)
+	GetApartments() ([]*models.Apartment, error)
 	GetExistingRents(apartment models.Apartment) ([]*models.Rent, error)
 	GetPayedCondos(apartment models.Apartment) ([]*models.Condo, error)
 	GetPayedBills(apartment models.Apartment) ([]*models.EnergyBill, error)
@@ -26,16 +56,35 @@ type Store interface {
 	GetMiscellaneousExpenses(apartment models.Apartment) ([]*models.MiscellaneousExpense, error)
 	GetPayedFinancialInstallments(apartment models.Apartment) ([]*models.FinancingInstallment, error)
 	GetPayedAmortizations(apartment models.Apartment) ([]*models.Amortization, error)
+	GetStatementRules() ([]*models.StatementRule, error)
+	GetAttachments(apartment models.Apartment) ([]*models.Attachment, error)
+	GetUsers() ([]*models.User, error)
+	GetAllowedChats() ([]*models.AllowedChat, error)
+	GetRecurringExpenses() ([]*models.RecurringExpense, error)
+	GetPaymentDues() ([]*models.PaymentDue, error)
+	GetScheduledCleanings() ([]*models.ScheduledCleaning, error)
+	GetReportSubscriptions() ([]*models.ReportSubscription, error)
+	GetFinancingContracts() ([]*models.FinancingContract, error)
+	GetIndexValues(index models.CorrectionIndex) ([]*models.IndexValue, error)
+	GetBudgets() ([]*models.Budget, error)
+	GetCalendarBookings(apartment models.Apartment, source string) ([]*models.CalendarBooking, error)
+	GetUserPreference(userId int64) (*models.UserPreference, error)
+	GetRecordDefault(userId int64, apartment string, recordType models.RecordType) (*models.RecordDefault, error)
+	GetJobRun(job string) (*models.JobRun, error)
 }
 
 type store struct {
 	client Store
+	alerts BudgetAlerts
 }
 
-func NewGoogleSheetsStore(sheetId string, credentialsJson []byte) Store {
+// NewGoogleSheetsStore stores the records in the spreadsheet, the expenses which cross a threshold of their budget
+// being told to the alerts, which may be nil
+func NewGoogleSheetsStore(sheetId string, credentialsJson []byte, alerts BudgetAlerts) Store {
 	sheetsClient := google_sheets.NewSheetsClient(context.Background(), sheetId, credentialsJson)
 	return &store{
 		client: sheetsClient,
+		alerts: alerts,
 	}
 }
 
@@ -49,7 +98,12 @@ func (s *store) AddCleaning(c *models.Cleaning) error {
 		return errors.ErrCleaningAlreadyHappened
 	}
 
-	return s.client.AddCleaning(c)
+	if err := s.client.AddCleaning(c); err != nil {
+		return err
+	}
+
+	s.checkBudget(c.Apartment, models.RecordCleaning, c.Date, c.Value)
+	return nil
 }
 
 func (s *store) AddCondo(c *models.Condo) error {
@@ -62,13 +116,91 @@ func (s *store) AddCondo(c *models.Condo) error {
 		return errors.ErrCondoAlreadyPayed
 	}
 
-	return s.client.AddCondo(c)
+	if err := s.client.AddCondo(c); err != nil {
+		return err
+	}
+
+	s.checkBudget(c.Apartment, models.RecordCondo, c.Date, c.Value)
+	return nil
 }
 
 func (s *store) AddApartment(a *models.Apartment) error {
+	if !isApartmentNameValid(a.Name) {
+		return errors.ErrApartmentInvalidName
+	}
+
+	if err := s.checkApartmentNameIsFree(a.Name); err != nil {
+		return err
+	}
+
 	return s.client.AddApartment(a)
 }
 
+// UpdateApartment saves the registry data of an existing apartment
+func (s *store) UpdateApartment(a *models.Apartment) error {
+	if _, err := s.getApartment(a.Name); err != nil {
+		return err
+	}
+
+	return s.client.UpdateApartment(a)
+}
+
+// RenameApartment renames the apartment sheet and every reference to it
+func (s *store) RenameApartment(oldName, newName string) error {
+	if !isApartmentNameValid(newName) {
+		return errors.ErrApartmentInvalidName
+	}
+
+	apartments, err := s.GetApartments()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, a := range apartments {
+		if a.Name == oldName {
+			found = true
+		} else if strings.EqualFold(a.Name, newName) {
+			return errors.ErrApartmentAlreadyExists
+		}
+	}
+	if !found {
+		return errors.ErrApartmentNotFound
+	}
+
+	return s.client.RenameApartment(oldName, newName)
+}
+
+func (s *store) checkApartmentNameIsFree(name string) error {
+	existingApartments, err := s.GetAvailableApartments()
+	if err != nil {
+		return err
+	}
+
+	for _, apt := range existingApartments {
+		if strings.EqualFold(apt, name) {
+			return errors.ErrApartmentAlreadyExists
+		}
+	}
+
+	return nil
+}
+
+func (s *store) getApartment(name string) (*models.Apartment, error) {
+	apartments, err := s.GetApartments()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range apartments {
+		if a.Name == name {
+			return a, nil
+		}
+	}
+
+	return nil, errors.ErrApartmentNotFound
+}
+
 func (s *store) AddBill(e *models.EnergyBill) error {
 	payedBills, err := s.GetPayedBills(e.Apartment)
 	if err != nil {
@@ -79,7 +211,12 @@ func (s *store) AddBill(e *models.EnergyBill) error {
 		return errors.ErrBillAlreadyPayed
 	}
 
-	return s.client.AddBill(e)
+	if err := s.client.AddBill(e); err != nil {
+		return err
+	}
+
+	s.checkBudget(e.Apartment, models.RecordEnergyBill, e.Date, e.Value)
+	return nil
 }
 
 func (s *store) AddRent(r *models.Rent) error {
@@ -100,7 +237,12 @@ func (s *store) AddRent(r *models.Rent) error {
 }
 
 func (s *store) AddMiscellaneousExpense(m *models.MiscellaneousExpense) error {
-	return s.client.AddMiscellaneousExpense(m)
+	if err := s.client.AddMiscellaneousExpense(m); err != nil {
+		return err
+	}
+
+	s.checkBudget(m.Apartment, models.RecordMiscellaneousExpense, m.Date, m.Value)
+	return nil
 }
 
 func (s *store) AddAmortization(a *models.Amortization) error {
@@ -111,10 +253,49 @@ func (s *store) AddFinancingInstallment(f *models.FinancingInstallment) error {
 	return s.client.AddFinancingInstallment(f)
 }
 
+// AddBatch validates every record of the batch as the single Add methods do, and writes them only if all are valid
+func (s *store) AddBatch(b *models.Batch) error {
+	existingRents := make(map[string][]*models.Rent)
+	for _, r := range b.Rents {
+		if _, ok := existingRents[r.Apartment.Name]; !ok {
+			rents, err := s.GetExistingRents(r.Apartment)
+			if err != nil {
+				return err
+			}
+			existingRents[r.Apartment.Name] = rents
+		}
+
+		if !isRentDatesAvailable(r, existingRents[r.Apartment.Name]) {
+			return fmt.Errorf("%w: %v", errors.ErrRentDatesUsed, r.ToString())
+		}
+
+		if r.DateBegin.After(r.DateEnd) || r.DateBegin.Equal(r.DateEnd) {
+			return fmt.Errorf("%w: %v", errors.ErrRentReversedDates, r.ToString())
+		}
+
+		existingRents[r.Apartment.Name] = append(existingRents[r.Apartment.Name], r)
+	}
+
+	if err := s.client.AddBatch(b); err != nil {
+		return err
+	}
+
+	s.checkBatchBudgets(b)
+	return nil
+}
+
+func (s *store) AddAttachment(a *models.Attachment) error {
+	return s.client.AddAttachment(a)
+}
+
 func (s *store) GetAvailableApartments() ([]string, error) {
 	return s.client.GetAvailableApartments()
 }
 
+func (s *store) GetApartments() ([]*models.Apartment, error) {
+	return s.client.GetApartments()
+}
+
 func (s *store) GetExistingRents(apartment models.Apartment) ([]*models.Rent, error) {
 	return s.client.GetExistingRents(apartment)
 }
@@ -143,6 +324,283 @@ func (s *store) GetPayedAmortizations(apartment models.Apartment) ([]*models.Amo
 	return s.client.GetPayedAmortizations(apartment)
 }
 
+func (s *store) GetStatementRules() ([]*models.StatementRule, error) {
+	return s.client.GetStatementRules()
+}
+
+func (s *store) GetAttachments(apartment models.Apartment) ([]*models.Attachment, error) {
+	return s.client.GetAttachments(apartment)
+}
+
+func (s *store) AddUser(u *models.User) error {
+	return s.client.AddUser(u)
+}
+
+func (s *store) RemoveUser(id int64) error {
+	return s.client.RemoveUser(id)
+}
+
+func (s *store) AddAllowedChat(c *models.AllowedChat) error {
+	return s.client.AddAllowedChat(c)
+}
+
+func (s *store) RemoveAllowedChat(id int64) error {
+	return s.client.RemoveAllowedChat(id)
+}
+
+func (s *store) GetUsers() ([]*models.User, error) {
+	return s.client.GetUsers()
+}
+
+func (s *store) GetAllowedChats() ([]*models.AllowedChat, error) {
+	return s.client.GetAllowedChats()
+}
+
+// AddRecurringExpense adds the recurring expense, replacing the one of the same type in the same apartment
+func (s *store) AddRecurringExpense(r *models.RecurringExpense) error {
+	if !models.IsRecurringExpenseType(r.Type) {
+		return errors.ErrRecurringExpenseInvalidType
+	}
+	if r.Day < 1 || r.Day > 31 {
+		return errors.ErrInvalidDueDay
+	}
+	if !r.End.IsZero() && r.End.Before(r.Start) {
+		return errors.ErrRecurringExpenseReversedDates
+	}
+
+	return s.client.AddRecurringExpense(r)
+}
+
+func (s *store) RemoveRecurringExpense(r *models.RecurringExpense) error {
+	return s.client.RemoveRecurringExpense(r)
+}
+
+func (s *store) GetRecurringExpenses() ([]*models.RecurringExpense, error) {
+	return s.client.GetRecurringExpenses()
+}
+
+// SetPaymentDue sets the due day of the payments of a type in the apartment, replacing the previous one
+func (s *store) SetPaymentDue(p *models.PaymentDue) error {
+	if !models.IsPaymentDueType(p.Type) {
+		return errors.ErrPaymentDueInvalidType
+	}
+	if p.Day < 1 || p.Day > 31 {
+		return errors.ErrInvalidDueDay
+	}
+
+	return s.client.SetPaymentDue(p)
+}
+
+func (s *store) RemovePaymentDue(p *models.PaymentDue) error {
+	return s.client.RemovePaymentDue(p)
+}
+
+func (s *store) GetPaymentDues() ([]*models.PaymentDue, error) {
+	return s.client.GetPaymentDues()
+}
+
+// AddScheduledCleaning assigns the cleaning of the apartment at the date, replacing any cleaning already scheduled then
+func (s *store) AddScheduledCleaning(c *models.ScheduledCleaning) error {
+	return s.client.AddScheduledCleaning(c)
+}
+
+func (s *store) RemoveScheduledCleaning(c *models.ScheduledCleaning) error {
+	return s.client.RemoveScheduledCleaning(c)
+}
+
+func (s *store) GetScheduledCleanings() ([]*models.ScheduledCleaning, error) {
+	return s.client.GetScheduledCleanings()
+}
+
+// SetReportSubscription subscribes the chat to the report, replacing the subscription of the same frequency
+func (s *store) SetReportSubscription(r *models.ReportSubscription) error {
+	return s.client.SetReportSubscription(r)
+}
+
+func (s *store) RemoveReportSubscription(r *models.ReportSubscription) error {
+	return s.client.RemoveReportSubscription(r)
+}
+
+func (s *store) GetReportSubscriptions() ([]*models.ReportSubscription, error) {
+	return s.client.GetReportSubscriptions()
+}
+
+// SetFinancingContract sets the financing of the apartment, replacing the previous one
+func (s *store) SetFinancingContract(f *models.FinancingContract) error {
+	if f.Principal <= 0 || f.Term <= 0 || f.AnnualRate < 0 {
+		return errors.ErrFinancingInvalidTerms
+	}
+	if _, ok := models.ParseAmortizationSystem(string(f.System)); !ok {
+		return errors.ErrFinancingInvalidSystem
+	}
+	if _, ok := models.ParseCorrectionIndex(string(f.Index)); !ok {
+		return errors.ErrFinancingInvalidIndex
+	}
+
+	return s.client.SetFinancingContract(f)
+}
+
+func (s *store) RemoveFinancingContract(f *models.FinancingContract) error {
+	return s.client.RemoveFinancingContract(f)
+}
+
+func (s *store) GetFinancingContracts() ([]*models.FinancingContract, error) {
+	return s.client.GetFinancingContracts()
+}
+
+// AddIndexValues keeps the monthly variations of the indexes, replacing the ones of the same index and month
+func (s *store) AddIndexValues(values []*models.IndexValue) error {
+	for _, v := range values {
+		if _, ok := models.ParseCorrectionIndex(string(v.Index)); !ok || v.Index == models.IndexNone {
+			return errors.ErrFinancingInvalidIndex
+		}
+	}
+
+	return s.client.AddIndexValues(values)
+}
+
+func (s *store) GetIndexValues(index models.CorrectionIndex) ([]*models.IndexValue, error) {
+	return s.client.GetIndexValues(index)
+}
+
+// SetBudget sets the monthly budget of a type of record in the apartment, replacing the previous one
+func (s *store) SetBudget(b *models.Budget) error {
+	if !models.IsBudgetType(b.Type) {
+		return errors.ErrBudgetInvalidType
+	}
+	if b.Value <= 0 {
+		return errors.ErrBudgetInvalidValue
+	}
+
+	return s.client.SetBudget(b)
+}
+
+func (s *store) RemoveBudget(b *models.Budget) error {
+	return s.client.RemoveBudget(b)
+}
+
+func (s *store) GetBudgets() ([]*models.Budget, error) {
+	return s.client.GetBudgets()
+}
+
+// SetCalendarBookings keeps the reservations read in a sync of the calendar of the source, replacing the previous sync
+func (s *store) SetCalendarBookings(apartment models.Apartment, source string, bookings []*models.CalendarBooking) error {
+	return s.client.SetCalendarBookings(apartment, source, bookings)
+}
+
+func (s *store) GetCalendarBookings(apartment models.Apartment, source string) ([]*models.CalendarBooking, error) {
+	return s.client.GetCalendarBookings(apartment, source)
+}
+
+// SetUserPreference saves what the user answered last, so it is offered first on their next conversation
+func (s *store) SetUserPreference(p *models.UserPreference) error {
+	return s.client.SetUserPreference(p)
+}
+
+func (s *store) GetUserPreference(userId int64) (*models.UserPreference, error) {
+	return s.client.GetUserPreference(userId)
+}
+
+// SetRecordDefault saves the value and payer the user registered for a kind of record in an apartment
+func (s *store) SetRecordDefault(d *models.RecordDefault) error {
+	return s.client.SetRecordDefault(d)
+}
+
+// GetRecordDefault returns the default the user saved for the kind of record in the apartment or, when they have none,
+// the value and payer of the latest record of that kind in the apartment
+func (s *store) GetRecordDefault(userId int64, apartment string, recordType models.RecordType) (*models.RecordDefault, error) {
+	d, err := s.client.GetRecordDefault(userId, apartment, recordType)
+	if err != nil || d != nil {
+		return d, err
+	}
+	return latestRecordDefault(s, userId, apartment, recordType)
+}
+
+// latestRecordDefault returns the value and payer of the latest record of the type in the apartment, nil if there is
+// none
+func latestRecordDefault(s Store, userId int64, apartmentName string, t models.RecordType) (*models.RecordDefault, error) {
+	apartment := models.Apartment{Name: apartmentName}
+	var dates []time.Time
+	var values []float64
+	var payers []string
+	switch t {
+	case models.RecordCleaning:
+		cleanings, err := s.GetPayedCleanings(apartment)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range cleanings {
+			dates, values, payers = append(dates, c.Date), append(values, c.Value), append(payers, c.Payer)
+		}
+	case models.RecordCondo:
+		condos, err := s.GetPayedCondos(apartment)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range condos {
+			dates, values, payers = append(dates, c.Date), append(values, c.Value), append(payers, c.Payer)
+		}
+	case models.RecordEnergyBill:
+		bills, err := s.GetPayedBills(apartment)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range bills {
+			dates, values, payers = append(dates, b.Date), append(values, b.Value), append(payers, b.Payer)
+		}
+	case models.RecordFinancingInstallment:
+		installments, err := s.GetPayedFinancialInstallments(apartment)
+		if err != nil {
+			return nil, err
+		}
+		for _, fi := range installments {
+			dates, values, payers = append(dates, fi.Date), append(values, fi.Value), append(payers, fi.Payer)
+		}
+	case models.RecordMiscellaneousExpense:
+		expenses, err := s.GetMiscellaneousExpenses(apartment)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range expenses {
+			dates, values, payers = append(dates, e.Date), append(values, e.Value), append(payers, e.Payer)
+		}
+	case models.RecordAmortization:
+		amortizations, err := s.GetPayedAmortizations(apartment)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range amortizations {
+			dates, values, payers = append(dates, a.Date), append(values, a.Value), append(payers, a.Payer)
+		}
+	}
+
+	latest := -1
+	for i, d := range dates {
+		if latest < 0 || !d.Before(dates[latest]) {
+			latest = i
+		}
+	}
+	if latest < 0 {
+		return nil, nil
+	}
+	return &models.RecordDefault{
+		UserId:     userId,
+		Apartment:  apartmentName,
+		RecordType: t,
+		Value:      values[latest],
+		Payer:      payers[latest],
+	}, nil
+}
+
+// SetJobRun saves when a scheduled job last did its work
+func (s *store) SetJobRun(r *models.JobRun) error {
+	return s.client.SetJobRun(r)
+}
+
+func (s *store) GetJobRun(job string) (*models.JobRun, error) {
+	return s.client.GetJobRun(job)
+}
+
 func isRentDatesAvailable(r *models.Rent, existingRents []*models.Rent) bool {
 	for _, er := range existingRents {
 		dateBegin, dateEnd := er.DateBegin, er.DateEnd
@@ -161,6 +619,17 @@ func isRentDatesAvailable(r *models.Rent, existingRents []*models.Rent) bool {
 	return true
 }
 
+// maxApartmentNameLength is the limit of bytes of the name, which is the callback data of the apartment keyboards
+const maxApartmentNameLength = format.MaxCallbackDataLength
+
+// isApartmentNameValid tells if the name can be a sheet title referenced in A1 notation, titles starting with
+// a bracket are reserved to sheets which are not apartments
+func isApartmentNameValid(name string) bool {
+	trimmed := strings.TrimSpace(name)
+	return len(trimmed) > 0 && trimmed == name && len(name) <= maxApartmentNameLength &&
+		!strings.HasPrefix(name, "[") && !strings.ContainsAny(name, "!'\"")
+}
+
 func isCondoPayedAtMonth(c *models.Condo, condosPayed []*models.Condo) bool {
 	for _, cp := range condosPayed {
 		if cp.Date.Month() == c.Date.Month() && cp.Date.Year() == c.Date.Year() {
@@ -0,0 +1,230 @@
+package tax
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/pdf"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// Amounts are the rents received by a partner and the expenses paid by them which are deductible from them
+type Amounts struct {
+	Taxable    float64
+	Deductible float64
+}
+
+// Base is the value declared in the carnê-leão, never negative
+func (a Amounts) Base() float64 {
+	if a.Deductible > a.Taxable {
+		return 0
+	}
+	return a.Taxable - a.Deductible
+}
+
+func (a *Amounts) add(other Amounts) {
+	a.Taxable += other.Taxable
+	a.Deductible += other.Deductible
+}
+
+// Totals sum the amounts of several months, the base being the sum of the monthly bases as each month is declared on
+// its own
+type Totals struct {
+	Taxable    float64
+	Deductible float64
+	Base       float64
+}
+
+func sumMonths(months [12]Amounts) Totals {
+	var t Totals
+	for _, m := range months {
+		t.Taxable += m.Taxable
+		t.Deductible += m.Deductible
+		t.Base += m.Base()
+	}
+	return t
+}
+
+// PartnerReport is the rental income of a partner in a year, month by month and by apartment
+type PartnerReport struct {
+	Partner string
+	Months  [12]Amounts
+	// Apartments are the months of each apartment
+	Apartments map[string]*[12]Amounts
+}
+
+func (p *PartnerReport) Total() Totals {
+	return sumMonths(p.Months)
+}
+
+// ApartmentTotal sums the months of the apartment
+func (p *PartnerReport) ApartmentTotal(name string) Totals {
+	if months, ok := p.Apartments[name]; ok {
+		return sumMonths(*months)
+	}
+	return Totals{}
+}
+
+// ApartmentNames returns the apartments of the partner in alphabetical order
+func (p *PartnerReport) ApartmentNames() []string {
+	var names []string
+	for name := range p.Apartments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Report is the carnê-leão of every partner in a year
+type Report struct {
+	Year     int
+	Partners []*PartnerReport
+}
+
+// Build sums the rents each partner received in the year, by the month they were received in, and the deductible
+// expenses they paid: the condo fees and the service fees of the platforms
+func Build(store storage.Store, year int) (*Report, error) {
+	apartments, err := store.GetApartments()
+	if err != nil {
+		return nil, err
+	}
+
+	byPartner := make(map[string]*PartnerReport)
+	add := func(partner, apartment string, date time.Time, amounts Amounts) {
+		if date.Year() != year || len(partner) == 0 {
+			return
+		}
+		p, ok := byPartner[partner]
+		if !ok {
+			p = &PartnerReport{Partner: partner, Apartments: make(map[string]*[12]Amounts)}
+			byPartner[partner] = p
+		}
+		p.Months[date.Month()-1].add(amounts)
+		if _, ok := p.Apartments[apartment]; !ok {
+			p.Apartments[apartment] = &[12]Amounts{}
+		}
+		p.Apartments[apartment][date.Month()-1].add(amounts)
+	}
+
+	// archived apartments count too, they may have been sold during the year
+	for _, apt := range apartments {
+		rents, err := store.GetExistingRents(*apt)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rents {
+			add(r.Receiver, apt.Name, r.ReceiptDate(), Amounts{Taxable: r.Value})
+		}
+
+		condos, err := store.GetPayedCondos(*apt)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range condos {
+			add(c.Payer, apt.Name, c.Date, Amounts{Deductible: c.Value})
+		}
+
+		expenses, err := store.GetMiscellaneousExpenses(*apt)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range expenses {
+			if e.Category == models.CategoryServiceFee {
+				add(e.Payer, apt.Name, e.Date, Amounts{Deductible: e.Value})
+			}
+		}
+	}
+
+	report := &Report{Year: year}
+	for _, p := range byPartner {
+		report.Partners = append(report.Partners, p)
+	}
+	sort.Slice(report.Partners, func(i, j int) bool {
+		return report.Partners[i].Partner < report.Partners[j].Partner
+	})
+	return report, nil
+}
+
+// Format writes the yearly totals of each partner as a chat message
+func (r *Report) Format() string {
+	lines := []string{fmt.Sprintf("Carnê-leao de %d", r.Year)}
+	if len(r.Partners) == 0 {
+		return lines[0] + "\nNenhum aluguel ou despesa dedutível no ano"
+	}
+	for _, p := range r.Partners {
+		total := p.Total()
+		lines = append(lines, fmt.Sprintf("%v: aluguéis R$%.2f, dedutíveis R$%.2f, base de cálculo R$%.2f",
+			p.Partner, total.Taxable, total.Deductible, total.Base))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CSV writes the months of each partner followed by the totals by apartment, with comma decimals
+func (r *Report) CSV() []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = ';'
+	_ = w.Write([]string{"Parceiro", "Mês", "Aluguéis recebidos", "Despesas dedutíveis", "Base de cálculo"})
+	for _, p := range r.Partners {
+		for i, m := range p.Months {
+			month := fmt.Sprintf("%02d/%d", i+1, r.Year)
+			_ = w.Write([]string{p.Partner, month, decimal(m.Taxable), decimal(m.Deductible), decimal(m.Base())})
+		}
+	}
+	_ = w.Write(nil)
+	_ = w.Write([]string{"Parceiro", "Imóvel", "Aluguéis recebidos", "Despesas dedutíveis", "Base de cálculo"})
+	for _, p := range r.Partners {
+		for _, name := range p.ApartmentNames() {
+			t := p.ApartmentTotal(name)
+			_ = w.Write([]string{p.Partner, name, decimal(t.Taxable), decimal(t.Deductible), decimal(t.Base)})
+		}
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+// PDF writes a page for each partner with the months and the totals by apartment
+func (r *Report) PDF() []byte {
+	doc := pdf.New()
+	row := func(label string, t Totals) string {
+		return fmt.Sprintf("%-24s %15s %15s %15s", label, money(t.Taxable), money(t.Deductible), money(t.Base))
+	}
+	header := fmt.Sprintf("%-24s %15s %15s %15s", "", "Aluguéis", "Dedutíveis", "Base")
+
+	for i, p := range r.Partners {
+		if i > 0 {
+			doc.PageBreak()
+		}
+		doc.Heading(fmt.Sprintf("Carnê-leao %d - %v", r.Year, p.Partner))
+		doc.Line(header)
+		for m, amounts := range p.Months {
+			doc.Line(row(fmt.Sprintf("%02d/%d", m+1, r.Year), Totals{amounts.Taxable, amounts.Deductible, amounts.Base()}))
+		}
+		doc.Line(row("Total", p.Total()))
+		doc.Blank()
+		doc.Heading("Por imóvel")
+		doc.Line(header)
+		for _, name := range p.ApartmentNames() {
+			doc.Line(row(name, p.ApartmentTotal(name)))
+		}
+	}
+	if len(r.Partners) == 0 {
+		doc.Heading(fmt.Sprintf("Carnê-leao %d", r.Year))
+		doc.Line("Nenhum aluguel ou despesa dedutível no ano")
+	}
+	return doc.Bytes()
+}
+
+func decimal(v float64) string {
+	return strings.Replace(strconv.FormatFloat(v, 'f', 2, 64), ".", ",", 1)
+}
+
+func money(v float64) string {
+	return "R$" + decimal(v)
+}
@@ -0,0 +1,144 @@
+package tax
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gustavolopess/hoteleiro/internal/models"
+	"github.com/gustavolopess/hoteleiro/internal/storage"
+)
+
+// taxStore holds the records read by the carnê-leão, the other methods of the store are not expected to be called
+type taxStore struct {
+	storage.Store
+	apartments []*models.Apartment
+	rents      map[string][]*models.Rent
+	condos     map[string][]*models.Condo
+	expenses   map[string][]*models.MiscellaneousExpense
+}
+
+func (s *taxStore) GetApartments() ([]*models.Apartment, error) {
+	return s.apartments, nil
+}
+
+func (s *taxStore) GetExistingRents(a models.Apartment) ([]*models.Rent, error) {
+	return s.rents[a.Name], nil
+}
+
+func (s *taxStore) GetPayedCondos(a models.Apartment) ([]*models.Condo, error) {
+	return s.condos[a.Name], nil
+}
+
+func (s *taxStore) GetMiscellaneousExpenses(a models.Apartment) ([]*models.MiscellaneousExpense, error) {
+	return s.expenses[a.Name], nil
+}
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
+
+func newTaxStore() *taxStore {
+	return &taxStore{
+		apartments: []*models.Apartment{{Name: "Centro"}, {Name: "Praia", Archived: true}},
+		rents: map[string][]*models.Rent{
+			"Centro": {
+				{DateBegin: date(2024, time.January, 10), Value: 1000, Receiver: "Gustavo"},
+				// checked in during the previous year, received in January
+				{DateBegin: date(2023, time.December, 30), Value: 500, Receiver: "Gustavo", ReceivedAt: date(2024, time.January, 2)},
+				// checked in during the year, received in the next one
+				{DateBegin: date(2024, time.December, 30), Value: 700, Receiver: "Gustavo", ReceivedAt: date(2025, time.January, 2)},
+				{DateBegin: date(2024, time.March, 5), Value: 300, Receiver: "Emerson"},
+			},
+			"Praia": {{DateBegin: date(2024, time.February, 1), Value: 2000, Receiver: "Gustavo"}},
+		},
+		condos: map[string][]*models.Condo{
+			"Centro": {
+				{Date: date(2024, time.January, 10), Value: 400, Payer: "Gustavo"},
+				// more than received in February, which leaves the month without a base
+				{Date: date(2024, time.February, 10), Value: 2500, Payer: "Gustavo"},
+			},
+		},
+		expenses: map[string][]*models.MiscellaneousExpense{
+			"Centro": {
+				{Date: date(2024, time.January, 11), Value: 100, Payer: "Gustavo", Category: models.CategoryServiceFee},
+				{Date: date(2024, time.January, 12), Value: 80, Payer: "Gustavo", Description: "lâmpada"},
+			},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	r, err := Build(newTaxStore(), 2024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Partners) != 2 || r.Partners[0].Partner != "Emerson" || r.Partners[1].Partner != "Gustavo" {
+		t.Fatalf("partners %v, want Emerson and Gustavo", r.Partners)
+	}
+
+	g := r.Partners[1]
+	if jan := g.Months[time.January-1]; jan.Taxable != 1500 || jan.Deductible != 500 {
+		t.Errorf("January of Gustavo %+v, want 1500 received and 500 deductible", jan)
+	}
+	if feb := g.Months[time.February-1]; feb.Taxable != 2000 || feb.Deductible != 2500 || feb.Base() != 0 {
+		t.Errorf("February of Gustavo %+v with base %v, want 2000 received, 2500 deductible and no base", feb, feb.Base())
+	}
+	if dec := g.Months[time.December-1]; dec.Taxable != 0 {
+		t.Errorf("December of Gustavo received %v, want the rent in the month it was received", dec.Taxable)
+	}
+	if _, ok := g.Apartments["Praia"]; !ok {
+		t.Errorf("the archived apartment is missing from the report of Gustavo")
+	}
+}
+
+func TestTotals(t *testing.T) {
+	r, err := Build(newTaxStore(), 2024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range r.Partners {
+		var bases float64
+		for _, m := range p.Months {
+			bases += m.Base()
+		}
+		if total := p.Total(); !near(total.Base, bases) {
+			t.Errorf("base of %v is %.2f, want the sum of the monthly bases %.2f", p.Partner, total.Base, bases)
+		}
+	}
+
+	g := r.Partners[1]
+	// January declares 1000, February nothing though 500 more was deducted than received
+	if total := g.Total(); total.Taxable != 3500 || total.Deductible != 3000 || !near(total.Base, 1000) {
+		t.Errorf("total of Gustavo %+v, want 3500 received, 3000 deductible and a base of 1000", total)
+	}
+	if centro := g.ApartmentTotal("Centro"); !near(centro.Base, 1000) {
+		t.Errorf("base of Centro %.2f, want 1000", centro.Base)
+	}
+	if praia := g.ApartmentTotal("Praia"); !near(praia.Base, 2000) {
+		t.Errorf("base of Praia %.2f, want 2000", praia.Base)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	r, err := Build(newTaxStore(), 2024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csv := string(r.CSV())
+	for _, line := range []string{
+		"Gustavo;01/2024;1500,00;500,00;1000,00",
+		"Gustavo;02/2024;2000,00;2500,00;0,00",
+		"Gustavo;Centro;1500,00;3000,00;1000,00",
+		"Emerson;Centro;300,00;0,00;300,00",
+	} {
+		if !strings.Contains(csv, line+"\n") {
+			t.Errorf("CSV is missing %q:\n%s", line, csv)
+		}
+	}
+}